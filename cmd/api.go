@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/coder/websocket"
+	"github.com/spf13/viper"
+)
+
+// maxReadBytes bounds a single message read from the WebSocket, as in the
+// TUI: a page of encrypted history is well over the library's default.
+const maxReadBytes = 4 << 20
+
+// room is a room as listed by GET /rooms.
+type room struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Topic      string `json:"topic"`
+	Visibility string `json:"visibility"`
+	Kind       string `json:"kind"`
+	Encrypted  bool   `json:"encrypted"`
+}
+
+// apiRequest sends payload, if any, as JSON to path on the configured server
+// with the configured API key, and decodes the response into out.
+func apiRequest(method, path string, payload any, wantStatus int, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	return apiDo(method, path, "application/json", body, wantStatus, out)
+}
+
+// apiDo sends body to path on the configured server with the configured API
+// key, and decodes the response into out.
+func apiDo(method, path, contentType string, body io.Reader, wantStatus int, out any) error {
+	resp, err := apiCall(method, path, contentType, body, wantStatus)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// apiCall sends body to path on the configured server with the configured API
+// key, returning the response if it has wantStatus. The caller must close its
+// body.
+func apiCall(method, path, contentType string, body io.Reader, wantStatus int) (*http.Response, error) {
+	host, apiKey, err := clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, apiURL(host, path), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != wantStatus {
+		defer func() { _ = resp.Body.Close() }()
+		var errBody map[string]string
+		_ = json.NewDecoder(resp.Body).Decode(&errBody)
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, errBody["error"])
+	}
+	return resp, nil
+}
+
+func clientConfig() (host, apiKey string, err error) {
+	if viper.ConfigFileUsed() == "" {
+		return "", "", errors.New("no config file found — run 'chatatui init' first")
+	}
+	host, apiKey = viper.GetString("host"), viper.GetString("api_key")
+	if host == "" {
+		return "", "", errors.New("'host' not set — edit your config file to point at the server")
+	}
+	if apiKey == "" {
+		return "", "", errors.New("'api_key' not set — run 'chatatui register <name>' to register")
+	}
+	return host, apiKey, nil
+}
+
+func apiURL(host, path string) string {
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "http://" + host
+	}
+	return host + path
+}
+
+func wsURL(host, path string) string {
+	switch {
+	case strings.HasPrefix(host, "https://"):
+		host = "wss://" + strings.TrimPrefix(host, "https://")
+	case strings.HasPrefix(host, "http://"):
+		host = "ws://" + strings.TrimPrefix(host, "http://")
+	default:
+		host = "ws://" + host
+	}
+	return host + path
+}
+
+// findRoom returns the room the user can see whose name or ID is nameOrID.
+func findRoom(nameOrID string) (*room, error) {
+	var rooms []room
+	if err := apiRequest(http.MethodGet, "/rooms", nil, http.StatusOK, &rooms); err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(rooms, func(r room) bool { return r.ID == nameOrID || r.Name == nameOrID })
+	if i < 0 {
+		return nil, fmt.Errorf("no room named %q — run 'chatatui rooms ls' to see your rooms", nameOrID)
+	}
+	return &rooms[i], nil
+}
+
+// subscribe dials the multiplexed WebSocket and subscribes to roomID,
+// returning once the server has confirmed it.
+func subscribe(ctx context.Context, roomID string) (*websocket.Conn, error) {
+	host, apiKey, err := clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := websocket.Dial(ctx, wsURL(host, "/ws"), &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer " + apiKey}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(maxReadBytes)
+
+	if err := writeWire(ctx, conn, &hub.WireMessage{Type: hub.MessageTypeSubscribe, RoomID: roomID}); err != nil {
+		_ = conn.CloseNow()
+		return nil, err
+	}
+	for {
+		wire, err := readWire(ctx, conn)
+		if err != nil {
+			_ = conn.CloseNow()
+			return nil, err
+		}
+		switch wire.Type {
+		case hub.MessageTypeSubscribe:
+			return conn, nil
+		case hub.MessageTypeError:
+			_ = conn.CloseNow()
+			return nil, errors.New(wire.Content)
+		}
+	}
+}
+
+func readWire(ctx context.Context, conn *websocket.Conn) (*hub.WireMessage, error) {
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var wire hub.WireMessage
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, err
+	}
+	return &wire, nil
+}
+
+func writeWire(ctx context.Context, conn *websocket.Conn, wire *hub.WireMessage) error {
+	data, err := wire.Marshal()
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// loadKey reads the encryption key the TUI keeps next to the config file.
+func loadKey() (*e2e.Key, error) {
+	path := filepath.Join(filepath.Dir(viper.ConfigFileUsed()), e2e.KeyFileName)
+	key, err := e2e.LoadKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load encryption key — run 'chatatui' once to create it: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var botScopes []string
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Manage your bot accounts",
+}
+
+var botCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a bot and print its API key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var bot struct {
+			Name   string   `json:"name"`
+			Scopes []string `json:"scopes"`
+			APIKey string   `json:"api_key"`
+		}
+		payload := map[string]any{"name": args[0], "scopes": botScopes}
+		if err := apiRequest(http.MethodPost, "/bots", payload, http.StatusCreated, &bot); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("created bot %q with scopes %s\n", bot.Name, strings.Join(bot.Scopes, " "))
+		fmt.Printf("api_key: %s\n", bot.APIKey)
+		fmt.Println("the key is not shown again — keep it somewhere safe")
+	},
+}
+
+var botListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your bots",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var bots []struct {
+			ID     string   `json:"id"`
+			Name   string   `json:"name"`
+			Scopes []string `json:"scopes"`
+		}
+		if err := apiRequest(http.MethodGet, "/bots", nil, http.StatusOK, &bots); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, bot := range bots {
+			fmt.Printf("%s  %-20s %s\n", bot.ID, bot.Name, strings.Join(bot.Scopes, " "))
+		}
+	},
+}
+
+func init() {
+	botCreateCmd.Flags().StringSliceVar(&botScopes, "scope", []string{"rooms:read", "messages:read", "messages:write"},
+		"scope to grant, repeatable: rooms:read, rooms:write, messages:read, messages:write, profile:write")
+	botCmd.AddCommand(botCreateCmd, botListCmd)
+	rootCmd.AddCommand(botCmd)
+}
@@ -0,0 +1,96 @@
+// Command echobot is an example bot built with pkg/botsdk. It repeats what it
+// is told with !echo, and with !remind 10m stretch, mentions whoever asked
+// once the time is up.
+//
+// Create its API key with "chatatui bot create echobot", then run:
+//
+//	CHATATUI_BOT_KEY=... go run ./cmd/echobot -host localhost:8080 -rooms general
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/EwanGreer/chatatui/pkg/botsdk"
+)
+
+// maxReminder is the longest a reminder can be set for. Reminders are kept in
+// memory, so they are lost if the bot restarts.
+const maxReminder = 24 * time.Hour
+
+func main() {
+	host := flag.String("host", "localhost:8080", "server address")
+	rooms := flag.String("rooms", "", "comma-separated rooms to join (default every room the bot can see)")
+	flag.Parse()
+
+	key := os.Getenv("CHATATUI_BOT_KEY")
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "error: CHATATUI_BOT_KEY is not set")
+		os.Exit(1)
+	}
+
+	cfg := botsdk.Config{Host: *host, APIKey: key}
+	if *rooms != "" {
+		cfg.Rooms = strings.Split(*rooms, ",")
+	}
+	bot := botsdk.New(cfg)
+	bot.Command("help", help)
+	bot.Command("echo", echo)
+	bot.Command("remind", remind)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("echobot starting", "host", *host)
+	if err := bot.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func help(ctx context.Context, b *botsdk.Bot, cmd botsdk.Command) {
+	send(ctx, b, cmd.RoomID, "!echo <text> repeats text; !remind <duration> <text> reminds you, e.g. !remind 10m stretch")
+}
+
+func echo(ctx context.Context, b *botsdk.Bot, cmd botsdk.Command) {
+	if cmd.Text == "" {
+		return
+	}
+	send(ctx, b, cmd.RoomID, cmd.Text)
+}
+
+func remind(ctx context.Context, b *botsdk.Bot, cmd botsdk.Command) {
+	if len(cmd.Args) < 2 {
+		send(ctx, b, cmd.RoomID, "usage: !remind <duration> <text>, e.g. !remind 10m stretch")
+		return
+	}
+	after, err := time.ParseDuration(cmd.Args[0])
+	if err != nil || after <= 0 || after > maxReminder {
+		send(ctx, b, cmd.RoomID, fmt.Sprintf("%q is not a duration between 1s and %s", cmd.Args[0], maxReminder))
+		return
+	}
+	text := strings.TrimSpace(strings.TrimPrefix(cmd.Text, cmd.Args[0]))
+
+	if err := b.Reply(ctx, cmd.Message, fmt.Sprintf("ok, I'll remind you in %s", after)); err != nil {
+		slog.Warn("failed to reply", "error", err)
+	}
+	select {
+	case <-time.After(after):
+		send(ctx, b, cmd.RoomID, fmt.Sprintf("@%s reminder: %s", cmd.Author, text))
+	case <-ctx.Done():
+	}
+}
+
+func send(ctx context.Context, b *botsdk.Bot, roomID, text string) {
+	if err := b.Send(ctx, roomID, text); err != nil {
+		slog.Warn("failed to send", "error", err, "room_id", roomID)
+	}
+}
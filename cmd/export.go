@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <room>",
+	Short: "Export a room's history",
+	Long: `Export the whole history of a room, by name or ID, to stdout or a file.
+
+The default jsonl format writes the room and then each message as a line of
+JSON, keeping their authors, times and IDs, and can be loaded into a room on
+any server with 'chatatui import'. The markdown format is a transcript to read.
+Files are described but their contents are not exported.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := findRoom(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := exportRoom(r.ID, exportFormat, exportOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// exportRoom streams the room's export to path, or to stdout if path is
+// empty, removing what was written to path if it fails.
+func exportRoom(roomID, format, path string) (err error) {
+	resp, err := apiCall(http.MethodGet, "/rooms/"+roomID+"/export?format="+url.QueryEscape(format), "", nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if path == "" {
+		_, err = io.Copy(os.Stdout, resp.Body)
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("export was cut short: %w", err)
+	}
+	return file.Close()
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "jsonl", "jsonl to import again, or markdown for a transcript")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write the export to (default stdout)")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var importName string
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create a room from an export",
+	Long: `Create a room, owned by you, from a file written by 'chatatui export', or
+from stdin if the file is -. Messages keep their times and IDs, so an export
+can only be imported once per server. Messages you sent stay yours; anyone
+else's are attributed to a placeholder account with their name. Messages
+older than the server's retention policy are pruned soon after.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			defer func() { _ = file.Close() }()
+			in = file
+		}
+
+		path := "/rooms/import"
+		if importName != "" {
+			path += "?name=" + url.QueryEscape(importName)
+		}
+		var imported struct {
+			room
+			Messages int `json:"messages"`
+		}
+		if err := apiDo(http.MethodPost, path, "application/x-ndjson", in, http.StatusCreated, &imported); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("imported %d messages into room %q (%s)\n", imported.Messages, imported.Name, imported.ID)
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importName, "name", "", "name for the new room (default the exported room's)")
+	rootCmd.AddCommand(importCmd)
+}
@@ -67,10 +67,7 @@ var registerCmd = &cobra.Command{
 }
 
 func registerURL(host string) string {
-	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
-		host = "http://" + host
-	}
-	return host + "/register"
+	return apiURL(host, "/register")
 }
 
 func saveAPIKey(apiKey string) error {
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var roomsJSON bool
+
+var roomsCmd = &cobra.Command{
+	Use:   "rooms",
+	Short: "Work with rooms without the TUI",
+}
+
+var roomsListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the rooms you can see",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var rooms []room
+		if err := apiRequest(http.MethodGet, "/rooms", nil, http.StatusOK, &rooms); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if roomsJSON {
+			_ = json.NewEncoder(os.Stdout).Encode(rooms)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tNAME\tKIND\tVISIBILITY\tTOPIC")
+		for _, r := range rooms {
+			name := r.Name
+			if r.Encrypted {
+				name += " 🔒"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.ID, name, r.Kind, r.Visibility, r.Topic)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	roomsListCmd.Flags().BoolVar(&roomsJSON, "json", false, "print the rooms as JSON")
+	roomsCmd.AddCommand(roomsListCmd)
+	rootCmd.AddCommand(roomsCmd)
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/EwanGreer/chatatui/internal/client/ui"
+	"github.com/EwanGreer/chatatui/internal/e2e"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -50,6 +52,16 @@ var rootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// The private key lives next to the config file, so that each
+		// config, like each account, has a key of its own.
+		keyPath := filepath.Join(filepath.Dir(viper.ConfigFileUsed()), e2e.KeyFileName)
+		key, _, err := e2e.LoadOrCreateKey(keyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: could not load encryption key: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Key = key
+
 		if _, err := tea.NewProgram(ui.NewModel(cfg), tea.WithAltScreen()).Run(); err != nil {
 			panic(err)
 		}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/coder/websocket"
+	"github.com/spf13/cobra"
+)
+
+// sendTimeout bounds how long send waits for the server to store a message.
+const sendTimeout = 30 * time.Second
+
+var sendFileName string
+
+var sendCmd = &cobra.Command{
+	Use:   "send <room> [message]",
+	Short: "Send a message to a room, reading it from stdin if not given",
+	Long: `Send a message to a room, by name or ID, reading it from stdin if it is
+not given on the command line:
+
+  make 2>&1 | tail -n 20 | chatatui send builds
+
+Text too long for a message is sent as a text file attachment instead.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		text := strings.Join(args[1:], " ")
+		if len(args) == 1 {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: could not read stdin: %v\n", err)
+				os.Exit(1)
+			}
+			text = string(data)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			fmt.Fprintln(os.Stderr, "error: nothing to send")
+			os.Exit(1)
+		}
+
+		r, err := findRoom(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(text) > limits.MaxMessageLength && !r.Encrypted {
+			err = sendFile(r.ID, sendFileName, text)
+		} else {
+			err = sendMessage(r, text)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// sendMessage sends text over the WebSocket, encrypting it for the room's
+// members if the room is encrypted, and waits for the server to store it.
+func sendMessage(r *room, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	content := text
+	if r.Encrypted {
+		sealed, err := sealFor(r.ID, text)
+		if err != nil {
+			return err
+		}
+		content = sealed
+	}
+
+	conn, err := subscribe(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	if err := writeWire(ctx, conn, &hub.WireMessage{Type: hub.MessageTypeChat, RoomID: r.ID, Content: content}); err != nil {
+		return err
+	}
+	for {
+		wire, err := readWire(ctx, conn)
+		if err != nil {
+			return err
+		}
+		switch {
+		case wire.Type == hub.MessageTypeAck && wire.RoomID == r.ID:
+			_ = conn.Close(websocket.StatusNormalClosure, "")
+			return nil
+		case wire.Type == hub.MessageTypeError:
+			return errors.New(wire.Content)
+		}
+	}
+}
+
+// sealFor encrypts text for every member of the room who has published a
+// key, and for the user.
+func sealFor(roomID, text string) (string, error) {
+	key, err := loadKey()
+	if err != nil {
+		return "", err
+	}
+	var members []struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := apiRequest(http.MethodGet, "/rooms/"+roomID+"/keys", nil, http.StatusOK, &members); err != nil {
+		return "", fmt.Errorf("could not fetch room keys: %w", err)
+	}
+
+	var recipients []string
+	for _, m := range members {
+		if m.PublicKey != "" {
+			recipients = append(recipients, m.PublicKey)
+		}
+	}
+	sealed, err := e2e.Seal([]byte(text), key, recipients)
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt message: %w", err)
+	}
+	return string(sealed), nil
+}
+
+// sendFile uploads text as a plain text attachment called name.
+func sendFile(roomID, name, text string) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(part, text); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	return apiDo(http.MethodPost, "/rooms/"+roomID+"/attachments", form.FormDataContentType(), &body, http.StatusCreated, nil)
+}
+
+func init() {
+	sendCmd.Flags().StringVar(&sendFileName, "name", "message.txt", "file name for text too long to send as a message")
+	rootCmd.AddCommand(sendCmd)
+}
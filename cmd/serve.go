@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,13 +11,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/blob"
 	"github.com/EwanGreer/chatatui/internal/config"
 	"github.com/EwanGreer/chatatui/internal/middleware"
 	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/EwanGreer/chatatui/internal/retention"
 	"github.com/EwanGreer/chatatui/internal/server"
 	"github.com/EwanGreer/chatatui/internal/server/api"
 	"github.com/EwanGreer/chatatui/internal/server/hub"
 	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/EwanGreer/chatatui/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -34,19 +38,61 @@ var serveCmd = &cobra.Command{
 		}
 		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})))
 
-		rateLimiter, err := middleware.NewRateLimiter(cfg.RedisURL, cfg.RateLimitRequests, cfg.RateLimitWindowSecs)
+		rateLimiter, err := newRateLimiter(cfg)
 		if err != nil {
-			slog.Warn("rate limiter disabled", "error", err)
+			slog.Error("failed to initialize rate limiter", "error", err)
+			os.Exit(1)
 		}
 
-		database, err := repository.NewPostgresDB(cfg.DatabaseDSN)
+		database, err := repository.Open(cfg.DatabaseDSN)
 		if err != nil {
 			slog.Error("failed to initialize database", "error", err)
 			os.Exit(1)
 		}
 
+		if err := checkSchema(database, serveMigrate); err != nil {
+			slog.Error("database schema is not current", "error", err)
+			os.Exit(1)
+		}
+
+		blobs, err := blob.Open(cfg.BlobStore)
+		if err != nil {
+			slog.Error("failed to initialize blob store", "error", err)
+			os.Exit(1)
+		}
+
+		broker, err := newBroker(cfg)
+		if err != nil {
+			slog.Error("failed to initialize broker", "error", err)
+			os.Exit(1)
+		}
+		defer func() { _ = broker.Close() }()
+
+		webhooks := webhook.NewDispatcher(database.Webhooks(), database.Messages(), webhook.Config{
+			MaxAttempts:          cfg.WebhookMaxAttempts,
+			RetryDelay:           time.Duration(cfg.WebhookRetryDelaySecs) * time.Second,
+			Timeout:              time.Duration(cfg.WebhookTimeoutSecs) * time.Second,
+			Workers:              cfg.WebhookWorkers,
+			AllowPrivateNetworks: cfg.WebhookAllowPrivate,
+		})
+		defer webhooks.Close()
+
+		janitorCtx, stopJanitor := context.WithCancel(context.Background())
+		defer stopJanitor()
+		if cfg.RetentionIntervalSecs > 0 {
+			janitor := retention.NewJanitor(database.Rooms(), database.Messages(), blobs, retention.Config{
+				Server:     repository.Retention{MaxAgeDays: cfg.RetentionMaxAgeDays, MaxMessages: cfg.RetentionMaxMessages},
+				Interval:   time.Duration(cfg.RetentionIntervalSecs) * time.Second,
+				BatchSize:  cfg.RetentionBatchSize,
+				HardDelete: cfg.RetentionHardDelete,
+			})
+			go janitor.Run(janitorCtx)
+		}
+
 		svc := service.NewChatService(database.Rooms(), database.Messages())
-		handler := api.NewHandler(hub.NewHub(), database.Users(), database.Users(), database.Rooms(), svc, cfg, rateLimiter)
+		svc.SetMessageListener(webhooks)
+		hooks := service.NewWebhookService(database.Rooms(), database.Webhooks())
+		handler := api.NewHandler(hub.NewHub(broker), database.Users(), database.Users(), database.Users(), database.Users(), database.Rooms(), svc, hooks, blobs, cfg, rateLimiter)
 		srv := server.NewChatServer(handler, cfg.Addr, database)
 
 		go func() {
@@ -60,6 +106,7 @@ var serveCmd = &cobra.Command{
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 		<-quit
+		stopJanitor()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -73,6 +120,79 @@ var serveCmd = &cobra.Command{
 	},
 }
 
+// serveMigrate applies pending migrations at startup instead of refusing to
+// start, which suits throwaway databases such as sqlite://:memory:.
+var serveMigrate bool
+
+// checkSchema refuses to serve a database whose schema is behind this build,
+// unless migrate is set, in which case it brings the schema up to date first.
+func checkSchema(database *repository.Store, migrate bool) error {
+	migrator, err := repository.NewMigrator(database.DB)
+	if err != nil {
+		return err
+	}
+	if migrate {
+		ran, err := migrator.Up()
+		for _, m := range ran {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := migrator.Check(); err != nil {
+		if errors.Is(err, repository.ErrSchemaBehind) {
+			return fmt.Errorf("%w; run 'chatatui migrate up' or start with --migrate", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// newBroker returns the cross-node broadcast backend selected in config. In
+// auto mode it uses Redis and falls back to the in-memory broker, which only
+// fans out between clients connected to this node, if Redis cannot be
+// reached.
+func newBroker(cfg config.ServerConfig) (hub.Broker, error) {
+	switch cfg.Broker {
+	case "memory":
+		return hub.NewMemoryBroker(), nil
+	case "redis":
+		return hub.NewRedisBroker(cfg.RedisURL)
+	case "auto", "":
+		broker, err := hub.NewRedisBroker(cfg.RedisURL)
+		if err != nil {
+			slog.Warn("redis broker unavailable, falling back to in-memory broker", "error", err)
+			return hub.NewMemoryBroker(), nil
+		}
+		return broker, nil
+	default:
+		return nil, fmt.Errorf("unknown broker %q", cfg.Broker)
+	}
+}
+
+// newRateLimiter returns the HTTP rate limiter selected in config. In auto
+// mode it uses Redis, so that limits are shared between nodes, and falls back
+// to counting in process if Redis cannot be reached.
+func newRateLimiter(cfg config.ServerConfig) (*middleware.RateLimiter, error) {
+	switch cfg.RateLimitBackend {
+	case "memory":
+		return middleware.NewMemoryRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindowSecs), nil
+	case "redis":
+		return middleware.NewRateLimiter(cfg.RedisURL, cfg.RateLimitRequests, cfg.RateLimitWindowSecs)
+	case "auto", "":
+		rl, err := middleware.NewRateLimiter(cfg.RedisURL, cfg.RateLimitRequests, cfg.RateLimitWindowSecs)
+		if err != nil {
+			slog.Warn("redis rate limiter unavailable, falling back to in-memory rate limiter", "error", err)
+			return middleware.NewMemoryRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindowSecs), nil
+		}
+		return rl, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimitBackend)
+	}
+}
+
 func init() {
+	serveCmd.Flags().BoolVar(&serveMigrate, "migrate", false, "apply pending database migrations before starting")
 	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tailJSON    bool
+	tailHistory bool
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail <room>",
+	Short: "Print a room's messages as they arrive",
+	Long: `Print a room's messages, by name or ID, as they arrive until interrupted.
+With --json each message is printed as one line of JSON in the WebSocket wire
+format, for scripts.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := findRoom(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var key *e2e.Key
+		if r.Encrypted {
+			if key, err = loadKey(); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := tail(ctx, r, key); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func tail(ctx context.Context, r *room, key *e2e.Key) error {
+	conn, err := subscribe(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	out := json.NewEncoder(os.Stdout)
+	for {
+		wire, err := readWire(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if wire.RoomID != "" && wire.RoomID != r.ID {
+			continue
+		}
+
+		switch wire.Type {
+		case hub.MessageTypeTyping, hub.MessageTypeAck, hub.MessageTypeMention:
+			continue
+		case hub.MessageTypeUnsubscribe:
+			return errors.New("you are no longer in this room: " + wire.Content)
+		case hub.MessageTypeHistory:
+			// Subscribing sends the newest page of history, oldest first.
+			if !tailHistory {
+				continue
+			}
+			for _, msg := range wire.Messages {
+				msg.RoomID = r.ID
+				if err := printWire(out, &msg, key); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := printWire(out, wire, key); err != nil {
+			return err
+		}
+	}
+}
+
+// printWire writes a message as a line of JSON with --json, and otherwise as
+// a line of text, skipping what has no text form.
+func printWire(out *json.Encoder, wire *hub.WireMessage, key *e2e.Key) error {
+	if key != nil && e2e.IsEnvelope([]byte(wire.Content)) {
+		if plaintext, _, err := e2e.Open([]byte(wire.Content), key); err == nil {
+			wire.Content = string(plaintext)
+		} else {
+			wire.Content = "🔒 unable to decrypt this message"
+		}
+	}
+	if tailJSON {
+		return out.Encode(wire)
+	}
+
+	ts := wire.Timestamp.Local().Format("15:04:05")
+	var line string
+	switch wire.Type {
+	case hub.MessageTypeChat:
+		line = fmt.Sprintf("%s %s: %s", ts, wire.Author, wire.Content)
+	case hub.MessageTypeAction:
+		line = fmt.Sprintf("%s * %s %s", ts, wire.Author, wire.Content)
+	case hub.MessageTypeThreadReply:
+		line = fmt.Sprintf("%s %s (in thread): %s", ts, wire.Author, wire.Content)
+	case hub.MessageTypeFile:
+		if wire.Attachment == nil {
+			return nil
+		}
+		line = fmt.Sprintf("%s %s sent %s", ts, wire.Author, wire.Attachment.Name)
+		if wire.Content != "" {
+			line += ": " + wire.Content
+		}
+	case hub.MessageTypeTopic:
+		line = fmt.Sprintf("%s -- topic: %s", ts, wire.Content)
+	case hub.MessageTypeSystem:
+		if wire.Event != "" {
+			return nil // presence
+		}
+		line = fmt.Sprintf("%s -- %s: %s", ts, wire.Author, wire.Content)
+	case hub.MessageTypeError:
+		line = fmt.Sprintf("%s -- error: %s", ts, wire.Content)
+	default:
+		return nil
+	}
+	_, err := fmt.Println(line)
+	return err
+}
+
+func init() {
+	tailCmd.Flags().BoolVar(&tailJSON, "json", false, "print each message as a line of JSON")
+	tailCmd.Flags().BoolVar(&tailHistory, "history", false, "print the newest page of history first")
+	rootCmd.AddCommand(tailCmd)
+}
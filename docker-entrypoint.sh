@@ -5,7 +5,7 @@ set -euo pipefail
 : "${APP_PKG:=.}"                                # main package path (root of project)
 : "${APP_BIN:=/tmp/app}"                         # compiled binary path inside container
 : "${APP_CONFIG:=/app/dev-config.toml}"          # config file path
-: "${APP_CMD:=serve}"                            # subcommand to run (serve starts the server)
+: "${APP_CMD:=serve --migrate}"                  # subcommand to run (serve starts the server)
 : "${DLV_ADDR:=0.0.0.0:2345}"                    # delve listen address
 : "${AIR_TMPDIR:=/tmp/air}"                      # air temp dir
 : "${AIR_EXCLUDE_DIRS:=vendor,.git,$AIR_TMPDIR}" # comma-separated
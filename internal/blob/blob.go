@@ -0,0 +1,57 @@
+// Package blob stores attachment contents outside the database. Stores are
+// addressed by opaque keys chosen by the server.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	// Put stores everything read from r under key, replacing any blob
+	// already there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Open returns the store named by url. The backend is picked from the URL's
+// scheme:
+//
+//	file:///var/lib/chatatui/blobs  files under an absolute directory
+//	file://blobs                    files under a directory relative to the
+//	                                working directory
+func Open(url string) (Store, error) {
+	scheme, rest, found := strings.Cut(url, "://")
+	switch {
+	case found && scheme == "file":
+		if rest == "" {
+			return nil, fmt.Errorf("blob store url %q has no path", url)
+		}
+		return NewFileStore(rest)
+	default:
+		return nil, fmt.Errorf("unsupported blob store url %q: expected a file:// url", url)
+	}
+}
+
+// validKey reports whether key is safe to use as a relative path: slash
+// separated, with no empty, "." or ".." segments.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for segment := range strings.SplitSeq(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsRune(segment, '\\') {
+			return false
+		}
+	}
+	return true
+}
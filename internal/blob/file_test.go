@@ -0,0 +1,64 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	if err := store.Put(ctx, "room/abc", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := store.Get(ctx, "room/abc")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(data) != "hello" {
+		t.Errorf("Get = %q, want hello", data)
+	}
+
+	if err := store.Delete(ctx, "room/abc"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "room/abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "room/abc"); err != nil {
+		t.Errorf("deleting a missing blob = %v", err)
+	}
+}
+
+func TestFileStore_RejectsUnsafeKeys(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	for _, key := range []string{"", "../escape", "a/../../b", "/abs", "a//b", `a\..\b`} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+	}
+}
+
+func TestOpen_PicksBackendFromScheme(t *testing.T) {
+	if _, err := Open("file://" + t.TempDir()); err != nil {
+		t.Errorf("Open(file://) = %v", err)
+	}
+	for _, url := range []string{"file://", "s3://bucket", "/var/lib/blobs"} {
+		if _, err := Open(url); err == nil {
+			t.Errorf("Open(%q) succeeded", url)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// attachmentSavedMsg reports a downloaded attachment, saved at path.
+type attachmentSavedMsg struct {
+	path   string
+	opened bool
+}
+
+// uploadFile sends the file at path to roomID. The server announces it to the
+// room, so success needs no message of its own.
+func (m Model) uploadFile(roomID, path, caption string) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Open(expandHome(path))
+		if err != nil {
+			return commandErrMsg("/upload failed: " + err.Error())
+		}
+		defer func() { _ = f.Close() }()
+		if info, err := f.Stat(); err != nil || info.IsDir() {
+			return commandErrMsg("/upload failed: " + path + " is not a file")
+		}
+
+		// Stream the form rather than buffering the whole file.
+		body, pw := io.Pipe()
+		form := multipart.NewWriter(pw)
+		go func() {
+			part, err := form.CreateFormFile("file", filepath.Base(path))
+			if err == nil {
+				_, err = io.Copy(part, f)
+			}
+			if err == nil {
+				err = form.WriteField("caption", caption)
+			}
+			if err == nil {
+				err = form.Close()
+			}
+			pw.CloseWithError(err)
+		}()
+
+		req, err := http.NewRequest("POST", m.config.httpURL("/rooms/"+roomID+"/attachments"), body)
+		if err != nil {
+			return commandErrMsg("/upload failed: " + err.Error())
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return commandErrMsg("/upload failed: " + err.Error())
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusCreated {
+			var apiErr struct {
+				Error string `json:"error"`
+			}
+			if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Error == "" {
+				apiErr.Error = fmt.Sprintf("server returned %d", resp.StatusCode)
+			}
+			return commandErrMsg("/upload failed: " + apiErr.Error)
+		}
+		return nil
+	}
+}
+
+// downloadAttachment saves the file attached to line in dir, without
+// overwriting anything already there, and opens it if open is set.
+func (m Model) downloadAttachment(line chatLine, dir string, open bool) tea.Cmd {
+	return func() tea.Msg {
+		req, err := http.NewRequest("GET", m.config.httpURL("/attachments/"+line.id), nil)
+		if err != nil {
+			return commandErrMsg("download failed: " + err.Error())
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return commandErrMsg("download failed: " + err.Error())
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Error string `json:"error"`
+			}
+			if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Error == "" {
+				apiErr.Error = fmt.Sprintf("server returned %d", resp.StatusCode)
+			}
+			return commandErrMsg("download failed: " + apiErr.Error)
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return commandErrMsg("download failed: " + err.Error())
+		}
+		f, path, err := createUnique(dir, filepath.Base(line.attachment.Name))
+		if err != nil {
+			return commandErrMsg("download failed: " + err.Error())
+		}
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			_ = f.Close()
+			_ = os.Remove(path)
+			return commandErrMsg("download failed: " + err.Error())
+		}
+		if err := f.Close(); err != nil {
+			return commandErrMsg("download failed: " + err.Error())
+		}
+
+		if open {
+			if err := openFile(path); err != nil {
+				return commandErrMsg(fmt.Sprintf("saved %s but could not open it: %v", path, err))
+			}
+		}
+		return attachmentSavedMsg{path: path, opened: open}
+	}
+}
+
+// attachmentTarget picks the attachment a command acts on: the selected line
+// if it has one, and the newest attachment in view otherwise.
+func (m Model) attachmentTarget() (chatLine, bool) {
+	if line, ok := m.selectedLine(); ok && line.attachment != nil && line.id != "" && !line.deleted {
+		return line, true
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		line := m.messages[i]
+		if line.attachment != nil && line.id != "" && !line.deleted {
+			return line, true
+		}
+	}
+	return chatLine{}, false
+}
+
+// downloadDir is where attachments are saved by default: ~/Downloads if it
+// exists, and the working directory otherwise.
+func downloadDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, "Downloads")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return "."
+}
+
+// openDir is where attachments are saved to be opened.
+func openDir() string {
+	return filepath.Join(os.TempDir(), "chatatui")
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// createUnique creates name in dir, adding " (1)", " (2)" and so on before the
+// extension if a file of that name already exists.
+func createUnique(dir, name string) (*os.File, string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", stem, i, ext)
+		}
+		path := filepath.Join(dir, candidate)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, path, err
+	}
+}
+
+// openFile opens path in the desktop's default application for it.
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// formatSize renders a byte count for display, e.g. "12 KB".
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/EwanGreer/chatatui/internal/server/hub"
@@ -15,7 +20,7 @@ import (
 )
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(textinput.Blink, m.fetchRooms(), m.tickCmd())
+	return tea.Batch(textinput.Blink, m.fetchRooms(), m.fetchUnread(), m.publishKey(), m.tickCmd())
 }
 
 func (m Model) fetchRooms() tea.Cmd {
@@ -47,11 +52,76 @@ func (m Model) fetchRooms() tea.Cmd {
 	}
 }
 
-func (m Model) createRoom(name string) tea.Cmd {
+func (m Model) fetchUnread() tea.Cmd {
+	return func() tea.Msg {
+		url := m.config.httpURL("/unread")
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return errMsg(err)
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errMsg(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return errMsg(fmt.Errorf("server returned %d", resp.StatusCode))
+		}
+
+		var counts []struct {
+			RoomID string `json:"room_id"`
+			Unread int    `json:"unread"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&counts); err != nil {
+			return errMsg(err)
+		}
+
+		unread := make(unreadMsg, len(counts))
+		for _, c := range counts {
+			unread[c.RoomID] = c.Unread
+		}
+		return unread
+	}
+}
+
+func (m Model) fetchMembers(roomID string) tea.Cmd {
+	return func() tea.Msg {
+		url := m.config.httpURL("/rooms/" + roomID + "/members")
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return errMsg(err)
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errMsg(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return errMsg(fmt.Errorf("server returned %d", resp.StatusCode))
+		}
+
+		var members []Member
+		if err := json.NewDecoder(resp.Body).Decode(&members); err != nil {
+			return errMsg(err)
+		}
+
+		return membersMsg{roomID: roomID, members: members}
+	}
+}
+
+func (m Model) createRoom(name, visibility string, encrypted bool) tea.Cmd {
 	return func() tea.Msg {
 		url := m.config.httpURL("/rooms")
 
-		payload := map[string]string{"name": name}
+		payload := map[string]any{"name": name, "visibility": visibility, "encrypted": encrypted}
 		body, err := json.Marshal(payload)
 		if err != nil {
 			return errMsg(err)
@@ -83,19 +153,228 @@ func (m Model) createRoom(name string) tea.Cmd {
 	}
 }
 
+// openDM asks the server for the direct message room with the named users,
+// creating it if needed.
+func (m Model) openDM(names []string) tea.Cmd {
+	return func() tea.Msg {
+		url := m.config.httpURL("/dms")
+
+		body, err := json.Marshal(map[string][]string{"names": names})
+		if err != nil {
+			return errMsg(err)
+		}
+
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+		if err != nil {
+			return errMsg(err)
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errMsg(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			var apiErr struct {
+				Error string `json:"error"`
+			}
+			_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+			return commandErrMsg(fmt.Sprintf("/dm failed: %s", apiErr.Error))
+		}
+
+		var room Room
+		if err := json.NewDecoder(resp.Body).Decode(&room); err != nil {
+			return errMsg(err)
+		}
+
+		return dmOpenedMsg(room)
+	}
+}
+
+// searchMessages runs a search typed into the search overlay. Words prefixed
+// with from:, in:, before: or after: become filters; in: takes a room name.
+func (m Model) searchMessages(input string) tea.Cmd {
+	params := url.Values{}
+	var words []string
+	for _, field := range strings.Fields(input) {
+		key, value, ok := strings.Cut(field, ":")
+		switch {
+		case ok && key == "from":
+			params.Set("author", value)
+		case ok && key == "in":
+			i := slices.IndexFunc(m.rooms, func(r Room) bool { return strings.EqualFold(r.Name, value) })
+			if i < 0 {
+				return func() tea.Msg { return searchMsg{query: input, err: "no room named " + value} }
+			}
+			params.Set("room", m.rooms[i].ID)
+		case ok && (key == "before" || key == "after"):
+			params.Set(key, value)
+		default:
+			words = append(words, field)
+		}
+	}
+	params.Set("q", strings.Join(words, " "))
+
+	return func() tea.Msg {
+		req, err := http.NewRequest("GET", m.config.httpURL("/search?"+params.Encode()), nil)
+		if err != nil {
+			return searchMsg{query: input, err: err.Error()}
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return searchMsg{query: input, err: err.Error()}
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Error string `json:"error"`
+			}
+			if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Error == "" {
+				apiErr.Error = fmt.Sprintf("server returned %d", resp.StatusCode)
+			}
+			return searchMsg{query: input, err: apiErr.Error}
+		}
+
+		var page struct {
+			Messages []wireMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			return searchMsg{query: input, err: err.Error()}
+		}
+		return searchMsg{query: input, results: page.Messages}
+	}
+}
+
+// mentionsLimit is how many recent mentions /mentions lists.
+const mentionsLimit = 20
+
+// fetchMentions fetches the newest messages that mention the user.
+func (m Model) fetchMentions() tea.Cmd {
+	return func() tea.Msg {
+		req, err := http.NewRequest("GET", m.config.httpURL(fmt.Sprintf("/mentions?limit=%d", mentionsLimit)), nil)
+		if err != nil {
+			return commandErrMsg("/mentions failed: " + err.Error())
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return commandErrMsg("/mentions failed: " + err.Error())
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return commandErrMsg(fmt.Sprintf("/mentions failed: server returned %d", resp.StatusCode))
+		}
+
+		var page struct {
+			Mentions []wireMessage `json:"mentions"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			return commandErrMsg("/mentions failed: " + err.Error())
+		}
+		return mentionsMsg(page.Mentions)
+	}
+}
+
+// leaveRoom removes the user from a room.
+func (m Model) leaveRoom(roomID string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.apiRequest("DELETE", "/rooms/"+roomID+"/members/me", nil, http.StatusNoContent); err != nil {
+			return commandErrMsg("/leave failed: " + err.Error())
+		}
+		return roomLeftMsg(roomID)
+	}
+}
+
+// setNick renames the user.
+func (m Model) setNick(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.apiRequest("PUT", "/users/me", map[string]string{"name": name}, http.StatusOK); err != nil {
+			return commandErrMsg("/nick failed: " + err.Error())
+		}
+		return nickChangedMsg(name)
+	}
+}
+
+// setTopic changes a room's topic. The change is announced over the
+// WebSocket, so success needs no message of its own.
+func (m Model) setTopic(roomID, topic string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.apiRequest("PUT", "/rooms/"+roomID+"/topic", map[string]string{"topic": topic}, http.StatusNoContent); err != nil {
+			return commandErrMsg("/topic failed: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// setRetention changes how long a room keeps its messages. The server may
+// hold them for less, so the rooms are fetched again to show what applies.
+func (m Model) setRetention(roomID string, retention Retention) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.apiRequest("PUT", "/rooms/"+roomID+"/retention", retention, http.StatusOK); err != nil {
+			return commandErrMsg("/retention failed: " + err.Error())
+		}
+		return retentionMsg(roomID)
+	}
+}
+
+// apiRequest sends a JSON request to the server and checks for the expected
+// status, returning the server's error message otherwise.
+func (m Model) apiRequest(method, path string, payload any, wantStatus int) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, m.config.httpURL(path), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", m.config.APIKey)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != wantStatus {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Error == "" {
+			return fmt.Errorf("server returned %d", resp.StatusCode)
+		}
+		return errors.New(apiErr.Error)
+	}
+	return nil
+}
+
 func (m Model) tickCmd() tea.Cmd {
 	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-func (m *Model) connectToRoom(roomID string) tea.Cmd {
+// connect dials the multiplexed WebSocket endpoint, which carries every room
+// the user subscribes to.
+func (m *Model) connect() tea.Cmd {
 	return func() tea.Msg {
-		if m.conn != nil {
-			_ = m.conn.Close(websocket.StatusNormalClosure, "switching rooms")
-		}
-
-		url := m.config.wsURL("/ws/" + roomID)
+		url := m.config.wsURL("/ws")
 
 		ctx := context.Background()
 		conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
@@ -106,8 +385,9 @@ func (m *Model) connectToRoom(roomID string) tea.Cmd {
 		if err != nil {
 			return errMsg(err)
 		}
+		conn.SetReadLimit(maxReadBytes)
 
-		return connectedMsg{roomID: roomID, conn: conn}
+		return connectedMsg(conn)
 	}
 }
 
@@ -119,7 +399,7 @@ func (m *Model) listenForMessages() tea.Cmd {
 
 		_, data, err := m.conn.Read(context.Background())
 		if err != nil {
-			// Ignore normal close errors (happens when switching rooms)
+			// Ignore normal close errors (happens when replacing the connection)
 			if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
 				return nil
 			}
@@ -127,29 +407,90 @@ func (m *Model) listenForMessages() tea.Cmd {
 		}
 
 		var wire wireMessage
-		if err := json.Unmarshal(data, &wire); err == nil {
-			if wire.Type == hub.MessageTypeTyping.String() {
-				return typingMsg(wire.Author)
+		if err := json.Unmarshal(data, &wire); err != nil {
+			return incomingMsg{
+				Type:      hub.MessageTypeChat.String(),
+				Content:   string(data),
+				Timestamp: time.Now(),
 			}
-			return incomingMsg{formatted: formatWireMessage(data), author: wire.Author}
 		}
 
-		return incomingMsg{formatted: string(data)}
+		switch wire.Type {
+		case hub.MessageTypeTyping.String():
+			return typingMsg(wire)
+		case hub.MessageTypeHistory.String():
+			return historyMsg{roomID: wire.RoomID, messages: wire.Messages, hasMore: wire.HasMore}
+		case hub.MessageTypeThread.String():
+			return threadMsg{roomID: wire.RoomID, parentID: wire.ParentID, replies: wire.Messages}
+		case hub.MessageTypeSubscribe.String():
+			return subscribedMsg(wire.RoomID)
+		case hub.MessageTypeUnsubscribe.String():
+			return unsubscribedMsg(wire)
+		case hub.MessageTypeSystem.String():
+			if wire.Event == "" {
+				break // a notice, such as one posted to an incoming webhook
+			}
+			return presenceMsg(wire)
+		case hub.MessageTypeRead.String():
+			return readReceiptMsg(wire)
+		}
+		return incomingMsg(wire)
 	}
 }
 
-func sendMessageCmd(conn *websocket.Conn, text string) tea.Cmd {
-	return func() tea.Msg {
-		if err := conn.Write(context.Background(), websocket.MessageText, []byte(text)); err != nil {
-			return errMsg(err)
-		}
-		return nil
-	}
+func sendMessageCmd(conn *websocket.Conn, roomID, text string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeChat, RoomID: roomID, Content: text})
+}
+
+func sendActionCmd(conn *websocket.Conn, roomID, text string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeAction, RoomID: roomID, Content: text})
+}
+
+func sendReplyCmd(conn *websocket.Conn, roomID, parentID, text string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeThreadReply, RoomID: roomID, ParentID: parentID, Content: text})
+}
+
+// sendThreadCmd asks for every reply to parentID.
+func sendThreadCmd(conn *websocket.Conn, roomID, parentID string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeThread, RoomID: roomID, ParentID: parentID})
+}
+
+func sendReactionCmd(conn *websocket.Conn, roomID string, kind hub.MessageType, id, emoji string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: kind, RoomID: roomID, ID: id, Content: emoji})
+}
+
+func sendSubscribeCmd(conn *websocket.Conn, roomID string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeSubscribe, RoomID: roomID})
+}
+
+func sendTypingCmd(conn *websocket.Conn, roomID string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeTyping, RoomID: roomID})
+}
+
+func sendPresenceCmd(conn *websocket.Conn, event string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeSystem, Event: event})
+}
+
+func sendReadCmd(conn *websocket.Conn, roomID, id string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeRead, RoomID: roomID, ID: id})
+}
+
+func sendEditCmd(conn *websocket.Conn, roomID, id, text string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeEdit, RoomID: roomID, ID: id, Content: text})
+}
+
+func sendDeleteCmd(conn *websocket.Conn, roomID, id string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeDelete, RoomID: roomID, ID: id})
+}
+
+// sendHistoryCmd asks for the page of roomID's messages before the given ID,
+// or the newest page if before is empty.
+func sendHistoryCmd(conn *websocket.Conn, roomID, before string) tea.Cmd {
+	return sendWireCmd(conn, &hub.WireMessage{Type: hub.MessageTypeHistory, RoomID: roomID, ID: before})
 }
 
-func sendTypingCmd(conn *websocket.Conn) tea.Cmd {
+func sendWireCmd(conn *websocket.Conn, msg *hub.WireMessage) tea.Cmd {
 	return func() tea.Msg {
-		msg := &hub.WireMessage{Type: hub.MessageTypeTyping}
 		data, err := msg.Marshal()
 		if err != nil {
 			return errMsg(err)
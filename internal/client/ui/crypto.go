@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxReadBytes bounds a single message read from the WebSocket. A page of
+// encrypted history is several times the size of a plaintext one, well over
+// the library's 32 KiB default.
+const maxReadBytes = 4 << 20
+
+// memberKey is a room member's entry in the server's key directory.
+type memberKey struct {
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+type keysMsg struct {
+	roomID string
+	keys   []memberKey
+}
+
+// publishKey puts the user's public key in the key directory, so that others
+// can encrypt messages for them.
+func (m Model) publishKey() tea.Cmd {
+	if m.config.Key == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		payload := map[string]string{"public_key": m.config.Key.PublicKey()}
+		if err := m.apiRequest("PUT", "/users/me/key", payload, http.StatusNoContent); err != nil {
+			return commandErrMsg("could not publish your encryption key: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// fetchKeys loads the public keys of an encrypted room's members. Ordinary
+// rooms need none, so it does nothing for them.
+func (m Model) fetchKeys(roomID string) tea.Cmd {
+	if !m.isEncrypted(roomID) {
+		return nil
+	}
+	return func() tea.Msg {
+		req, err := http.NewRequest("GET", m.config.httpURL("/rooms/"+roomID+"/keys"), nil)
+		if err != nil {
+			return commandErrMsg("could not fetch room keys: " + err.Error())
+		}
+		req.Header.Set("Authorization", m.config.APIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return commandErrMsg("could not fetch room keys: " + err.Error())
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return commandErrMsg(fmt.Sprintf("could not fetch room keys: server returned %d", resp.StatusCode))
+		}
+
+		var keys []memberKey
+		if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+			return commandErrMsg("could not fetch room keys: " + err.Error())
+		}
+		return keysMsg{roomID: roomID, keys: keys}
+	}
+}
+
+func (m Model) isEncrypted(roomID string) bool {
+	i := m.roomIndexOf(roomID)
+	return i >= 0 && m.rooms[i].Encrypted
+}
+
+// seal returns what to send for text in roomID: the text itself in ordinary
+// rooms, and an envelope for every member with a published key in encrypted
+// ones. It reports false, after telling the user why, if the message cannot
+// be sent yet.
+func (m *Model) seal(roomID, text string) (string, bool) {
+	if !m.isEncrypted(roomID) {
+		return text, true
+	}
+	if m.config.Key == nil {
+		m.appendNotice("this room is encrypted and you have no key")
+		return "", false
+	}
+	keys, ok := m.roomKeys[roomID]
+	if !ok {
+		m.appendNotice("still fetching this room's keys, try again in a moment")
+		return "", false
+	}
+
+	recipients := make([]string, 0, len(keys))
+	for _, k := range keys {
+		recipients = append(recipients, k.PublicKey)
+	}
+	sealed, err := e2e.Seal([]byte(text), m.config.Key, recipients)
+	if err != nil {
+		m.appendNotice("could not encrypt message: " + err.Error())
+		return "", false
+	}
+	return string(sealed), true
+}
+
+// decryptWire replaces the envelope in an encrypted message, and in any
+// messages it carries, with the plaintext. A message whose sealing key is not
+// the one the directory lists for its author is marked unverified.
+func (m Model) decryptWire(wire *wireMessage) {
+	for i := range wire.Messages {
+		m.decryptWire(&wire.Messages[i])
+	}
+
+	switch wire.Type {
+	case hub.MessageTypeChat.String(), hub.MessageTypeAction.String(), hub.MessageTypeThreadReply.String(),
+		hub.MessageTypeEdit.String(), hub.MessageTypeAck.String():
+	default:
+		return
+	}
+	if !e2e.IsEnvelope([]byte(wire.Content)) {
+		return
+	}
+	if m.config.Key == nil {
+		wire.Content = "🔒 encrypted message"
+		return
+	}
+
+	plaintext, senderKey, err := e2e.Open([]byte(wire.Content), m.config.Key)
+	switch {
+	case errors.Is(err, e2e.ErrNotRecipient):
+		wire.Content = "🔒 encrypted message not sent to your key"
+		return
+	case err != nil:
+		wire.Content = "🔒 unable to decrypt this message"
+		return
+	}
+	wire.Content = string(plaintext)
+	wire.unverified = !m.senderKeyMatches(wire.RoomID, wire.Author, senderKey)
+}
+
+// senderKeyMatches reports whether senderKey is the directory's key for the
+// named author. Authors the directory does not list, such as members who have
+// since left or been renamed, cannot be checked and are given the benefit of
+// the doubt.
+func (m Model) senderKeyMatches(roomID, author, senderKey string) bool {
+	if author == "" {
+		return true
+	}
+	for _, k := range m.roomKeys[roomID] {
+		if k.Name == author {
+			return k.PublicKey == senderKey
+		}
+	}
+	return true
+}
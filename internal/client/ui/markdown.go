@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// markdownChars are the characters that can start markdown formatting. Text
+// without any of them, on a single line, renders as itself.
+const markdownChars = "*_`[]<>#~|\\!"
+
+// markdownStyle is glamour's dark style without the document margins and
+// blank lines around it, so that message bodies sit flush in the viewport.
+var markdownStyle = func() ansi.StyleConfig {
+	style := styles.DarkStyleConfig
+	style.Document.Margin = nil
+	style.Document.BlockPrefix = ""
+	style.Document.BlockSuffix = ""
+	style.Document.Color = nil
+	style.CodeBlock.Margin = nil
+	return style
+}()
+
+// markdownRenderer renders message bodies for one viewport width, caching
+// the results: the viewport is re-rendered on every selection change and
+// incoming message, and glamour is too slow to run over the whole history
+// each time.
+type markdownRenderer struct {
+	width    int
+	renderer *glamour.TermRenderer
+	cache    map[string]string
+}
+
+// render returns text rendered as markdown wrapped to width. If the text
+// cannot be rendered it is returned as it was.
+func (r *markdownRenderer) render(text string, width int) string {
+	width = max(width, 10)
+	if r.renderer == nil || r.width != width {
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithStyles(markdownStyle),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return text
+		}
+		r.width, r.renderer, r.cache = width, renderer, make(map[string]string)
+	}
+
+	// Tabs would throw out the viewport's width calculations.
+	text = strings.ReplaceAll(text, "\t", "    ")
+	if !strings.ContainsAny(text, markdownChars+"\n") && xansi.StringWidth(text) <= width {
+		return text
+	}
+	if out, ok := r.cache[text]; ok {
+		return out
+	}
+	out, err := r.renderer.Render(text)
+	if err != nil {
+		return text
+	}
+	out = trimRendered(out)
+	r.cache[text] = out
+	return out
+}
+
+// trimRendered drops the blank lines glamour puts around blocks and the
+// padding it adds to fill each line out to the wrap width.
+func trimRendered(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = trimTrailingSpace(line)
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// trimTrailingSpace removes trailing spaces from a line that may contain
+// escape sequences, keeping the styling of what is left.
+func trimTrailingSpace(line string) string {
+	plain := xansi.Strip(line)
+	trimmed := strings.TrimRight(plain, " ")
+	if trimmed == "" {
+		return ""
+	}
+	if len(trimmed) == len(plain) {
+		return line
+	}
+	return xansi.Truncate(line, xansi.StringWidth(trimmed), "") + "\x1b[0m"
+}
+
+// hasOpenFence reports whether text has a ``` code fence that has not been
+// closed, in which case enter continues the block rather than sending.
+func hasOpenFence(text string) bool {
+	open := false
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			open = !open
+		}
+	}
+	return open
+}
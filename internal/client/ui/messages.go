@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// messageIndent is how far message bodies that span several lines are
+// indented beneath their author.
+const messageIndent = 2
+
+// chatLine is a single entry in the message viewport. Lines are kept
+// structured rather than pre-rendered so that edits and deletes received from
+// the server can rewrite them in place.
+type chatLine struct {
+	id        string
+	kind      string
+	author    string
+	content   string
+	timestamp time.Time
+	own       bool
+	pending   bool // own message not yet acknowledged by the server
+	edited    bool
+	deleted   bool
+	// unverified marks an encrypted message whose sender's key did not
+	// match the key directory.
+	unverified bool
+	mentioned  bool // the message @mentions the user
+	// replyCount and lastReplyAt summarise the thread a message started.
+	replyCount  int
+	lastReplyAt time.Time
+	reactions   []hub.Reaction
+	attachment  *hub.Attachment
+}
+
+func lineFromWire(wire wireMessage) chatLine {
+	line := chatLine{
+		id:         wire.ID,
+		kind:       wire.Type,
+		author:     wire.Author,
+		content:    wire.Content,
+		timestamp:  wire.Timestamp,
+		edited:     wire.EditedAt != nil,
+		unverified: wire.unverified,
+		replyCount: wire.ReplyCount,
+		reactions:  wire.Reactions,
+		attachment: wire.Attachment,
+	}
+	if wire.LastReplyAt != nil {
+		line.lastReplyAt = *wire.LastReplyAt
+	}
+	return line
+}
+
+// applyWire folds an incoming wire message into the line list. It reports
+// whether a new line was appended to the view, as opposed to an existing line
+// changing or the message landing in the room behind an open thread.
+func (m *Model) applyWire(wire wireMessage) bool {
+	switch wire.Type {
+	case hub.MessageTypeEdit.String():
+		m.updateLine(wire.ID, func(line *chatLine) {
+			line.content = wire.Content
+			line.edited = true
+			line.unverified = wire.unverified
+		})
+		return false
+	case hub.MessageTypeDelete.String():
+		m.updateLine(wire.ID, func(line *chatLine) { line.deleted = true })
+		return false
+	case hub.MessageTypeReact.String(), hub.MessageTypeUnreact.String():
+		add := wire.Type == hub.MessageTypeReact.String()
+		m.updateLine(wire.ID, func(line *chatLine) { line.react(wire.Content, add, false) })
+		return false
+	case hub.MessageTypeAck.String():
+		lines := m.roomLines()
+		if wire.ParentID != "" {
+			m.countReply(wire)
+			if m.thread == nil || m.thread.parentID != wire.ParentID {
+				return false
+			}
+			lines = &m.messages
+		}
+		for i := range *lines {
+			line := &(*lines)[i]
+			if line.own && line.pending && line.content == wire.Content {
+				line.id = wire.ID
+				line.timestamp = wire.Timestamp
+				line.pending = false
+				break
+			}
+		}
+		return false
+	case hub.MessageTypeThreadReply.String():
+		m.countReply(wire)
+		if m.thread == nil || m.thread.parentID != wire.ParentID {
+			return false
+		}
+		m.messages = append(m.messages, lineFromWire(wire))
+		return true
+	}
+
+	lines := m.roomLines()
+	*lines = append(*lines, lineFromWire(wire))
+	return m.thread == nil
+}
+
+// prependHistory inserts an older page of history above the current lines,
+// keeping the viewport anchored on what the user was looking at.
+func (m *Model) prependHistory(wires []wireMessage) {
+	lines := make([]chatLine, 0, len(wires))
+	for _, wire := range wires {
+		if m.lineIndex(wire.ID) < 0 {
+			lines = append(lines, lineFromWire(wire))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	yOffset := m.viewport.YOffset
+	m.messages = append(lines, m.messages...)
+	if m.selected >= 0 {
+		m.selected += len(lines)
+	}
+	m.updateViewportContent()
+	if len(lines) < len(m.lineOffsets) {
+		m.viewport.SetYOffset(yOffset + m.lineOffsets[len(lines)])
+	}
+}
+
+// oldestMessageID returns the ID of the oldest persisted message loaded, used
+// as the cursor when paging back through history.
+func (m Model) oldestMessageID() string {
+	for _, line := range m.messages {
+		if line.id != "" {
+			return line.id
+		}
+	}
+	return ""
+}
+
+// newestMessageID returns the ID of the newest persisted message loaded.
+func (m Model) newestMessageID() string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].id != "" && m.messages[i].isMessage() {
+			return m.messages[i].id
+		}
+	}
+	return ""
+}
+
+func (m *Model) appendOwnLine(kind, text string) {
+	m.messages = append(m.messages, chatLine{
+		kind:      kind,
+		author:    "You",
+		content:   text,
+		timestamp: time.Now(),
+		own:       true,
+		pending:   true,
+	})
+}
+
+func (m Model) lineIndex(id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, line := range m.messages {
+		if line.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// isMessage reports whether the line is something a member said, as opposed
+// to a notice or room event.
+func (l chatLine) isMessage() bool {
+	switch l.kind {
+	case hub.MessageTypeChat.String(), hub.MessageTypeAction.String(), hub.MessageTypeThreadReply.String(), hub.MessageTypeFile.String():
+		return true
+	}
+	return false
+}
+
+// selectedLine returns the message highlighted in the viewport, if any.
+func (m Model) selectedLine() (chatLine, bool) {
+	if m.selected < 0 || m.selected >= len(m.messages) {
+		return chatLine{}, false
+	}
+	return m.messages[m.selected], true
+}
+
+// canModify reports whether the line is one of the user's own messages that
+// the server has acknowledged and that has not been deleted.
+func (l chatLine) canModify() bool {
+	return l.own && !l.pending && !l.deleted && l.id != ""
+}
+
+// renderLine renders a line for a viewport width, with message bodies
+// rendered as markdown.
+func renderLine(line chatLine, md *markdownRenderer, width int) string {
+	ts := line.timestamp.Local().Format("15:04")
+
+	if line.kind == hub.MessageTypeError.String() {
+		return styleError.Italic(true).Render(fmt.Sprintf("%s ! %s", ts, line.content))
+	}
+
+	if line.kind == hub.MessageTypeTopic.String() {
+		if line.content == "" {
+			return styleMuted.Italic(true).Render(fmt.Sprintf("%s %s cleared the topic", ts, line.author))
+		}
+		return styleMuted.Italic(true).Render(fmt.Sprintf("%s %s set the topic to: %s", ts, line.author, line.content))
+	}
+
+	if line.kind == hub.MessageTypeSystem.String() {
+		return styleMuted.Render(fmt.Sprintf("%s -- %s: %s", ts, line.author, line.content))
+	}
+
+	if line.deleted {
+		return styleMuted.Italic(true).Render(fmt.Sprintf("%s %s: message deleted", ts, line.author))
+	}
+
+	prefix := fmt.Sprintf("%s %s: ", ts, line.author)
+	if line.kind == hub.MessageTypeAction.String() {
+		prefix = fmt.Sprintf("%s * %s ", ts, line.author)
+	}
+	if line.mentioned {
+		prefix = styleMention.Render(strings.TrimRight(prefix, " ")) + " "
+	}
+	// A body that renders to one line follows the author; anything longer
+	// starts on the next line, indented beneath it.
+	body := md.render(line.content, width-messageIndent)
+	text := prefix + body
+	if strings.Contains(body, "\n") || xansi.StringWidth(text) > width {
+		text = strings.TrimRight(prefix, " ") + "\n" + indentLines(body, messageIndent)
+	}
+	if a := line.attachment; a != nil {
+		text = fmt.Sprintf("%s %s: 📎 %s %s", ts, line.author, a.Name, styleMuted.Render("("+formatSize(a.Size)+")"))
+		if line.content != "" {
+			text += " " + line.content
+		}
+	}
+	if line.edited {
+		text += styleMuted.Render(" (edited)")
+	}
+	if line.unverified {
+		text += styleError.Render(" (unverified sender)")
+	}
+	switch line.replyCount {
+	case 0:
+	case 1:
+		text += styleMuted.Render(fmt.Sprintf(" [1 reply, %s]", line.lastReplyAt.Local().Format("15:04")))
+	default:
+		text += styleMuted.Render(fmt.Sprintf(" [%d replies, last %s]", line.replyCount, line.lastReplyAt.Local().Format("15:04")))
+	}
+	if len(line.reactions) > 0 {
+		text += "\n" + renderReactions(line.reactions)
+	}
+	return text
+}
+
+func indentLines(s string, n int) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
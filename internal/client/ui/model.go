@@ -1,17 +1,30 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/e2e"
 	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/coder/websocket"
 )
 
 const typingUserTTL = 4 * time.Second
 
+// readFlushDelay batches read events so that a burst of messages, such as the
+// history sent on join, produces a single read event.
+const readFlushDelay = 500 * time.Millisecond
+
+// idleAfter is how long without a keypress before the user is shown as away.
+const idleAfter = 5 * time.Minute
+
 type focus int
 
 const (
@@ -19,6 +32,7 @@ const (
 	focusMessages
 	focusInput
 	focusCreateRoom
+	focusSearch
 )
 
 type connState int
@@ -30,67 +44,202 @@ const (
 )
 
 type Room struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+	Kind       string `json:"kind"`
+	Topic      string `json:"topic"`
+	Encrypted  bool   `json:"encrypted"`
+	// Retention is nil when the room keeps its messages forever.
+	Retention *Retention `json:"retention"`
+}
+
+// Retention is how long a room keeps its messages. A zero field is unlimited.
+type Retention struct {
+	MaxAgeDays  int `json:"max_age_days"`
+	MaxMessages int `json:"max_messages"`
+}
+
+// String describes the policy, e.g. "30d / last 1000".
+func (r Retention) String() string {
+	var parts []string
+	if r.MaxAgeDays > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", r.MaxAgeDays))
+	}
+	if r.MaxMessages > 0 {
+		parts = append(parts, fmt.Sprintf("last %d", r.MaxMessages))
+	}
+	if len(parts) == 0 {
+		return "forever"
+	}
+	return strings.Join(parts, " / ")
+}
+
+func (r Room) isDM() bool {
+	return r.Kind == "dm"
+}
+
+type Member struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
 }
 
+// roomVisibilities is the order the create room modal cycles through.
+var roomVisibilities = []string{"public", "invite_only", "private"}
+
 type Config struct {
 	ServerAddr string
 	APIKey     string
+	// Key encrypts and decrypts messages in encrypted rooms.
+	Key *e2e.Key
 }
 
 type Model struct {
-	config          Config
-	viewport        viewport.Model
-	input           textinput.Model
-	createRoomInput textinput.Model
-	rooms           []Room
-	messages        []string
-	focus           focus
-	width           int
-	height          int
-	ready           bool
-	roomIndex       int
-	err             error
-	conn            *websocket.Conn
-	connectedTo     string
-	state           connState
-	reconnectDelay  time.Duration
-	typingUsers     map[string]time.Time
-	lastTypingSent  time.Time
+	config              Config
+	viewport            viewport.Model
+	markdown            *markdownRenderer
+	input               textarea.Model
+	createRoomInput     textinput.Model
+	createRoomVis       int
+	createRoomEncrypted bool
+	searchInput         textinput.Model
+	searchResults       []wireMessage
+	searchIndex         int
+	searchedFor         string // query the current results are for
+	searchErr           string
+	jumpTo              string // ID of a search hit to select once it is loaded
+	completions         []string
+	completionIndex     int
+	completionBase      string // input before the word being completed
+	completedTo         string // input as last completed, to detect repeated tabs
+	rooms               []Room
+	members             []Member
+	roomKeys            map[string][]memberKey // room ID -> members' public keys
+	unread              map[string]int         // room ID -> unread count
+	mentioned           map[string]bool        // IDs of messages that mention the user
+	mentionedIn         map[string]bool        // rooms with mentions not yet looked at
+	readBy              map[string]string      // reader name -> newest message ID they read
+	lastReadID          string
+	readScheduled       bool
+	messages            []chatLine
+	selected            int
+	lineOffsets         []int
+	editingID           string
+	thread              *threadView // open thread, if any
+	reacting            bool        // the reaction picker is open
+	historyLoading      bool
+	historyDone         bool
+	focus               focus
+	width               int
+	height              int
+	ready               bool
+	roomIndex           int
+	err                 error
+	conn                *websocket.Conn
+	connectedTo         string          // room shown in the message view
+	pendingRoom         string          // room we asked to subscribe to and will switch to
+	subscribed          map[string]bool // rooms subscribed to on conn
+	historyInitial      bool            // the next history page is the newest, not an older one
+	state               connState
+	reconnectDelay      time.Duration
+	typingUsers         map[string]time.Time
+	lastTypingSent      time.Time
+	lastInput           time.Time
+	away                bool
 }
 
 type (
-	roomsMsg     []Room
-	errMsg       error
-	connectedMsg struct {
-		roomID string
-		conn   *websocket.Conn
-	}
+	roomsMsg       []Room
+	errMsg         error
+	connectedMsg   *websocket.Conn
 	roomCreatedMsg Room
+	unreadMsg      map[string]int
+	readFlushMsg   struct{}
+	dmOpenedMsg    Room
+	commandErrMsg  string // shown inline when a slash command fails
+	roomLeftMsg    string // ID of a room the user left with /leave
+	nickChangedMsg string
+	retentionMsg   string // ID of a room whose retention was changed
 	tickMsg        time.Time
-	reconnectMsg   string
+	reconnectMsg   struct{}
 )
 
-type incomingMsg struct {
-	formatted string
-	author    string
+type incomingMsg wireMessage
+
+type typingMsg wireMessage
+
+type subscribedMsg string // ID of a room the server subscribed us to
+
+type unsubscribedMsg wireMessage
+
+type presenceMsg wireMessage
+
+type readReceiptMsg wireMessage
+
+type membersMsg struct {
+	roomID  string
+	members []Member
+}
+
+type historyMsg struct {
+	roomID   string
+	messages []wireMessage
+	hasMore  bool
+}
+
+type threadMsg struct {
+	roomID   string
+	parentID string
+	replies  []wireMessage
 }
 
-type typingMsg string // username of the person who is typing
+type mentionsMsg []wireMessage
+
+type searchMsg struct {
+	query   string
+	results []wireMessage
+	err     string
+}
 
 type wireMessage struct {
-	Type      string    `json:"type"`
-	ID        string    `json:"id"`
-	Author    string    `json:"author"`
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
+	Type      string     `json:"type"`
+	RoomID    string     `json:"room_id,omitempty"`
+	ID        string     `json:"id"`
+	Author    string     `json:"author"`
+	Content   string     `json:"content"`
+	Timestamp time.Time  `json:"timestamp"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+
+	ParentID    string          `json:"parent_id,omitempty"`
+	ReplyCount  int             `json:"reply_count,omitempty"`
+	LastReplyAt *time.Time      `json:"last_reply_at,omitempty"`
+	Reactions   []hub.Reaction  `json:"reactions,omitempty"`
+	Attachment  *hub.Attachment `json:"attachment,omitempty"`
+
+	Messages []wireMessage `json:"messages,omitempty"`
+	HasMore  bool          `json:"has_more,omitempty"`
+
+	Event  string `json:"event,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+
+	// unverified is set on decrypted messages sealed with a key other than
+	// the one the key directory lists for their author.
+	unverified bool
 }
 
 func NewModel(cfg Config) *Model {
-	ti := textinput.New()
+	ti := textarea.New()
 	ti.Placeholder = "Type a message..."
 	ti.CharLimit = limits.MaxMessageLength
+	ti.Prompt = "> "
+	ti.ShowLineNumbers = false
+	ti.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ti.SetHeight(1)
+	// Enter sends; these keys start a new line instead. Terminals do not
+	// report shift+enter as distinct from enter, so it cannot be used here.
+	ti.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
 	ti.Focus()
 
 	createInput := textinput.New()
@@ -98,15 +247,29 @@ func NewModel(cfg Config) *Model {
 	createInput.CharLimit = limits.MaxRoomNameLength
 	createInput.Width = 30
 
+	searchInput := textinput.New()
+	searchInput.Placeholder = "words from:name in:room after:2006-01-02"
+	searchInput.Width = 50
+
 	return &Model{
 		config:          cfg,
 		input:           ti,
+		markdown:        &markdownRenderer{},
 		createRoomInput: createInput,
+		searchInput:     searchInput,
 		rooms:           []Room{},
-		messages:        []string{},
+		messages:        []chatLine{},
+		selected:        -1,
 		focus:           focusInput,
 		reconnectDelay:  time.Second,
+		subscribed:      make(map[string]bool),
 		typingUsers:     make(map[string]time.Time),
+		unread:          make(map[string]int),
+		mentioned:       make(map[string]bool),
+		mentionedIn:     make(map[string]bool),
+		roomKeys:        make(map[string][]memberKey),
+		readBy:          make(map[string]string),
+		lastInput:       time.Now(),
 	}
 }
 
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reactionChoices are offered by the picker opened with "+", selected by
+// their position starting from 1.
+var reactionChoices = []string{"👍", "❤️", "😂", "🎉", "👀", "🙏"}
+
+// pickReaction toggles the user's reaction to the selected message with the
+// choice bound to key, updating the line before the server confirms.
+func (m *Model) pickReaction(key string) tea.Cmd {
+	m.reacting = false
+	n := 0
+	if _, err := fmt.Sscanf(key, "%d", &n); err != nil || n < 1 || n > len(reactionChoices) {
+		m.updateViewportContent()
+		return nil
+	}
+	line, ok := m.selectedLine()
+	if !ok || !line.isMessage() || line.id == "" || line.deleted || m.conn == nil {
+		m.updateViewportContent()
+		return nil
+	}
+
+	emoji := reactionChoices[n-1]
+	mine := slices.ContainsFunc(line.reactions, func(r hub.Reaction) bool { return r.Emoji == emoji && r.Mine })
+	m.updateLine(line.id, func(l *chatLine) { l.react(emoji, !mine, true) })
+	m.updateViewportContent()
+	if mine {
+		return sendReactionCmd(m.conn, m.connectedTo, hub.MessageTypeUnreact, line.id, emoji)
+	}
+	return sendReactionCmd(m.conn, m.connectedTo, hub.MessageTypeReact, line.id, emoji)
+}
+
+// react counts a reaction being added or withdrawn. own marks it as the
+// user's.
+func (l *chatLine) react(emoji string, add, own bool) {
+	i := slices.IndexFunc(l.reactions, func(r hub.Reaction) bool { return r.Emoji == emoji })
+	if add {
+		if i < 0 {
+			l.reactions = append(l.reactions, hub.Reaction{Emoji: emoji})
+			i = len(l.reactions) - 1
+		}
+		l.reactions[i].Count++
+		l.reactions[i].Mine = l.reactions[i].Mine || own
+		return
+	}
+	if i < 0 {
+		return
+	}
+	l.reactions[i].Count--
+	if own {
+		l.reactions[i].Mine = false
+	}
+	if l.reactions[i].Count <= 0 {
+		l.reactions = slices.Delete(l.reactions, i, i+1)
+	}
+}
+
+// renderReactions lists a line's reactions, highlighting the user's own.
+func renderReactions(reactions []hub.Reaction) string {
+	parts := make([]string, len(reactions))
+	for i, r := range reactions {
+		part := fmt.Sprintf("%s %d", r.Emoji, r.Count)
+		if r.Mine {
+			parts[i] = styleHelpKey.Render(part)
+		} else {
+			parts[i] = styleMuted.Render(part)
+		}
+	}
+	return "      " + strings.Join(parts, "  ")
+}
+
+// reactionPicker is shown on the status line while choosing a reaction.
+func reactionPicker() string {
+	parts := make([]string, len(reactionChoices))
+	for i, emoji := range reactionChoices {
+		parts[i] = styleHelpKey.Render(fmt.Sprint(i+1)) + " " + emoji
+	}
+	return " react: " + strings.Join(parts, "  ") + styleMuted.Render("  (esc to cancel)")
+}
@@ -0,0 +1,345 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// argKind says what a slash command's arguments are, for tab completion.
+type argKind int
+
+const (
+	argNone argKind = iota
+	argRoom
+	argUser
+)
+
+// slashCommand is a command typed into the input box, such as "/join general".
+// Commands run client side; only their effects reach the server.
+type slashCommand struct {
+	name  string
+	usage string
+	help  string
+	args  argKind
+	run   func(m *Model, args []string) tea.Cmd
+}
+
+// slashCommands is the registry of commands, in the order /help lists them. It
+// is filled in by init because /help refers back to it.
+var slashCommands []slashCommand
+
+func init() {
+	slashCommands = []slashCommand{
+		{name: "join", usage: "<room>", help: "switch to a room", args: argRoom, run: runJoin},
+		{name: "create", usage: "<name> [public|invite-only|private] [encrypted]", help: "create a room", run: runCreate},
+		{name: "leave", help: "leave the current room", run: runLeave},
+		{name: "dm", usage: "<user> [user...]", help: "open a direct message", args: argUser, run: runDM},
+		{name: "me", usage: "<action>", help: "send an action, e.g. /me waves", run: runMe},
+		{name: "upload", usage: "<path> [caption]", help: "send a file to the room", run: runUpload},
+		{name: "download", usage: "[dir]", help: "save the selected or latest file", run: runDownload},
+		{name: "open", help: "open the selected or latest file", run: runOpen},
+		{name: "nick", usage: "<name>", help: "change your name", run: runNick},
+		{name: "topic", usage: "[topic]", help: "show or set the room topic", run: runTopic},
+		{name: "retention", usage: "[days] [max messages]", help: "show or set how long the room keeps messages (0 = forever)", run: runRetention},
+		{name: "mentions", help: "list recent messages that mention you", run: runMentions},
+		{name: "help", help: "list commands", run: runHelp},
+		{name: "quit", help: "exit chatatui", run: func(*Model, []string) tea.Cmd { return tea.Quit }},
+	}
+}
+
+func findSlashCommand(name string) (slashCommand, bool) {
+	i := slices.IndexFunc(slashCommands, func(c slashCommand) bool { return c.name == name })
+	if i < 0 {
+		return slashCommand{}, false
+	}
+	return slashCommands[i], true
+}
+
+// runSlash parses and runs a line starting with "/". Unknown commands are
+// reported locally rather than sent to the room.
+func (m *Model) runSlash(line string) tea.Cmd {
+	fields := strings.Fields(strings.TrimPrefix(line, "/"))
+	if len(fields) == 0 {
+		m.appendNotice("type /help for a list of commands")
+		return nil
+	}
+
+	cmd, ok := findSlashCommand(strings.ToLower(fields[0]))
+	if !ok {
+		m.appendNotice(fmt.Sprintf("unknown command /%s (try /help)", fields[0]))
+		return nil
+	}
+	return cmd.run(m, fields[1:])
+}
+
+// synopsis is the command as /help shows it, e.g. "/join <room>".
+func (c slashCommand) synopsis() string {
+	return strings.TrimSpace("/" + c.name + " " + c.usage)
+}
+
+func runJoin(m *Model, args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.appendNotice(slashUsage("join"))
+		return nil
+	}
+	i := slices.IndexFunc(m.rooms, func(r Room) bool { return strings.EqualFold(r.Name, args[0]) })
+	if i < 0 {
+		m.appendNotice("no room named " + args[0])
+		return nil
+	}
+	m.roomIndex = i
+	return m.openRoom(m.rooms[i].ID)
+}
+
+func runCreate(m *Model, args []string) tea.Cmd {
+	if len(args) == 0 || len(args) > 3 {
+		m.appendNotice(slashUsage("create"))
+		return nil
+	}
+	visibility, visibilitySet, encrypted := roomVisibilities[0], false, false
+	for _, arg := range args[1:] {
+		option := strings.ReplaceAll(strings.ToLower(arg), "-", "_")
+		switch {
+		case option == "encrypted" && !encrypted:
+			encrypted = true
+		case slices.Contains(roomVisibilities, option) && !visibilitySet:
+			visibility, visibilitySet = option, true
+		default:
+			m.appendNotice(slashUsage("create"))
+			return nil
+		}
+	}
+	return m.createRoom(args[0], visibility, encrypted)
+}
+
+func runLeave(m *Model, _ []string) tea.Cmd {
+	if m.connectedTo == "" {
+		m.appendNotice("you are not in a room")
+		return nil
+	}
+	return m.leaveRoom(m.connectedTo)
+}
+
+func runDM(m *Model, args []string) tea.Cmd {
+	if len(args) == 0 {
+		m.appendNotice(slashUsage("dm"))
+		return nil
+	}
+	return m.openDM(args)
+}
+
+func runMe(m *Model, args []string) tea.Cmd {
+	if len(args) == 0 {
+		m.appendNotice(slashUsage("me"))
+		return nil
+	}
+	if m.conn == nil || m.connectedTo == "" {
+		return nil
+	}
+	text := strings.Join(args, " ")
+	content, ok := m.seal(m.connectedTo, text)
+	if !ok {
+		return nil
+	}
+	// Actions go to the room, not the open thread.
+	m.closeThread()
+	m.appendOwnLine(hub.MessageTypeAction.String(), text)
+	m.updateViewportContent()
+	m.viewport.GotoBottom()
+	return sendActionCmd(m.conn, m.connectedTo, content)
+}
+
+func runUpload(m *Model, args []string) tea.Cmd {
+	if len(args) == 0 {
+		m.appendNotice(slashUsage("upload"))
+		return nil
+	}
+	if m.connectedTo == "" {
+		m.appendNotice("you are not in a room")
+		return nil
+	}
+	if m.isEncrypted(m.connectedTo) {
+		m.appendNotice("files cannot be sent to encrypted rooms")
+		return nil
+	}
+	return m.uploadFile(m.connectedTo, args[0], strings.Join(args[1:], " "))
+}
+
+func runDownload(m *Model, args []string) tea.Cmd {
+	if len(args) > 1 {
+		m.appendNotice(slashUsage("download"))
+		return nil
+	}
+	line, ok := m.attachmentTarget()
+	if !ok {
+		m.appendNotice("there is no file to download")
+		return nil
+	}
+	dir := downloadDir()
+	if len(args) == 1 {
+		dir = expandHome(args[0])
+	}
+	return m.downloadAttachment(line, dir, false)
+}
+
+func runOpen(m *Model, _ []string) tea.Cmd {
+	line, ok := m.attachmentTarget()
+	if !ok {
+		m.appendNotice("there is no file to open")
+		return nil
+	}
+	return m.downloadAttachment(line, openDir(), true)
+}
+
+func runNick(m *Model, args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.appendNotice(slashUsage("nick"))
+		return nil
+	}
+	return m.setNick(args[0])
+}
+
+func runTopic(m *Model, args []string) tea.Cmd {
+	if m.connectedTo == "" {
+		m.appendNotice("you are not in a room")
+		return nil
+	}
+	if len(args) == 0 {
+		topic := ""
+		if i := m.roomIndexOf(m.connectedTo); i >= 0 {
+			topic = m.rooms[i].Topic
+		}
+		if topic == "" {
+			m.appendNotice("no topic is set")
+		} else {
+			m.appendNotice("topic: " + topic)
+		}
+		return nil
+	}
+	return m.setTopic(m.connectedTo, strings.Join(args, " "))
+}
+
+func runRetention(m *Model, args []string) tea.Cmd {
+	if m.connectedTo == "" {
+		m.appendNotice("you are not in a room")
+		return nil
+	}
+	if len(args) == 0 {
+		retention := Retention{}
+		if i := m.roomIndexOf(m.connectedTo); i >= 0 && m.rooms[i].Retention != nil {
+			retention = *m.rooms[i].Retention
+		}
+		m.appendNotice("messages are kept " + retention.String())
+		return nil
+	}
+	if len(args) > 2 {
+		m.appendNotice(slashUsage("retention"))
+		return nil
+	}
+
+	var values [2]int
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			m.appendNotice(slashUsage("retention"))
+			return nil
+		}
+		values[i] = n
+	}
+	return m.setRetention(m.connectedTo, Retention{MaxAgeDays: values[0], MaxMessages: values[1]})
+}
+
+func runMentions(m *Model, _ []string) tea.Cmd {
+	return m.fetchMentions()
+}
+
+func runHelp(m *Model, _ []string) tea.Cmd {
+	for _, c := range slashCommands {
+		m.appendNotice(fmt.Sprintf("%s — %s", c.synopsis(), c.help))
+	}
+	return nil
+}
+
+func slashUsage(name string) string {
+	cmd, _ := findSlashCommand(name)
+	return "usage: " + cmd.synopsis()
+}
+
+// completeInput completes the word being typed after a "/": a command name
+// for the first word, and a room or user name for arguments of commands that
+// take them. Repeated tabs cycle through the candidates.
+func (m *Model) completeInput() {
+	value := m.input.Value()
+	if m.completions != nil && value == m.completedTo {
+		m.completionIndex = (m.completionIndex + 1) % len(m.completions)
+		m.applyCompletion()
+		return
+	}
+
+	base, word := value, ""
+	if i := strings.LastIndexByte(value, ' '); i >= 0 {
+		base, word = value[:i+1], value[i+1:]
+	} else {
+		base, word = "/", strings.TrimPrefix(value, "/")
+	}
+
+	var candidates []string
+	if base == "/" {
+		for _, c := range slashCommands {
+			candidates = append(candidates, c.name)
+		}
+	} else {
+		fields := strings.Fields(strings.TrimPrefix(base, "/"))
+		if len(fields) == 0 {
+			return
+		}
+		cmd, ok := findSlashCommand(strings.ToLower(fields[0]))
+		if !ok {
+			return
+		}
+		switch cmd.args {
+		case argRoom:
+			for _, r := range m.rooms {
+				if !r.isDM() {
+					candidates = append(candidates, r.Name)
+				}
+			}
+		case argUser:
+			for _, member := range m.members {
+				candidates = append(candidates, member.Name)
+			}
+		}
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) && !slices.Contains(matches, c) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		m.completions = nil
+		return
+	}
+
+	slices.Sort(matches)
+	m.completionBase = base
+	m.completions = matches
+	m.completionIndex = 0
+	m.applyCompletion()
+}
+
+func (m *Model) applyCompletion() {
+	value := m.completionBase + m.completions[m.completionIndex]
+	if len(m.completions) == 1 {
+		value += " "
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.completedTo = value
+}
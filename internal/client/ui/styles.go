@@ -13,6 +13,7 @@ const (
 	layoutInputPadding   = 2 // horizontal padding inside input
 	layoutViewportBorder = 2 // viewport border top + bottom
 	layoutTypingLine     = 1 // typing indicator row beneath viewport
+	maxInputRows         = 5 // rows the input grows to before it scrolls
 )
 
 // Color palette.
@@ -32,6 +33,9 @@ var (
 	styleMuted   = lipgloss.NewStyle().Foreground(colorMuted)
 	styleBold    = lipgloss.NewStyle().Bold(true)
 
+	styleSelected = lipgloss.NewStyle().Reverse(true)
+	styleMention  = lipgloss.NewStyle().Foreground(colorWarning).Bold(true)
+
 	styleTyping   = lipgloss.NewStyle().Foreground(colorMuted).Italic(true).PaddingLeft(1)
 	styleHelpKey  = lipgloss.NewStyle().Foreground(colorFocus).Bold(true)
 	styleHelpDesc = lipgloss.NewStyle().Foreground(colorMuted)
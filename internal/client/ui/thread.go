@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// threadView is the thread open in the message view. While it is open the
+// view holds the parent message followed by its replies, and the room's own
+// lines are set aside until the thread is closed.
+type threadView struct {
+	parentID     string
+	roomLines    []chatLine
+	roomSelected int
+	loading      bool
+}
+
+// openThread shows the thread started by the selected message and asks the
+// server for its replies.
+func (m *Model) openThread() tea.Cmd {
+	line, ok := m.selectedLine()
+	if !ok || m.thread != nil || m.conn == nil || !line.isMessage() || line.id == "" || line.deleted {
+		return nil
+	}
+
+	m.thread = &threadView{
+		parentID:     line.id,
+		roomLines:    m.messages,
+		roomSelected: m.selected,
+		loading:      true,
+	}
+	// The parent is shown in both lists, which updateLine changes together,
+	// so it must not share their reactions.
+	line.reactions = slices.Clone(line.reactions)
+	m.messages = []chatLine{line}
+	m.selected = 0
+	m.editingID = ""
+	m.updateViewportContent()
+	return sendThreadCmd(m.conn, m.connectedTo, line.id)
+}
+
+// closeThread puts the room's lines back in the message view.
+func (m *Model) closeThread() {
+	if m.thread == nil {
+		return
+	}
+	m.messages = m.thread.roomLines
+	m.selected = m.thread.roomSelected
+	m.thread = nil
+	m.editingID = ""
+	m.updateViewportContent()
+	m.ensureSelectedVisible()
+}
+
+// showThread fills the open thread with the replies the server sent, keeping
+// any that arrived live in the meantime.
+func (m *Model) showThread(msg threadMsg) {
+	if m.thread == nil || msg.parentID != m.thread.parentID {
+		return
+	}
+	m.thread.loading = false
+
+	lines := []chatLine{m.messages[0]}
+	for _, wire := range msg.replies {
+		lines = append(lines, lineFromWire(wire))
+	}
+	for _, line := range m.messages[1:] {
+		sent := slices.ContainsFunc(msg.replies, func(wire wireMessage) bool { return wire.ID == line.id })
+		if line.id == "" || !sent {
+			lines = append(lines, line)
+		}
+	}
+	m.messages = lines
+	m.updateViewportContent()
+	m.viewport.GotoBottom()
+}
+
+// roomLines returns the room's lines, wherever they are while a thread is open.
+func (m *Model) roomLines() *[]chatLine {
+	if m.thread != nil {
+		return &m.thread.roomLines
+	}
+	return &m.messages
+}
+
+// updateLine applies fn to the line with the given ID, both in the view and in
+// the room's lines set aside behind an open thread.
+func (m *Model) updateLine(id string, fn func(*chatLine)) {
+	if id == "" {
+		return
+	}
+	lists := []*[]chatLine{&m.messages}
+	if m.thread != nil {
+		lists = append(lists, &m.thread.roomLines)
+	}
+	for _, lines := range lists {
+		for i := range *lines {
+			if (*lines)[i].id == id {
+				fn(&(*lines)[i])
+			}
+		}
+	}
+}
+
+// countReply records a new reply on the message it answers.
+func (m *Model) countReply(wire wireMessage) {
+	m.updateLine(wire.ParentID, func(line *chatLine) {
+		line.replyCount++
+		line.lastReplyAt = wire.Timestamp
+	})
+}
@@ -2,14 +2,32 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/server/hub"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/coder/websocket"
 )
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if !m.ready {
+		return m.update(msg)
+	}
+	// Let the input lay out its text with room to grow, then shrink it to
+	// fit what is left once the message has been handled.
+	m.input.SetHeight(maxInputRows)
+	next, cmd := m.update(msg)
+	model := next.(Model)
+	model.layout()
+	return model, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -20,7 +38,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				delete(m.typingUsers, user)
 			}
 		}
-		return m, tea.Batch(m.fetchRooms(), m.tickCmd())
+		cmds := []tea.Cmd{m.fetchRooms(), m.fetchUnread(), m.tickCmd()}
+		if m.connectedTo != "" && m.state == connStateConnected {
+			cmds = append(cmds, m.fetchMembers(m.connectedTo), m.fetchKeys(m.connectedTo))
+		}
+		if !m.away && m.conn != nil && now.Sub(m.lastInput) >= idleAfter {
+			m.away = true
+			cmds = append(cmds, sendPresenceCmd(m.conn, hub.PresenceAway))
+		}
+		return m, tea.Batch(cmds...)
 
 	case roomsMsg:
 		// Preserve current room index if possible
@@ -29,9 +55,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			oldSelectedID = m.rooms[m.roomIndex].ID
 		}
 
-		sort.Slice(msg, func(i, j int) bool {
-			return msg[i].Name < msg[j].Name
-		})
+		sortRooms(msg)
 		m.rooms = msg
 
 		// Try to keep the same room selected
@@ -44,17 +68,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Only auto-connect on first load (when not connected)
-		if m.connectedTo == "" && len(m.rooms) > 0 {
-			return m, m.connectToRoom(m.rooms[0].ID)
+		// Only open a room on first load (when none is shown or pending)
+		if m.connectedTo == "" && m.pendingRoom == "" && len(m.rooms) > 0 {
+			return m, m.openRoom(m.rooms[0].ID)
 		}
 		return m, nil
 
+	case unreadMsg:
+		m.unread = msg
+		// Messages in the open room are read as they arrive; the server may
+		// not have caught up with the latest read event yet.
+		delete(m.unread, m.connectedTo)
+		return m, nil
+
+	case readFlushMsg:
+		m.readScheduled = false
+		id := m.newestMessageID()
+		if m.conn == nil || m.away || id == "" || id == m.lastReadID {
+			return m, nil
+		}
+		m.lastReadID = id
+		return m, sendReadCmd(m.conn, m.connectedTo, id)
+
+	case readReceiptMsg:
+		if m.isActive(msg.RoomID) {
+			m.readBy[msg.Author] = msg.ID
+			m.updateViewportContent()
+		}
+		return m, m.listenForMessages()
+
 	case roomCreatedMsg:
 		m.rooms = append(m.rooms, Room(msg))
-		sort.Slice(m.rooms, func(i, j int) bool {
-			return m.rooms[i].Name < m.rooms[j].Name
-		})
+		sortRooms(m.rooms)
 		for i, r := range m.rooms {
 			if r.ID == msg.ID {
 				m.roomIndex = i
@@ -63,48 +108,259 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.setFocus(focusRooms)
 		m.createRoomInput.Reset()
-		return m, m.connectToRoom(msg.ID)
+		m.createRoomVis = 0
+		m.createRoomEncrypted = false
+		return m, m.openRoom(msg.ID)
 
 	case connectedMsg:
-		m.conn = msg.conn
-		m.connectedTo = msg.roomID
+		if m.conn != nil {
+			_ = m.conn.Close(websocket.StatusNormalClosure, "reconnecting")
+		}
+		m.conn = msg
 		m.state = connStateConnected
 		m.reconnectDelay = time.Second
 		m.err = nil
-		m.messages = []string{}
-		m.updateViewportContent()
-		return m, m.listenForMessages()
+		m.away = false
+		m.subscribed = make(map[string]bool)
+
+		// Resubscribe to the room on screen after a reconnect.
+		roomID := m.pendingRoom
+		if roomID == "" {
+			roomID = m.connectedTo
+		}
+		if roomID == "" {
+			return m, m.listenForMessages()
+		}
+		return m, tea.Batch(m.listenForMessages(), m.openRoom(roomID))
+
+	case subscribedMsg:
+		roomID := string(msg)
+		m.subscribed[roomID] = true
+		if roomID != m.pendingRoom {
+			return m, m.listenForMessages()
+		}
+		// The server follows the subscription with the newest history page.
+		m.pendingRoom = ""
+		m.showRoom(roomID)
+		return m, tea.Batch(m.listenForMessages(), m.fetchMembers(roomID), m.fetchKeys(roomID))
+
+	case unsubscribedMsg:
+		delete(m.subscribed, msg.RoomID)
+		if msg.Content == "" || msg.RoomID != m.connectedTo {
+			return m, m.listenForMessages()
+		}
+		// The server only unsubscribes us unasked when we lose access.
+		m.appendNotice(msg.Content)
+		return m, tea.Batch(m.listenForMessages(), m.fetchRooms())
+
+	case membersMsg:
+		if msg.roomID == m.connectedTo {
+			m.members = msg.members
+		}
+		return m, nil
+
+	case keysMsg:
+		m.roomKeys[msg.roomID] = msg.keys
+		return m, nil
+
+	case presenceMsg:
+		if !m.isActive(msg.RoomID) {
+			return m, m.listenForMessages()
+		}
+		cmd := m.applyPresence(wireMessage(msg))
+		return m, tea.Batch(m.listenForMessages(), cmd)
 
 	case incomingMsg:
-		delete(m.typingUsers, msg.author)
-		m.messages = append(m.messages, msg.formatted)
+		m.decryptWire((*wireMessage)(&msg))
+		if msg.Type == hub.MessageTypeMention.String() {
+			m.applyMention(wireMessage(msg))
+			return m, m.listenForMessages()
+		}
+		if msg.Type == hub.MessageTypeTopic.String() {
+			if i := m.roomIndexOf(msg.RoomID); i >= 0 {
+				m.rooms[i].Topic = msg.Content
+			}
+		}
+		if msg.Type == hub.MessageTypeError.String() && msg.RoomID != "" && msg.RoomID == m.pendingRoom {
+			m.pendingRoom = ""
+			m.jumpTo = ""
+			m.appendNotice("could not join " + m.roomName(msg.RoomID))
+			return m, m.listenForMessages()
+		}
+		if !m.isActive(msg.RoomID) {
+			if lineFromWire(wireMessage(msg)).isMessage() {
+				m.unread[msg.RoomID]++
+			}
+			return m, m.listenForMessages()
+		}
+		delete(m.typingUsers, msg.Author)
+		appended := m.applyWire(wireMessage(msg))
 		m.updateViewportContent()
-		m.viewport.GotoBottom()
+		if appended && m.focus != focusMessages {
+			m.viewport.GotoBottom()
+		}
+		return m, tea.Batch(m.listenForMessages(), m.scheduleRead())
+
+	case historyMsg:
+		if !m.isActive(msg.roomID) {
+			return m, m.listenForMessages()
+		}
+		if m.thread != nil {
+			// Older history was asked for before the thread opened; it can
+			// be fetched again once the thread is closed.
+			m.historyLoading = false
+			return m, m.listenForMessages()
+		}
+		m.historyLoading = false
+		m.historyDone = !msg.hasMore
+		for i := range msg.messages {
+			m.decryptWire(&msg.messages[i])
+		}
+		m.prependHistory(msg.messages)
+		if m.historyInitial {
+			m.historyInitial = false
+			m.viewport.GotoBottom()
+			return m, tea.Batch(m.listenForMessages(), m.scheduleRead(), m.seekJump())
+		}
+		return m, tea.Batch(m.listenForMessages(), m.seekJump())
+
+	case threadMsg:
+		if m.isActive(msg.roomID) {
+			for i := range msg.replies {
+				m.decryptWire(&msg.replies[i])
+			}
+			m.showThread(msg)
+		}
 		return m, m.listenForMessages()
 
+	case searchMsg:
+		if msg.query != m.searchInput.Value() {
+			return m, nil // superseded by a newer search
+		}
+		for i := range msg.results {
+			m.decryptWire(&msg.results[i])
+		}
+		m.searchedFor = msg.query
+		m.searchResults = msg.results
+		m.searchIndex = 0
+		m.searchErr = msg.err
+		return m, nil
+
+	case mentionsMsg:
+		if len(msg) == 0 {
+			m.appendNotice("nobody has mentioned you")
+			return m, nil
+		}
+		// Oldest first, so the newest ends up nearest the input.
+		for i := len(msg) - 1; i >= 0; i-- {
+			mention := msg[i]
+			m.mentioned[mention.ID] = true
+			m.appendNotice(fmt.Sprintf("%s %s in %s: %s",
+				mention.Timestamp.Local().Format("Jan 2 15:04"), mention.Author, m.roomName(mention.RoomID), firstLine(mention.Content)))
+		}
+		return m, nil
+
 	case typingMsg:
-		if author := string(msg); author != "" {
-			m.typingUsers[author] = time.Now()
+		if m.isActive(msg.RoomID) && msg.Author != "" {
+			m.typingUsers[msg.Author] = time.Now()
 		}
 		return m, m.listenForMessages()
 
+	case dmOpenedMsg:
+		room := Room(msg)
+		if i := m.roomIndexOf(room.ID); i >= 0 {
+			m.rooms[i] = room
+		} else {
+			m.rooms = append(m.rooms, room)
+			sortRooms(m.rooms)
+		}
+		m.roomIndex = m.roomIndexOf(room.ID)
+		return m, m.openRoom(room.ID)
+
+	case commandErrMsg:
+		m.appendNotice(string(msg))
+		return m, nil
+
+	case attachmentSavedMsg:
+		if !msg.opened {
+			m.appendNotice("saved " + msg.path)
+		}
+		return m, nil
+
+	case roomLeftMsg:
+		roomID := string(msg)
+		delete(m.subscribed, roomID)
+		delete(m.unread, roomID)
+		delete(m.mentionedIn, roomID)
+		if i := m.roomIndexOf(roomID); i >= 0 {
+			m.rooms = slices.Delete(m.rooms, i, i+1)
+		}
+		m.roomIndex = 0
+		if roomID != m.connectedTo {
+			return m, m.fetchRooms()
+		}
+		m.connectedTo = ""
+		m.thread = nil
+		m.messages = []chatLine{}
+		m.members = nil
+		m.updateViewportContent()
+		if len(m.rooms) == 0 {
+			return m, m.fetchRooms()
+		}
+		return m, tea.Batch(m.fetchRooms(), m.openRoom(m.rooms[0].ID))
+
+	case nickChangedMsg:
+		m.appendNotice("you are now known as " + string(msg))
+		// Open connections keep the old name, so start a new one.
+		return m, m.connect()
+
+	case retentionMsg:
+		m.appendNotice("retention updated; messages past it will be deleted")
+		return m, m.fetchRooms()
+
 	case reconnectMsg:
-		return m, m.connectToRoom(string(msg))
+		return m, m.connect()
 
 	case errMsg:
-		if m.connectedTo != "" {
+		if m.connectedTo != "" || m.pendingRoom != "" {
 			delay := m.reconnectDelay
 			m.reconnectDelay = min(delay*2, 30*time.Second)
 			m.state = connStateConnecting
 			m.err = nil
 			return m, tea.Tick(delay, func(t time.Time) tea.Msg {
-				return reconnectMsg(m.connectedTo)
+				return reconnectMsg{}
 			})
 		}
 		m.err = msg
 		return m, nil
 
 	case tea.KeyMsg:
+		m.lastInput = time.Now()
+		if m.away && m.conn != nil {
+			// Handle the key as usual, but tell the room we are back first.
+			m.away = false
+			read := m.scheduleRead()
+			next, cmd := m.Update(msg)
+			return next, tea.Batch(sendPresenceCmd(m.conn, hub.PresenceBack), read, cmd)
+		}
+
+		if msg.String() == "ctrl+f" && m.focus != focusCreateRoom && m.focus != focusSearch {
+			m.setFocus(focusSearch)
+			return m, nil
+		}
+
+		if m.focus == focusSearch {
+			return m, m.handleSearchKey(msg)
+		}
+
+		if m.focus == focusMessages {
+			cmd, handled := m.handleMessagesKey(msg)
+			if handled {
+				return m, cmd
+			}
+			cmds = append(cmds, cmd)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -112,15 +368,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus != focusInput && m.focus != focusCreateRoom {
 				return m, tea.Quit
 			}
-		case "tab", "shift+tab":
+		case "tab":
+			if m.focus == focusCreateRoom {
+				m.createRoomVis = (m.createRoomVis + 1) % len(roomVisibilities)
+				return m, nil
+			}
+			if m.focus == focusInput && isSlashCommand(m.input.Value()) {
+				m.completeInput()
+				return m, nil
+			}
+			switch m.focus {
+			case focusRooms:
+				m.setFocus(focusInput)
+			case focusInput:
+				m.setFocus(focusMessages)
+			default:
+				m.setFocus(focusRooms)
+			}
+			return m, nil
+		case "shift+tab":
 			if m.focus != focusCreateRoom {
-				if m.focus == focusRooms {
+				switch m.focus {
+				case focusRooms:
+					m.setFocus(focusMessages)
+				case focusMessages:
 					m.setFocus(focusInput)
-				} else {
+				default:
 					m.setFocus(focusRooms)
 				}
 				return m, nil
 			}
+		case "ctrl+e":
+			if m.focus == focusCreateRoom {
+				m.createRoomEncrypted = !m.createRoomEncrypted
+				return m, nil
+			}
 		case "r":
 			if m.focus == focusRooms {
 				return m, m.fetchRooms()
@@ -134,6 +416,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus == focusCreateRoom {
 				m.setFocus(focusRooms)
 				m.createRoomInput.Reset()
+				m.createRoomVis = 0
+				m.createRoomEncrypted = false
+				return m, nil
+			}
+			if m.focus == focusInput && m.editingID != "" {
+				m.editingID = ""
+				m.input.Reset()
+				return m, nil
+			}
+			if (m.focus == focusInput || m.focus == focusMessages) && m.thread != nil {
+				m.closeThread()
 				return m, nil
 			}
 			if m.focus == focusInput || m.focus == focusMessages {
@@ -144,6 +437,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus == focusCreateRoom {
 				return m, nil
 			}
+			if m.focus == focusInput && (msg.String() == "[" || m.input.Value() != "") {
+				break // typed, or moving the cursor
+			}
 			if m.focus == focusInput || m.focus == focusMessages {
 				m.setFocus(focusRooms)
 			}
@@ -152,27 +448,65 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus == focusCreateRoom {
 				return m, nil
 			}
+			if m.focus == focusInput {
+				break
+			}
 			if m.focus == focusRooms {
 				m.setFocus(focusInput)
 			}
 			return m, nil
 		case "enter":
+			if m.focus == focusInput && hasOpenFence(m.input.Value()) {
+				m.input.InsertString("\n")
+				return m, nil
+			}
 			if m.focus == focusCreateRoom && m.createRoomInput.Value() != "" {
 				roomName := m.createRoomInput.Value()
-				return m, m.createRoom(roomName)
+				return m, m.createRoom(roomName, roomVisibilities[m.createRoomVis], m.createRoomEncrypted)
 			}
 			if m.focus == focusRooms && len(m.rooms) > 0 {
 				roomID := m.rooms[m.roomIndex].ID
 				m.setFocus(focusInput)
-				return m, m.connectToRoom(roomID)
+				return m, m.openRoom(roomID)
+			}
+			if m.focus == focusInput && m.input.Value() != "" && m.conn != nil && m.editingID != "" {
+				id, text := m.editingID, m.input.Value()
+				content, ok := m.seal(m.connectedTo, text)
+				if !ok {
+					return m, nil
+				}
+				m.editingID = ""
+				m.input.Reset()
+				m.updateLine(id, func(line *chatLine) {
+					line.content = text
+					line.edited = true
+				})
+				m.updateViewportContent()
+				return m, sendEditCmd(m.conn, m.connectedTo, id, content)
+			}
+			if m.focus == focusInput && isSlashCommand(m.input.Value()) {
+				line := m.input.Value()
+				m.input.Reset()
+				return m, m.runSlash(line)
 			}
 			if m.focus == focusInput && m.input.Value() != "" && m.conn != nil {
-				text := m.input.Value()
+				// A leading "//" sends a message that starts with a slash.
+				text := strings.TrimPrefix(m.input.Value(), "/")
+				content, ok := m.seal(m.connectedTo, text)
+				if !ok {
+					return m, nil
+				}
 				m.input.Reset()
-				m.messages = append(m.messages, fmt.Sprintf("%s You: %s", time.Now().Local().Format("15:04"), text))
+				if m.thread != nil {
+					m.appendOwnLine(hub.MessageTypeThreadReply.String(), text)
+					m.updateViewportContent()
+					m.viewport.GotoBottom()
+					return m, sendReplyCmd(m.conn, m.connectedTo, m.thread.parentID, content)
+				}
+				m.appendOwnLine(hub.MessageTypeChat.String(), text)
 				m.updateViewportContent()
 				m.viewport.GotoBottom()
-				return m, sendMessageCmd(m.conn, text)
+				return m, sendMessageCmd(m.conn, m.connectedTo, content)
 			}
 		case "up", "k":
 			if m.focus == focusRooms {
@@ -193,31 +527,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// box. Debounced to at most once every 2 seconds.
 			if m.shouldSendTyping() {
 				m.lastTypingSent = time.Now()
-				cmds = append(cmds, sendTypingCmd(m.conn))
+				cmds = append(cmds, sendTypingCmd(m.conn, m.connectedTo))
 			}
 		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-
-		// Account for outer border and help bar
-		innerWidth := m.width - layoutOuterChrome
-		innerHeight := m.height - layoutOuterChrome - layoutHelpBarHeight
-
-		mainWidth := innerWidth - layoutSidebarWidth - layoutSidebarDivider
-		viewportHeight := innerHeight - layoutHeaderHeight - layoutInputHeight - layoutTypingLine - layoutViewportBorder
-
-		if !m.ready {
-			m.viewport = viewport.New(mainWidth, viewportHeight)
-			m.viewport.SetContent("")
-			m.ready = true
-		} else {
-			m.viewport.Width = mainWidth
-			m.viewport.Height = viewportHeight
-		}
-
-		m.input.Width = mainWidth - layoutInputPadding
+		m.layout()
+		m.updateViewportContent()
 	}
 
 	if m.focus == focusInput {
@@ -241,6 +559,337 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// handleMessagesKey handles keys while the message viewport is focused:
+// moving the selection, editing, deleting or reacting to the selected message,
+// opening its thread, and fetching older history at the top. Keys it does not mark as handled fall
+// through to the viewport for scrolling.
+func (m *Model) handleMessagesKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.reacting && msg.String() != "ctrl+c" {
+		return m.pickReaction(msg.String()), true
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+			m.updateViewportContent()
+			m.ensureSelectedVisible()
+			return nil, true
+		}
+		return m.requestOlderHistory(), true
+	case "pgup", "home":
+		if m.viewport.AtTop() {
+			return m.requestOlderHistory(), false
+		}
+		return nil, false
+	case "down", "j":
+		if m.selected < len(m.messages)-1 {
+			m.selected++
+		}
+		m.updateViewportContent()
+		m.ensureSelectedVisible()
+		return nil, true
+	case "e":
+		line, ok := m.selectedLine()
+		if !ok || !line.canModify() || m.conn == nil {
+			return nil, true
+		}
+		m.editingID = line.id
+		m.input.SetValue(line.content)
+		m.input.CursorEnd()
+		m.setFocus(focusInput)
+		return nil, true
+	case "d":
+		line, ok := m.selectedLine()
+		if !ok || !line.canModify() || m.conn == nil {
+			return nil, true
+		}
+		m.updateLine(line.id, func(l *chatLine) { l.deleted = true })
+		m.updateViewportContent()
+		return sendDeleteCmd(m.conn, m.connectedTo, line.id), true
+	case "t", "enter":
+		return m.openThread(), true
+	case "+":
+		line, ok := m.selectedLine()
+		if ok && line.isMessage() && line.id != "" && !line.deleted {
+			m.reacting = true
+		}
+		return nil, true
+	case "o", "s":
+		line, ok := m.selectedLine()
+		if !ok || line.attachment == nil || line.id == "" || line.deleted {
+			return nil, true
+		}
+		if msg.String() == "o" {
+			return m.downloadAttachment(line, openDir(), true), true
+		}
+		return m.downloadAttachment(line, downloadDir(), false), true
+	}
+	return nil, false
+}
+
+// handleSearchKey handles keys while the search overlay is open. Enter runs
+// the typed query, or opens the highlighted hit once results for it are shown.
+func (m *Model) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		m.setFocus(focusInput)
+		return nil
+	case "up", "ctrl+p":
+		if m.searchIndex > 0 {
+			m.searchIndex--
+		}
+		return nil
+	case "down", "ctrl+n":
+		if m.searchIndex < len(m.searchResults)-1 {
+			m.searchIndex++
+		}
+		return nil
+	case "enter":
+		query := strings.TrimSpace(m.searchInput.Value())
+		if query == "" {
+			return nil
+		}
+		if query != m.searchedFor || len(m.searchResults) == 0 {
+			m.searchErr = ""
+			return m.searchMessages(query)
+		}
+		return m.jumpToHit(m.searchResults[m.searchIndex])
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+// jumpToHit closes the search overlay and shows hit in its room, paging back
+// through history until it is loaded.
+func (m *Model) jumpToHit(hit wireMessage) tea.Cmd {
+	m.closeThread()
+	// Replies are not in the room's history; show the message they answer.
+	m.jumpTo = hit.ID
+	if hit.ParentID != "" {
+		m.jumpTo = hit.ParentID
+	}
+	m.setFocus(focusMessages)
+	if i := m.roomIndexOf(hit.RoomID); i >= 0 {
+		m.roomIndex = i
+	}
+	if hit.RoomID == m.connectedTo {
+		return m.seekJump()
+	}
+	return m.openRoom(hit.RoomID)
+}
+
+// seekJump selects the pending search hit if it has been loaded, or asks for
+// the next older page of history if not.
+func (m *Model) seekJump() tea.Cmd {
+	if m.jumpTo == "" || m.historyInitial {
+		return nil
+	}
+	if i := m.lineIndex(m.jumpTo); i >= 0 {
+		m.jumpTo = ""
+		m.selected = i
+		m.updateViewportContent()
+		m.ensureSelectedVisible()
+		return nil
+	}
+	cmd := m.requestOlderHistory()
+	if cmd == nil && !m.historyLoading {
+		m.jumpTo = ""
+		m.appendNotice("that message is no longer available")
+	}
+	return cmd
+}
+
+// requestOlderHistory asks the server for the page of messages before the
+// oldest one currently loaded.
+func (m *Model) requestOlderHistory() tea.Cmd {
+	if m.conn == nil || m.thread != nil || m.historyLoading || m.historyDone {
+		return nil
+	}
+	oldest := m.oldestMessageID()
+	if oldest == "" {
+		return nil
+	}
+	m.historyLoading = true
+	return sendHistoryCmd(m.conn, m.connectedTo, oldest)
+}
+
+// openRoom switches the message view to roomID, subscribing to it first if
+// this connection is not already. The current room stays on screen until the
+// server accepts the subscription, so being refused leaves the user where they
+// were.
+func (m *Model) openRoom(roomID string) tea.Cmd {
+	if m.conn == nil {
+		m.pendingRoom = roomID
+		if m.state == connStateConnecting {
+			return nil
+		}
+		m.state = connStateConnecting
+		return m.connect()
+	}
+
+	if m.subscribed[roomID] {
+		m.pendingRoom = ""
+		m.showRoom(roomID)
+		return tea.Batch(sendHistoryCmd(m.conn, roomID, ""), m.fetchMembers(roomID), m.fetchKeys(roomID))
+	}
+
+	m.pendingRoom = roomID
+	return sendSubscribeCmd(m.conn, roomID)
+}
+
+// showRoom clears the message view for roomID and waits for its newest page
+// of history.
+func (m *Model) showRoom(roomID string) {
+	m.connectedTo = roomID
+	m.thread = nil
+	m.messages = []chatLine{}
+	m.selected = -1
+	m.editingID = ""
+	m.historyLoading = true
+	m.historyInitial = true
+	m.historyDone = false
+	m.members = nil
+	m.typingUsers = make(map[string]time.Time)
+	m.readBy = make(map[string]string)
+	m.lastReadID = ""
+	delete(m.unread, roomID)
+	delete(m.mentionedIn, roomID)
+	m.updateViewportContent()
+}
+
+// isActive reports whether a message for roomID belongs in the message view.
+// Messages without a room, such as connection errors, always do.
+func (m Model) isActive(roomID string) bool {
+	return roomID == "" || roomID == m.connectedTo
+}
+
+func (m Model) roomName(id string) string {
+	if i := m.roomIndexOf(id); i >= 0 {
+		return m.rooms[i].Name
+	}
+	return id
+}
+
+// ensureSelectedVisible scrolls the viewport so the selected message is on
+// screen.
+func (m *Model) ensureSelectedVisible() {
+	if m.selected < 0 || m.selected >= len(m.lineOffsets) {
+		return
+	}
+	top := m.lineOffsets[m.selected]
+	bottom := m.viewport.TotalLineCount()
+	if m.selected+1 < len(m.lineOffsets) {
+		bottom = m.lineOffsets[m.selected+1]
+	}
+
+	switch {
+	case top < m.viewport.YOffset:
+		m.viewport.SetYOffset(top)
+	case bottom > m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(bottom - m.viewport.Height)
+	}
+}
+
+// scheduleRead arranges for the newest message to be marked read shortly, so
+// that bursts of incoming messages are acknowledged once.
+func (m *Model) scheduleRead() tea.Cmd {
+	if m.readScheduled {
+		return nil
+	}
+	m.readScheduled = true
+	return tea.Tick(readFlushDelay, func(time.Time) tea.Msg {
+		return readFlushMsg{}
+	})
+}
+
+// isSlashCommand reports whether an input line is a command rather than a
+// message. Lines starting with "//" are messages that begin with a slash.
+func isSlashCommand(line string) bool {
+	return strings.HasPrefix(line, "/") && !strings.HasPrefix(line, "//")
+}
+
+// appendNotice shows a client-side error in the message view.
+func (m *Model) appendNotice(text string) {
+	m.messages = append(m.messages, chatLine{
+		kind:      hub.MessageTypeError.String(),
+		content:   text,
+		timestamp: time.Now(),
+	})
+	m.updateViewportContent()
+	m.viewport.GotoBottom()
+}
+
+// applyMention records a message that mentions the user. One in the open room
+// is highlighted where it appears; one elsewhere is announced and its room
+// marked in the sidebar.
+func (m *Model) applyMention(wire wireMessage) {
+	m.mentioned[wire.ID] = true
+	if m.isActive(wire.RoomID) {
+		m.updateViewportContent()
+		return
+	}
+	m.mentionedIn[wire.RoomID] = true
+	m.appendNotice(fmt.Sprintf("%s mentioned you in %s: %s", wire.Author, m.roomName(wire.RoomID), firstLine(wire.Content)))
+}
+
+// firstLine returns the first line of text, marking that there was more.
+func firstLine(text string) string {
+	if line, _, more := strings.Cut(text, "\n"); more {
+		return line + " …"
+	}
+	return text
+}
+
+func (m Model) roomIndexOf(id string) int {
+	for i, room := range m.rooms {
+		if room.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortRooms orders named rooms before direct messages, each alphabetically,
+// matching the sections of the sidebar.
+func sortRooms(rooms []Room) {
+	sort.SliceStable(rooms, func(i, j int) bool {
+		if rooms[i].isDM() != rooms[j].isDM() {
+			return !rooms[i].isDM()
+		}
+		return rooms[i].Name < rooms[j].Name
+	})
+}
+
+// applyPresence updates the member pane for a presence event. A join from
+// someone not listed means the member list is stale, so it is refetched.
+func (m *Model) applyPresence(wire wireMessage) tea.Cmd {
+	status := hub.StatusOnline
+	switch wire.Event {
+	case hub.PresenceLeave:
+		status = hub.StatusOffline
+	case hub.PresenceAway:
+		status = hub.StatusAway
+	}
+
+	for i := range m.members {
+		if m.members[i].UserID == wire.UserID {
+			m.members[i].Status = status
+			return nil
+		}
+	}
+
+	if wire.Event == hub.PresenceJoin && m.connectedTo != "" {
+		// A new member also needs their key included in what we send.
+		return tea.Batch(m.fetchMembers(m.connectedTo), m.fetchKeys(m.connectedTo))
+	}
+	return nil
+}
+
 func (m *Model) shouldSendTyping() bool {
 	if m.focus != focusInput {
 		return false
@@ -254,6 +903,43 @@ func (m *Model) shouldSendTyping() bool {
 	return m.input.Value() != ""
 }
 
+// layout sizes the viewport and input to the window. The input grows to fit
+// what has been typed, up to maxInputRows, taking rows from the viewport.
+func (m *Model) layout() {
+	// Account for outer border and help bar
+	innerWidth := m.width - layoutOuterChrome
+	innerHeight := m.height - layoutOuterChrome - layoutHelpBarHeight
+	mainWidth := innerWidth - layoutSidebarWidth - layoutSidebarDivider
+
+	m.input.SetWidth(mainWidth - layoutInputPadding)
+	rows := min(inputRows(m.input.Value(), m.input.Width()), maxInputRows)
+	m.input.SetHeight(rows)
+
+	viewportHeight := innerHeight - layoutHeaderHeight - layoutInputHeight - layoutTypingLine - layoutViewportBorder - (rows - 1)
+
+	if !m.ready {
+		m.viewport = viewport.New(mainWidth, viewportHeight)
+		m.viewport.SetContent("")
+		m.ready = true
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Width = mainWidth
+	m.viewport.Height = viewportHeight
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// inputRows estimates how many rows text takes up when wrapped to width.
+func inputRows(text string, width int) int {
+	rows := 0
+	for line := range strings.SplitSeq(text, "\n") {
+		rows += xansi.StringWidth(line)/max(width, 1) + 1
+	}
+	return rows
+}
+
 func (m *Model) setFocus(f focus) {
 	if m.focus == focusInput {
 		m.input.Blur()
@@ -261,11 +947,27 @@ func (m *Model) setFocus(f focus) {
 	if m.focus == focusCreateRoom {
 		m.createRoomInput.Blur()
 	}
+	if m.focus == focusSearch {
+		m.searchInput.Blur()
+	}
 	m.focus = f
+	m.reacting = false
 	if f == focusInput {
 		m.input.Focus()
 	}
 	if f == focusCreateRoom {
 		m.createRoomInput.Focus()
 	}
+	if f == focusSearch {
+		m.searchInput.Focus()
+	}
+	if f == focusMessages && (m.selected < 0 || m.selected >= len(m.messages)) {
+		m.selected = len(m.messages) - 1
+	}
+	if m.ready {
+		m.updateViewportContent()
+		if f == focusMessages {
+			m.ensureSelectedVisible()
+		}
+	}
 }
@@ -1,7 +1,6 @@
 package ui
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -36,6 +35,9 @@ func (m Model) View() string {
 	if m.focus == focusCreateRoom {
 		view = m.renderCreateRoomModal()
 	}
+	if m.focus == focusSearch {
+		view = m.renderSearchModal()
+	}
 
 	return view
 }
@@ -59,7 +61,25 @@ func (m Model) renderSidebar() string {
 
 	var roomList string
 	for i, room := range m.rooms {
+		if room.isDM() && (i == 0 || !m.rooms[i-1].isDM()) {
+			if i > 0 {
+				roomList += "\n"
+			}
+			roomList += styleBold.Render("Direct Messages") + "\n"
+		}
 		name := room.Name
+		if !room.isDM() {
+			name += visibilityMarker(room.Visibility)
+		}
+		if room.Encrypted {
+			name += styleMuted.Render(" [e]")
+		}
+		if n := m.unread[room.ID]; n > 0 && room.ID != m.connectedTo {
+			name += styleWarning.Render(fmt.Sprintf(" (%d)", n))
+		}
+		if m.mentionedIn[room.ID] && room.ID != m.connectedTo {
+			name += styleMention.Render(" @")
+		}
 		if i == m.roomIndex {
 			name = "> " + name
 		} else {
@@ -79,23 +99,69 @@ func (m Model) renderSidebar() string {
 	}
 
 	content := header + "\n\n" + roomList
+	if m.connectedTo != "" {
+		content += "\n\n" + m.renderMembers()
+	}
 
 	return style.Render(content)
 }
 
+// renderMembers lists the current room's members, online first, with a dot
+// showing whether each is online, away or offline.
+func (m Model) renderMembers() string {
+	header := styleBold.Render("Members")
+	if len(m.members) == 0 {
+		return header + "\n" + styleMuted.Render("(loading)")
+	}
+
+	members := make([]Member, len(m.members))
+	copy(members, m.members)
+	sort.SliceStable(members, func(i, j int) bool {
+		return statusRank(members[i].Status) < statusRank(members[j].Status)
+	})
+
+	var list strings.Builder
+	for _, member := range members {
+		var dot string
+		switch member.Status {
+		case hub.StatusOnline:
+			dot = styleStateConnected.Render("●")
+		case hub.StatusAway:
+			dot = styleStateConnecting.Render("●")
+		default:
+			dot = styleMuted.Render("○")
+		}
+		list.WriteString(dot + " " + member.Name + "\n")
+	}
+
+	return header + "\n" + list.String()
+}
+
+func statusRank(status string) int {
+	switch status {
+	case hub.StatusOnline:
+		return 0
+	case hub.StatusAway:
+		return 1
+	default:
+		return 2
+	}
+}
+
 func (m Model) renderMain() string {
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
 		Padding(0, 1)
 
 	title := "chatatui"
-	if m.connectedTo != "" {
-		for _, room := range m.rooms {
-			if room.ID == m.connectedTo {
-				title = room.Name
-				break
-			}
-		}
+	topic := ""
+	encrypted := false
+	var retention *Retention
+	if i := m.roomIndexOf(m.connectedTo); i >= 0 {
+		title = m.rooms[i].Name
+		topic = m.rooms[i].Topic
+		encrypted = m.rooms[i].Encrypted
+		retention = m.rooms[i].Retention
 	}
 
 	var stateIndicator string
@@ -107,7 +173,22 @@ func (m Model) renderMain() string {
 	case connStateDisconnected:
 		stateIndicator = styleStateDisconnected.Render(" ●")
 	}
-	header := headerStyle.Render(title + stateIndicator)
+	text := title + stateIndicator
+	if encrypted {
+		text += styleMuted.Render(" (encrypted)")
+	}
+	if retention != nil {
+		text += styleMuted.Render(" (kept " + retention.String() + ")")
+	}
+	if m.thread != nil {
+		text += styleMuted.Render(" › thread")
+		if m.thread.loading {
+			text += styleMuted.Render(" (loading)")
+		}
+	} else if topic != "" {
+		text += styleMuted.Render(" — " + topic)
+	}
+	header := headerStyle.MaxWidth(m.viewport.Width).Render(text)
 
 	viewportStyle := lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
@@ -134,12 +215,41 @@ func (m Model) renderMain() string {
 
 func (m *Model) updateViewportContent() {
 	var content strings.Builder
-	for _, msg := range m.messages {
-		content.WriteString(msg + "\n")
+	m.lineOffsets = m.lineOffsets[:0]
+	offset := 0
+	for i, line := range m.messages {
+		line.mentioned = m.mentioned[line.id]
+		rendered := renderLine(line, m.markdown, m.viewport.Width)
+		if m.focus == focusMessages && i == m.selected {
+			rendered = styleSelected.Render(rendered)
+		}
+		m.lineOffsets = append(m.lineOffsets, offset)
+		offset += strings.Count(rendered, "\n") + 1
+		content.WriteString(rendered + "\n")
+	}
+	if seen := m.seenBy(); seen != "" {
+		content.WriteString(styleMuted.Italic(true).Render("seen by "+seen) + "\n")
 	}
 	m.viewport.SetContent(content.String())
 }
 
+// seenBy lists who has read up to the newest message, for the read receipt
+// shown beneath it.
+func (m Model) seenBy() string {
+	newest := m.newestMessageID()
+	if newest == "" {
+		return ""
+	}
+	var readers []string
+	for reader, id := range m.readBy {
+		if id == newest {
+			readers = append(readers, reader)
+		}
+	}
+	sort.Strings(readers)
+	return strings.Join(readers, ", ")
+}
+
 func (m Model) renderCreateRoomModal() string {
 	modalStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -154,7 +264,10 @@ func (m Model) renderCreateRoomModal() string {
 		"",
 		m.createRoomInput.View(),
 		"",
-		styleModalHelp.Render("Press Enter to create, Esc to cancel"),
+		"Visibility: "+styleBold.Render(strings.ReplaceAll(roomVisibilities[m.createRoomVis], "_", "-")),
+		"Encrypted:  "+styleBold.Render(yesNo(m.createRoomEncrypted)),
+		"",
+		styleModalHelp.Render("Enter to create, Tab to change visibility, Ctrl+E to toggle encryption, Esc to cancel"),
 	)
 
 	modal := modalStyle.Render(content)
@@ -168,6 +281,82 @@ func (m Model) renderCreateRoomModal() string {
 	)
 }
 
+// searchResultsShown caps how many hits the search overlay lists at once.
+const searchResultsShown = 10
+
+func (m Model) renderSearchModal() string {
+	width := min(80, m.width-4)
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorFocus).
+		Padding(1, 2).
+		Width(width).
+		Background(colorModalBg)
+
+	var results strings.Builder
+	switch {
+	case m.searchErr != "":
+		results.WriteString(styleError.Render(m.searchErr))
+	case m.searchedFor != "" && len(m.searchResults) == 0:
+		results.WriteString(styleMuted.Render("No messages found"))
+	}
+
+	// Keep the highlighted hit within the window of results shown.
+	start := max(0, m.searchIndex-searchResultsShown+1)
+	end := min(len(m.searchResults), start+searchResultsShown)
+	for i := start; i < end; i++ {
+		hit := m.searchResults[i]
+		line := fmt.Sprintf("%s %s %s: %s",
+			hit.Timestamp.Local().Format("Jan 2 15:04"),
+			styleMuted.Render(m.roomName(hit.RoomID)),
+			hit.Author,
+			hit.Content)
+		line = lipgloss.NewStyle().MaxWidth(width - 4).Render(line)
+		if i == m.searchIndex {
+			line = styleSelected.Render(line)
+		}
+		results.WriteString(line + "\n")
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		styleModalTitle.Render("Search Messages"),
+		"",
+		m.searchInput.View(),
+		"",
+		strings.TrimSuffix(results.String(), "\n"),
+		"",
+		styleModalHelp.Render("Enter to search or open, ↑/↓ to choose, Esc to close"),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+	)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// visibilityMarker flags rooms that are not open to everyone in the sidebar.
+func visibilityMarker(visibility string) string {
+	switch visibility {
+	case "private":
+		return styleMuted.Render(" [p]")
+	case "invite_only":
+		return styleMuted.Render(" [i]")
+	default:
+		return ""
+	}
+}
+
 func (m Model) typingLine() string {
 	var typers []string
 	now := time.Now()
@@ -211,6 +400,9 @@ func (m Model) charCountIndicator() string {
 }
 
 func (m Model) renderStatusLine() string {
+	if m.reacting {
+		return reactionPicker()
+	}
 	typingText := styleTyping.Render(m.typingLine())
 	counter := m.charCountIndicator()
 	if counter == "" {
@@ -223,15 +415,39 @@ func (m Model) renderStatusLine() string {
 	return typingText + strings.Repeat(" ", gap) + counter
 }
 
+type helpKey struct{ key, desc string }
+
 func (m Model) renderHelp() string {
-	keys := []struct{ key, desc string }{
+	keys := []helpKey{
 		{"tab/←/→", "switch panel"},
 		{"j/k", "navigate"},
 		{"n", "new room"},
 		{"r", "refresh"},
+		{"ctrl+f", "search"},
 		{"enter", "join/send"},
-		{"q/ctrl+c", "quit"},
 	}
+	if m.focus == focusInput {
+		keys = append(keys, helpKey{"alt+enter", "new line"})
+	}
+	if m.focus == focusMessages {
+		keys = append(keys,
+			helpKey{"e", "edit"},
+			helpKey{"d", "delete"},
+		)
+		if m.thread == nil {
+			keys = append(keys, helpKey{"t", "thread"})
+		}
+		keys = append(keys, helpKey{"+", "react"})
+		if line, ok := m.selectedLine(); ok && line.attachment != nil {
+			keys = append(keys, helpKey{"o", "open file"}, helpKey{"s", "save file"})
+		}
+	}
+	if m.editingID != "" {
+		keys = append(keys, helpKey{"esc", "cancel edit"})
+	} else if m.thread != nil {
+		keys = append(keys, helpKey{"esc", "close thread"})
+	}
+	keys = append(keys, helpKey{"q/ctrl+c", "quit"})
 
 	var items []string
 	for _, k := range keys {
@@ -254,18 +470,3 @@ func (m Model) renderHelp() string {
 func (m Model) sidebarWidth() int {
 	return layoutSidebarWidth
 }
-
-func formatWireMessage(data []byte) string {
-	var wire wireMessage
-	if err := json.Unmarshal(data, &wire); err != nil {
-		return string(data)
-	}
-
-	ts := wire.Timestamp.Local().Format("15:04")
-
-	if wire.Type == hub.MessageTypeError.String() {
-		return styleError.Italic(true).Render(fmt.Sprintf("%s ! %s", ts, wire.Content))
-	}
-
-	return fmt.Sprintf("%s %s: %s", ts, wire.Author, wire.Content)
-}
@@ -0,0 +1,248 @@
+// Package e2e encrypts messages for end-to-end encrypted rooms.
+//
+// Every user has an X25519 key pair whose public half is published in the
+// server's key directory. A message is encrypted once with a fresh AES-256-GCM
+// content key, and that key is wrapped for each recipient with a key derived
+// by HKDF-SHA256 from the X25519 secret shared between sender and recipient.
+// The result is a JSON envelope, which is all the server ever sees. Since the
+// wrapping key depends on the sender's private key, a recipient who knows the
+// sender's public key also knows who sealed the message.
+package e2e
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Version identifies the envelope format and algorithms.
+const Version = 1
+
+var (
+	ErrMalformed    = errors.New("malformed encrypted message")
+	ErrNotRecipient = errors.New("message was not encrypted for this key")
+	ErrDecrypt      = errors.New("message could not be decrypted")
+)
+
+// Envelope is an encrypted message as stored and relayed by the server.
+type Envelope struct {
+	Version int `json:"v"`
+	// SenderKey is the sender's public key.
+	SenderKey  string `json:"sender_key"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+	// Keys maps each recipient's public key to the content key wrapped for
+	// them, as nonce followed by ciphertext.
+	Keys map[string]string `json:"keys"`
+}
+
+// Key is a user's X25519 key pair.
+type Key struct {
+	private *ecdh.PrivateKey
+}
+
+func GenerateKey() (*Key, error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{private: private}, nil
+}
+
+// PublicKey returns the base64 public key to publish in the key directory.
+func (k *Key) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.private.PublicKey().Bytes())
+}
+
+// ParsePublicKey decodes a base64 public key, checking that it is a valid
+// X25519 key.
+func ParsePublicKey(s string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext for recipients, keyed by their base64 public keys,
+// and returns the JSON envelope. The sender is always added as a recipient so
+// that they can read their own messages back.
+func Seal(plaintext []byte, sender *Key, recipients []string) ([]byte, error) {
+	contentKey := make([]byte, 32)
+	if _, err := rand.Read(contentKey); err != nil {
+		return nil, err
+	}
+
+	senderKey := sender.PublicKey()
+	nonce, ciphertext, err := encrypt(contentKey, plaintext, []byte(senderKey))
+	if err != nil {
+		return nil, err
+	}
+
+	env := Envelope{
+		Version:    Version,
+		SenderKey:  senderKey,
+		Nonce:      encode(nonce),
+		Ciphertext: encode(ciphertext),
+		Keys:       make(map[string]string, len(recipients)+1),
+	}
+	for _, recipient := range append(slices.Clip(recipients), senderKey) {
+		if _, ok := env.Keys[recipient]; ok {
+			continue
+		}
+		peer, err := ParsePublicKey(recipient)
+		if err != nil {
+			return nil, err
+		}
+		wrapKey, err := sender.wrapKey(peer, senderKey, recipient)
+		if err != nil {
+			return nil, err
+		}
+		wrapNonce, wrapped, err := encrypt(wrapKey, contentKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		env.Keys[recipient] = encode(append(wrapNonce, wrapped...))
+	}
+	return json.Marshal(env)
+}
+
+// Open decrypts an envelope sealed for k, returning the plaintext and the
+// sender's public key. Callers should compare the sender's key against the
+// key directory before trusting who sent the message.
+func Open(data []byte, k *Key) (plaintext []byte, senderKey string, err error) {
+	env, err := parse(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	recipient := k.PublicKey()
+	wrappedKey, ok := env.Keys[recipient]
+	if !ok {
+		return nil, "", ErrNotRecipient
+	}
+	sender, err := ParsePublicKey(env.SenderKey)
+	if err != nil {
+		return nil, "", ErrMalformed
+	}
+	wrapped, err := decode(wrappedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	nonce, err := decode(env.Nonce)
+	if err != nil {
+		return nil, "", err
+	}
+	ciphertext, err := decode(env.Ciphertext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wrapKey, err := k.wrapKey(sender, env.SenderKey, recipient)
+	if err != nil {
+		return nil, "", err
+	}
+	contentKey, err := decrypt(wrapKey, wrapped, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err = decrypt(contentKey, append(nonce, ciphertext...), []byte(env.SenderKey))
+	if err != nil {
+		return nil, "", err
+	}
+	return plaintext, env.SenderKey, nil
+}
+
+// IsEnvelope reports whether data looks like an envelope of a supported
+// version. It does not check that the envelope can be decrypted.
+func IsEnvelope(data []byte) bool {
+	_, err := parse(data)
+	return err == nil
+}
+
+func parse(data []byte) (*Envelope, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil, ErrMalformed
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, ErrMalformed
+	}
+	if env.Version != Version || env.SenderKey == "" || env.Nonce == "" || env.Ciphertext == "" || len(env.Keys) == 0 {
+		return nil, ErrMalformed
+	}
+	return &env, nil
+}
+
+// wrapKey derives the key that wraps content keys sent from senderKey to
+// recipientKey. Both sides compute it: the sender from their private key and
+// the recipient's public key, the recipient the other way around.
+func (k *Key) wrapKey(peer *ecdh.PublicKey, senderKey, recipientKey string) ([]byte, error) {
+	shared, err := k.private.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	info := fmt.Sprintf("chatatui e2e v%d wrap %s %s", Version, senderKey, recipientKey)
+	return hkdf.Key(sha256.New, shared, nil, info, 32)
+}
+
+func encrypt(key, plaintext, additionalData []byte) (nonce, ciphertext []byte, err error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// decrypt opens data laid out as nonce followed by ciphertext.
+func decrypt(key, data, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return b, nil
+}
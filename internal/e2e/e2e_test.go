@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustKey(t *testing.T) *Key {
+	t.Helper()
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return k
+}
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	alice, bob, eve := mustKey(t), mustKey(t), mustKey(t)
+
+	sealed, err := Seal([]byte("meet at noon"), alice, []string{bob.PublicKey()})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsEnvelope(sealed) {
+		t.Fatalf("sealed message is not recognised as an envelope: %s", sealed)
+	}
+
+	for name, k := range map[string]*Key{"recipient": bob, "sender": alice} {
+		plaintext, sender, err := Open(sealed, k)
+		if err != nil {
+			t.Fatalf("%s Open: %v", name, err)
+		}
+		if string(plaintext) != "meet at noon" {
+			t.Errorf("%s got %q", name, plaintext)
+		}
+		if sender != alice.PublicKey() {
+			t.Errorf("%s got sender %s, want alice", name, sender)
+		}
+	}
+
+	if _, _, err := Open(sealed, eve); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("outsider Open = %v, want ErrNotRecipient", err)
+	}
+}
+
+func TestOpen_RejectsTampering(t *testing.T) {
+	alice, bob, mallory := mustKey(t), mustKey(t), mustKey(t)
+
+	sealed, err := Seal([]byte("hello"), alice, []string{bob.PublicKey()})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	// Claiming another sender changes the wrapping key, so the content key
+	// cannot be unwrapped.
+	var env Envelope
+	_ = json.Unmarshal(sealed, &env)
+	env.SenderKey = mallory.PublicKey()
+	forged, _ := json.Marshal(env)
+	if _, _, err := Open(forged, bob); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("forged sender Open = %v, want ErrDecrypt", err)
+	}
+
+	_ = json.Unmarshal(sealed, &env)
+	env.Ciphertext = env.Nonce
+	forged, _ = json.Marshal(env)
+	if _, _, err := Open(forged, bob); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("altered ciphertext Open = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestIsEnvelope(t *testing.T) {
+	for _, data := range []string{"hello", "{}", `{"v":2,"sender_key":"a","nonce":"b","ciphertext":"c","keys":{"d":"e"}}`, `{"v":1}`} {
+		if IsEnvelope([]byte(data)) {
+			t.Errorf("IsEnvelope(%q) = true", data)
+		}
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeyFileName)
+
+	created, isNew, err := LoadOrCreateKey(path)
+	if err != nil || !isNew {
+		t.Fatalf("first LoadOrCreateKey = %v, %v", isNew, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key file permissions = %o, want 600", perm)
+	}
+
+	loaded, isNew, err := LoadOrCreateKey(path)
+	if err != nil || isNew {
+		t.Fatalf("second LoadOrCreateKey = %v, %v", isNew, err)
+	}
+	if loaded.PublicKey() != created.PublicKey() {
+		t.Error("reloaded key differs from the one created")
+	}
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// KeyFileName is the file, next to the client's config file, that holds the
+// user's private key.
+const KeyFileName = ".chatatui.key"
+
+// LoadOrCreateKey reads the private key at path, generating and saving a new
+// one readable only by the user if there is none. It reports whether the key
+// was created.
+func LoadOrCreateKey(path string) (*Key, bool, error) {
+	key, err := LoadKey(path)
+	if err == nil {
+		return key, false, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	}
+
+	key, err = GenerateKey()
+	if err != nil {
+		return nil, false, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key.private.Bytes()) + "\n"
+	// O_EXCL so that two clients starting at once cannot overwrite each
+	// other's key.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		key, err := LoadKey(path)
+		return key, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := f.WriteString(encoded); err != nil {
+		_ = f.Close()
+		return nil, false, err
+	}
+	return key, true, f.Close()
+}
+
+// LoadKey reads the private key at path.
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading key %s: %w", path, err)
+	}
+	private, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("reading key %s: %w", path, err)
+	}
+	return &Key{private: private}, nil
+}
@@ -3,4 +3,14 @@ package limits
 const (
 	MaxMessageLength  = 300
 	MaxRoomNameLength = 15
+	MaxUserNameLength = 15
+	MaxTopicLength    = 120
+	// MaxReactionLength is in runes, enough for emoji built from several
+	// code points.
+	MaxReactionLength = 16
+	// MaxEncryptedMessageLength bounds an end-to-end encrypted envelope,
+	// which grows with every recipient it carries a key for.
+	MaxEncryptedMessageLength = 16 << 10
+	// MaxAttachmentNameLength is in bytes; longer file names are cut short.
+	MaxAttachmentNameLength = 255
 )
@@ -34,12 +34,31 @@ func APIKeyAuth(users UserLookup) func(http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), userContextKey, user)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
 		})
 	}
 }
 
+// RequireScope refuses requests from bots whose API key was not granted
+// scope. Human users are never refused. It must run after APIKeyAuth.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := UserFromContext(r.Context()); user == nil || !user.HasScope(scope) {
+				writeJSONError(w, http.StatusForbidden, "INSUFFICIENT_SCOPE", "api key lacks the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithUser returns ctx carrying user as the caller, for requests
+// authenticated some other way than with an API key.
+func WithUser(ctx context.Context, user *repository.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
 func UserFromContext(ctx context.Context) *repository.User {
 	user, _ := ctx.Value(userContextKey).(*repository.User)
 	return user
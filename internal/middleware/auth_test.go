@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *repository.User
+		wantStatus int
+	}{
+		{
+			name:       "human users may do everything",
+			user:       &repository.User{Kind: repository.UserKindHuman},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bot with the scope",
+			user:       &repository.User{Kind: repository.UserKindBot, Scopes: "rooms:read messages:write"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bot without the scope",
+			user:       &repository.User{Kind: repository.UserKindBot, Scopes: "rooms:read"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no user",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.user != nil {
+				req = req.WithContext(WithUser(req.Context(), tt.user))
+			}
+			w := httptest.NewRecorder()
+
+			RequireScope(repository.ScopeMessagesWrite)(okHandler()).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
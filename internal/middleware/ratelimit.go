@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -22,7 +23,11 @@ type RateLimitCache interface {
 }
 
 type RateLimiter struct {
-	cache      RateLimitCache
+	cache RateLimitCache
+	// fallback counts requests that cache cannot, such as while Redis is
+	// unreachable, so that an outage limits each node on its own rather than
+	// failing every request.
+	fallback   RateLimitCache
 	maxReqs    int64
 	windowSecs int
 }
@@ -49,12 +54,29 @@ func NewRateLimiter(redisURL string, maxReqs, windowSecs int) (*RateLimiter, err
 
 	return &RateLimiter{
 		cache:      c,
+		fallback:   NewMemoryRateLimitCache(time.Duration(windowSecs) * time.Second),
 		maxReqs:    int64(maxReqs),
 		windowSecs: windowSecs,
 	}, nil
 }
 
+// NewMemoryRateLimiter returns a RateLimiter that keeps its counts in process.
+// Limits are per node, so it suits single-node deployments and tests.
+func NewMemoryRateLimiter(maxReqs, windowSecs int) *RateLimiter {
+	return &RateLimiter{
+		cache:      NewMemoryRateLimitCache(time.Duration(windowSecs) * time.Second),
+		maxReqs:    int64(maxReqs),
+		windowSecs: windowSecs,
+	}
+}
+
+// Middleware rejects requests from users over their limit. A nil RateLimiter
+// lets every request through. Requests the cache cannot count are counted by
+// the fallback, if there is one.
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if rl == nil {
+		return next
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := UserFromContext(r.Context())
 		if user == nil {
@@ -64,7 +86,11 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 
 		key := user.ID.String()
 
-		allowed, err := rl.isAllowed(r.Context(), key)
+		allowed, err := rl.isAllowed(r.Context(), rl.cache, key)
+		if err != nil && rl.fallback != nil {
+			slog.Warn("rate limit cache unavailable, counting in memory", "error", err)
+			allowed, err = rl.isAllowed(r.Context(), rl.fallback, key)
+		}
 		if err != nil {
 			writeJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "rate limit check failed")
 			return
@@ -80,14 +106,14 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-func (rl *RateLimiter) isAllowed(ctx context.Context, key string) (bool, error) {
-	count, err := rl.cache.Incr(ctx, key)
+func (rl *RateLimiter) isAllowed(ctx context.Context, cache RateLimitCache, key string) (bool, error) {
+	count, err := cache.Incr(ctx, key)
 	if err != nil {
 		return false, err
 	}
 
 	if count == 1 {
-		_, err := rl.cache.Expire(ctx, key, time.Duration(rl.windowSecs)*time.Second)
+		_, err := cache.Expire(ctx, key, time.Duration(rl.windowSecs)*time.Second)
 		if err != nil {
 			return false, err
 		}
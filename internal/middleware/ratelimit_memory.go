@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRateLimitCache is an in-process RateLimitCache for single-node
+// deployments and tests. It counts hits over a sliding window, estimated from
+// the counts of the current and previous fixed windows, so a burst at the end
+// of one window still counts against the start of the next.
+type MemoryRateLimitCache struct {
+	window    time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+}
+
+type windowCounter struct {
+	start    time.Time // start of the current window
+	current  int64
+	previous int64
+}
+
+func NewMemoryRateLimitCache(window time.Duration) *MemoryRateLimitCache {
+	return &MemoryRateLimitCache{
+		window:   max(window, time.Second),
+		now:      time.Now,
+		counters: make(map[string]*windowCounter),
+	}
+}
+
+// Incr records a hit for key and returns the estimated number of hits within
+// the last window, including this one.
+func (c *MemoryRateLimitCache) Incr(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	counter, ok := c.counters[key]
+	if !ok {
+		counter = &windowCounter{start: now}
+		c.counters[key] = counter
+	}
+	c.advance(counter, now)
+	counter.current++
+
+	// Weight the previous window by how much of it the sliding window still
+	// overlaps.
+	overlap := 1 - float64(now.Sub(counter.start))/float64(c.window)
+	return counter.current + int64(float64(counter.previous)*overlap), nil
+}
+
+// Expire is a no-op: the window is fixed when the cache is created and
+// counters age out on their own. It reports whether key is being counted.
+func (c *MemoryRateLimitCache) Expire(_ context.Context, key string, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.counters[key]
+	return ok, nil
+}
+
+// advance moves the counter's current window up to now.
+func (c *MemoryRateLimitCache) advance(counter *windowCounter, now time.Time) {
+	elapsed := now.Sub(counter.start) / c.window
+	switch {
+	case elapsed == 0:
+		return
+	case elapsed == 1:
+		counter.previous = counter.current
+	default:
+		counter.previous = 0
+	}
+	counter.current = 0
+	counter.start = counter.start.Add(elapsed * c.window)
+}
+
+// sweep drops counters with no hits in the last two windows.
+func (c *MemoryRateLimitCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.window {
+		return
+	}
+	c.lastSweep = now
+
+	for key, counter := range c.counters {
+		if now.Sub(counter.start) >= 2*c.window {
+			delete(c.counters, key)
+		}
+	}
+}
@@ -118,6 +118,25 @@ func TestRateLimiter_IncrError_Returns500(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestRateLimiter_IncrError_FallsBackToMemory(t *testing.T) {
+	userID := uuid.New()
+	cache := mocks.NewMockRateLimitCache(t)
+	cache.EXPECT().Incr(mock.Anything, userID.String()).Return(int64(0), errors.New("redis unavailable"))
+
+	rl := newRateLimiter(cache, 2, 60)
+	rl.fallback = NewMemoryRateLimitCache(60 * time.Second)
+	handler := rl.Middleware(okHandler())
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, requestWithUser(userID))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, requestWithUser(userID))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
 func TestRateLimiter_ExpireError_Returns500(t *testing.T) {
 	userID := uuid.New()
 	cache := mocks.NewMockRateLimitCache(t)
@@ -131,3 +150,58 @@ func TestRateLimiter_ExpireError_Returns500(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestRateLimiter_Nil_PassesThrough(t *testing.T) {
+	var rl *RateLimiter
+
+	w := httptest.NewRecorder()
+	rl.Middleware(okHandler()).ServeHTTP(w, requestWithUser(uuid.New()))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMemoryRateLimiter_LimitsPerUser(t *testing.T) {
+	rl := NewMemoryRateLimiter(2, 60)
+	alice, bob := uuid.New(), uuid.New()
+
+	codes := make([]int, 0, 3)
+	for range 3 {
+		w := httptest.NewRecorder()
+		rl.Middleware(okHandler()).ServeHTTP(w, requestWithUser(alice))
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+
+	w := httptest.NewRecorder()
+	rl.Middleware(okHandler()).ServeHTTP(w, requestWithUser(bob))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMemoryRateLimitCache_SlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemoryRateLimitCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	for range 10 {
+		_, err := cache.Incr(ctx, "k")
+		require.NoError(t, err)
+	}
+
+	// A quarter of the way into the next window, three quarters of the
+	// previous window's hits still count.
+	now = now.Add(75 * time.Second)
+	count, err := cache.Incr(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1+7), count)
+
+	// Two windows on, nothing from the first window remains.
+	now = now.Add(2 * time.Minute)
+	count, err = cache.Incr(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	exists, err := cache.Expire(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
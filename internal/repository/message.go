@@ -1,17 +1,113 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Message kinds. Actions are third-person messages sent with /me, and files
+// carry an Attachment, with any caption in Content.
+const (
+	MessageKindChat   = "chat"
+	MessageKindAction = "action"
+	MessageKindFile   = "file"
 )
 
 type Message struct {
 	BaseModel
 	Content  []byte
+	Kind     string    `gorm:"not null;default:chat"`
 	SenderID uuid.UUID `gorm:"type:uuid"`
 	Sender   User      `gorm:"foreignKey:SenderID"`
 	RoomID   uuid.UUID `gorm:"type:uuid"`
 	Room     Room      `gorm:"foreignKey:RoomID"`
+	// ParentID is set on replies to the thread started by that message.
+	ParentID   *uuid.UUID `gorm:"type:uuid;index"`
+	EditedAt   *time.Time
+	Attachment Attachment `gorm:"embedded;embeddedPrefix:attachment_"`
+	// Mentions are the users the message names with @name. They are stored
+	// along with a new message, but not loaded with it.
+	Mentions []Mention `gorm:"foreignKey:MessageID"`
+}
+
+// Attachment describes a file sent as a message. Its contents are kept in the
+// blob store under Key, which is empty on messages without one.
+type Attachment struct {
+	Key         string
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// Reaction is one user's emoji reaction to a message. A user can react to a
+// message with any number of different emoji, but each only once.
+type Reaction struct {
+	MessageID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Emoji     string    `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+func (Reaction) TableName() string {
+	return "message_reactions"
+}
+
+// Mention records that a message named a user with @name.
+type Mention struct {
+	MessageID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time
+}
+
+func (Mention) TableName() string {
+	return "message_mentions"
+}
+
+// ReactionCount is how many users reacted to a message with an emoji, and
+// whether the viewer the counts were fetched for is one of them.
+type ReactionCount struct {
+	MessageID uuid.UUID
+	Emoji     string
+	Count     int
+	Mine      bool
+}
+
+// ThreadSummary describes the replies to a message.
+type ThreadSummary struct {
+	ParentID    uuid.UUID
+	ReplyCount  int
+	LastReplyAt time.Time
+}
+
+// MessageSearch filters a full-text search over message content. Zero-valued
+// filters are ignored.
+type MessageSearch struct {
+	Query string
+	// UserID restricts the search to rooms the user is a member of.
+	UserID uuid.UUID
+	RoomID uuid.UUID
+	Author string
+	Before time.Time
+	After  time.Time
+	Limit  int
+}
+
+// likeEscaper escapes the LIKE wildcards in a search word.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// searchStem crudely stems word for substring matching by trimming a common
+// English suffix, so "releases" finds "release" and "deploying" "deployed".
+func searchStem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if stem, ok := strings.CutSuffix(word, suffix); ok && len(stem) >= 3 {
+			return stem
+		}
+	}
+	return word
 }
 
 type MessageRepository struct {
@@ -43,6 +139,280 @@ func (r *MessageRepository) GetByRoom(roomID uuid.UUID, limit, offset int) ([]Me
 	return messages, err
 }
 
+// GetByRoomBefore returns up to limit messages in the room older than before,
+// newest first, leaving out thread replies. Message IDs are UUIDv7 and
+// therefore ordered by creation time, which makes them a stable keyset cursor.
+// A nil before starts from the newest message.
+func (r *MessageRepository) GetByRoomBefore(roomID, before uuid.UUID, limit int) ([]Message, error) {
+	var messages []Message
+	query := r.db.Preload("Sender").Where("room_id = ? AND parent_id IS NULL", roomID)
+	if before != uuid.Nil {
+		query = query.Where("id < ?", before)
+	}
+	err := query.Order("id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetByRoomAfter returns up to limit messages in the room newer than after,
+// oldest first, thread replies included. A nil after starts from the oldest
+// message.
+func (r *MessageRepository) GetByRoomAfter(roomID, after uuid.UUID, limit int) ([]Message, error) {
+	var messages []Message
+	query := r.db.Preload("Sender").Where("room_id = ?", roomID)
+	if after != uuid.Nil {
+		query = query.Where("id > ?", after)
+	}
+	err := query.Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetReplies returns the replies to parentID in the order they were sent.
+func (r *MessageRepository) GetReplies(parentID uuid.UUID) ([]Message, error) {
+	var messages []Message
+	err := r.db.Preload("Sender").
+		Where("parent_id = ?", parentID).
+		Order("id ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+// ThreadSummaries returns a summary for each of parentIDs that has replies.
+func (r *MessageRepository) ThreadSummaries(parentIDs []uuid.UUID) ([]ThreadSummary, error) {
+	var summaries []ThreadSummary
+	if len(parentIDs) == 0 {
+		return summaries, nil
+	}
+
+	// Aggregates lose their column type on SQLite, so rather than scanning
+	// MAX(created_at) find the latest reply by ID and load its timestamp.
+	// UUIDv7s sort the same as text as they do natively.
+	var rows []struct {
+		ParentID    uuid.UUID
+		ReplyCount  int
+		LastReplyID string
+	}
+	err := r.db.Model(&Message{}).
+		Select("parent_id, COUNT(*) AS reply_count, MAX(CAST(id AS TEXT)) AS last_reply_id").
+		Where("parent_id IN ?", parentIDs).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return summaries, err
+	}
+
+	lastIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		id, err := uuid.Parse(row.LastReplyID)
+		if err != nil {
+			return nil, err
+		}
+		lastIDs = append(lastIDs, id)
+	}
+	var lastReplies []Message
+	if err := r.db.Select("id", "created_at").Where("id IN ?", lastIDs).Find(&lastReplies).Error; err != nil {
+		return nil, err
+	}
+	sentAt := make(map[uuid.UUID]time.Time, len(lastReplies))
+	for _, m := range lastReplies {
+		sentAt[m.ID] = m.CreatedAt
+	}
+
+	for i, row := range rows {
+		summaries = append(summaries, ThreadSummary{
+			ParentID:    row.ParentID,
+			ReplyCount:  row.ReplyCount,
+			LastReplyAt: sentAt[lastIDs[i]],
+		})
+	}
+	return summaries, nil
+}
+
+// AddReaction records userID reacting to messageID with emoji. It reports
+// whether the reaction is new.
+func (r *MessageRepository) AddReaction(messageID, userID uuid.UUID, emoji string) (bool, error) {
+	reaction := Reaction{MessageID: messageID, UserID: userID, Emoji: emoji}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RemoveReaction withdraws a reaction. It reports whether there was one.
+func (r *MessageRepository) RemoveReaction(messageID, userID uuid.UUID, emoji string) (bool, error) {
+	result := r.db.Exec("DELETE FROM message_reactions WHERE message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReactionCounts returns the reactions to each of messageIDs, grouped by emoji
+// in the order each emoji was first used, marking those viewerID made.
+func (r *MessageRepository) ReactionCounts(messageIDs []uuid.UUID, viewerID uuid.UUID) ([]ReactionCount, error) {
+	var counts []ReactionCount
+	if len(messageIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.Model(&Reaction{}).
+		Select("message_id, emoji, COUNT(*) AS count, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) > 0 AS mine", viewerID).
+		Where("message_id IN ?", messageIDs).
+		Group("message_id, emoji").
+		Order("MIN(created_at) ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// MentionsOf returns up to limit messages mentioning userID older than before,
+// newest first, leaving out messages that were deleted or are in rooms the
+// user has since left. A nil before starts from the newest mention.
+func (r *MessageRepository) MentionsOf(userID, before uuid.UUID, limit int) ([]Message, error) {
+	var messages []Message
+	query := r.db.Preload("Sender").Preload("Room").
+		Joins("JOIN message_mentions ON message_mentions.message_id = messages.id AND message_mentions.user_id = ?", userID).
+		Joins("JOIN room_members ON room_members.room_id = messages.room_id AND room_members.user_id = ?", userID)
+	if before != uuid.Nil {
+		query = query.Where("messages.id < ?", before)
+	}
+	err := query.Order("messages.id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// Search returns up to params.Limit messages matching params.Query, newest
+// first. On Postgres matching uses full-text search over the decoded content,
+// so words are stemmed and stop words ignored, backed by the
+// idx_messages_search index, which the expression here must match. SQLite has
+// no equivalent, so there matching is LIKE only: every word of the query, less
+// a common English suffix, must appear somewhere in the content, ignoring ASCII
+// case, and each search scans the messages it may return.
+func (r *MessageRepository) Search(params MessageSearch) ([]Message, error) {
+	var messages []Message
+	query := r.db.Preload("Sender").
+		Joins("JOIN room_members ON room_members.room_id = messages.room_id AND room_members.user_id = ?", params.UserID)
+	if r.db.Dialector.Name() == "sqlite" {
+		words := strings.Fields(params.Query)
+		if len(words) == 0 {
+			return messages, nil
+		}
+		for _, word := range words {
+			query = query.Where("CAST(messages.content AS TEXT) LIKE ? ESCAPE '\\'", "%"+likeEscaper.Replace(searchStem(word))+"%")
+		}
+	} else {
+		query = query.Where("to_tsvector('english', message_search_text(messages.content)) @@ plainto_tsquery('english', ?)", params.Query)
+	}
+	if params.RoomID != uuid.Nil {
+		query = query.Where("messages.room_id = ?", params.RoomID)
+	}
+	if params.Author != "" {
+		query = query.Joins("JOIN users AS authors ON authors.id = messages.sender_id").
+			Where("authors.name = ?", params.Author)
+	}
+	if !params.Before.IsZero() {
+		query = query.Where("messages.created_at < ?", params.Before)
+	}
+	if !params.After.IsZero() {
+		query = query.Where("messages.created_at > ?", params.After)
+	}
+	err := query.Order("messages.id DESC").
+		Limit(params.Limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *MessageRepository) UpdateContent(id uuid.UUID, content []byte, editedAt time.Time) error {
+	return r.db.Model(&Message{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"content": content, "edited_at": editedAt}).Error
+}
+
+// Prune deletes up to limit of the room's messages, oldest first, that were
+// sent before before or are older than its newest keep messages. A zero before
+// or keep sets no such limit. The replies to any thread they start go with
+// them, so that none is left pointing at a missing parent. Messages are
+// soft-deleted unless hard is set, in which case those already soft-deleted are
+// included and they are removed for good along with their reactions and
+// mentions. It returns the messages deleted with only their IDs and attachment
+// keys loaded.
+func (r *MessageRepository) Prune(roomID uuid.UUID, before time.Time, keep, limit int, hard bool) ([]Message, error) {
+	var conds []string
+	var args []any
+	if !before.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, before)
+	}
+	if keep > 0 {
+		var kept []Message
+		err := r.db.Select("id").
+			Where("room_id = ?", roomID).
+			Order("id DESC").
+			Offset(keep - 1).
+			Limit(1).
+			Find(&kept).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(kept) > 0 {
+			conds = append(conds, "id < ?")
+			args = append(args, kept[0].ID)
+		}
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	query := r.db
+	if hard {
+		query = query.Unscoped()
+	}
+	var expired []Message
+	err := query.Select("id", "attachment_key").
+		Where("room_id = ?", roomID).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Order("id ASC").
+		Limit(limit).
+		Find(&expired).Error
+	if err != nil || len(expired) == 0 {
+		return expired, err
+	}
+
+	ids := make([]uuid.UUID, len(expired))
+	for i, m := range expired {
+		ids[i] = m.ID
+	}
+	var replies []Message
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		find := tx
+		if hard {
+			find = tx.Unscoped()
+		}
+		err := find.Select("id", "attachment_key").
+			Where("parent_id IN ? AND id NOT IN ?", ids, ids).
+			Find(&replies).Error
+		if err != nil {
+			return err
+		}
+		for _, m := range replies {
+			ids = append(ids, m.ID)
+		}
+		if !hard {
+			return tx.Where("id IN ?", ids).Delete(&Message{}).Error
+		}
+		if err := tx.Where("message_id IN ?", ids).Delete(&Reaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id IN ?", ids).Delete(&Mention{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&Message{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(expired, replies...), nil
+}
+
+// Delete soft-deletes the message; it is excluded from queries but kept in the
+// table with deleted_at set.
 func (r *MessageRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&Message{}, "id = ?", id).Error
 }
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TestMigrations_MatchModels checks that the migrated test database has every
+// table, column and index the models expect, so that a model change without a
+// migration is caught.
+func TestMigrations_MatchModels(t *testing.T) {
+	assertSchemaMatchesModels(t, testDB)
+}
+
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	migrator := db.Migrator()
+	for _, model := range []any{&User{}, &Room{}, &RoomMember{}, &Message{}, &Reaction{}, &Mention{}, &Webhook{}, &WebhookDelivery{}} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parsing %T: %v", model, err)
+		}
+		s := stmt.Schema
+		if !migrator.HasTable(model) {
+			t.Errorf("table %s is missing", s.Table)
+			continue
+		}
+		for _, field := range s.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s is missing", s.Table, field.DBName)
+			}
+		}
+		for _, index := range s.ParseIndexes() {
+			if !migrator.HasIndex(model, index.Name) {
+				t.Errorf("index %s on %s is missing", index.Name, s.Table)
+			}
+		}
+	}
+}
+
+// The models as they were when AutoMigrate built the schema, before
+// migrations were introduced.
+type (
+	baselineUser struct {
+		BaseModel
+		Name   string
+		APIKey string         `gorm:"uniqueIndex"`
+		Rooms  []baselineRoom `gorm:"many2many:room_members;joinForeignKey:UserID;joinReferences:RoomID"`
+	}
+	baselineRoom struct {
+		BaseModel
+		Name    string
+		Members []baselineUser `gorm:"many2many:room_members;joinForeignKey:RoomID;joinReferences:UserID"`
+	}
+	baselineMessage struct {
+		BaseModel
+		Content  []byte
+		SenderID uuid.UUID    `gorm:"type:uuid"`
+		Sender   baselineUser `gorm:"foreignKey:SenderID"`
+		RoomID   uuid.UUID    `gorm:"type:uuid"`
+		Room     baselineRoom `gorm:"foreignKey:RoomID"`
+	}
+)
+
+func (baselineUser) TableName() string    { return "users" }
+func (baselineRoom) TableName() string    { return "rooms" }
+func (baselineMessage) TableName() string { return "messages" }
+
+func TestMigrator_UpgradesBaselineSchema(t *testing.T) {
+	store, err := Open("sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := store.AutoMigrate(&baselineUser{}, &baselineRoom{}, &baselineMessage{}); err != nil {
+		t.Fatalf("building the baseline schema: %v", err)
+	}
+	user := baselineUser{Name: "alice", APIKey: "key"}
+	room := baselineRoom{Name: "general"}
+	if err := store.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	room.Members = []baselineUser{user}
+	if err := store.Create(&room).Error; err != nil {
+		t.Fatalf("creating room: %v", err)
+	}
+	if err := store.Create(&baselineMessage{Content: []byte("hello"), SenderID: user.ID, RoomID: room.ID}).Error; err != nil {
+		t.Fatalf("creating message: %v", err)
+	}
+
+	migrator, err := NewMigrator(store.DB)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Errorf("Check after Up: %v", err)
+	}
+	assertSchemaMatchesModels(t, store.DB)
+
+	// Rows from before the upgrade take the new columns' defaults.
+	upgraded, err := store.Rooms().GetByID(room.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if upgraded.Visibility != RoomVisibilityPublic || upgraded.Kind != RoomKindRoom {
+		t.Errorf("upgraded room is %s %s, want a public room", upgraded.Visibility, upgraded.Kind)
+	}
+	member, err := store.Rooms().GetMember(room.ID, user.ID)
+	if err != nil {
+		t.Fatalf("GetMember: %v", err)
+	}
+	if member.Role != RoleMember {
+		t.Errorf("upgraded member has role %q, want %q", member.Role, RoleMember)
+	}
+	page, err := store.Messages().GetByRoomBefore(room.ID, uuid.Nil, 10)
+	if err != nil {
+		t.Fatalf("GetByRoomBefore: %v", err)
+	}
+	if len(page) != 1 || page[0].Kind != MessageKindChat {
+		t.Errorf("upgraded messages = %+v, want the one chat message", page)
+	}
+}
+
+func TestMigrations_SameVersionsForEveryDialect(t *testing.T) {
+	postgres, err := loadMigrations("postgres")
+	if err != nil {
+		t.Fatalf("postgres: %v", err)
+	}
+	sqlite, err := loadMigrations("sqlite")
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("postgres has %d migrations, sqlite %d", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Errorf("migration %d: postgres %d_%s, sqlite %d_%s", i,
+				postgres[i].Version, postgres[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	store, err := Open("sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	migrator, err := NewMigrator(store.DB)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	total := len(migrator.migrations)
+
+	if err := migrator.Check(); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("Check on an empty database = %v, want ErrSchemaBehind", err)
+	}
+
+	ran, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(ran) != total {
+		t.Errorf("Up applied %d migrations, want %d", len(ran), total)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Errorf("Check after Up: %v", err)
+	}
+	if ran, _ := migrator.Up(); len(ran) != 0 {
+		t.Errorf("second Up applied %d migrations, want none", len(ran))
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt.IsZero() {
+			t.Errorf("migration %d_%s not reported as applied", s.Version, s.Name)
+		}
+	}
+
+	reverted, err := migrator.Down(1)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != migrator.migrations[total-1].Version {
+		t.Errorf("Down(1) reverted %+v, want the latest migration", reverted)
+	}
+	if err := migrator.Check(); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("Check after Down = %v, want ErrSchemaBehind", err)
+	}
+
+	// Reverting everything leaves only the bookkeeping table behind.
+	if _, err := migrator.Down(total); err != nil {
+		t.Fatalf("Down(all): %v", err)
+	}
+	if store.Migrator().HasTable(&User{}) {
+		t.Error("users table survived reverting every migration")
+	}
+	if ran, err := migrator.Up(); err != nil || len(ran) != total {
+		t.Errorf("Up after Down = %d migrations, %v", len(ran), err)
+	}
+}
@@ -0,0 +1,3 @@
+-- Message edits: when a message was last edited, if ever.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS edited_at timestamptz;
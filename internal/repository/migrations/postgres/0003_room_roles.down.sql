@@ -0,0 +1,4 @@
+ALTER TABLE room_members DROP COLUMN IF EXISTS created_at;
+ALTER TABLE room_members DROP COLUMN IF EXISTS role;
+ALTER TABLE rooms DROP COLUMN IF EXISTS visibility;
+ALTER TABLE rooms DROP COLUMN IF EXISTS owner_id;
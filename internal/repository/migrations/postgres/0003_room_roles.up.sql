@@ -0,0 +1,6 @@
+-- Room ownership and visibility, and each member's role.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS owner_id uuid;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS visibility text NOT NULL DEFAULT 'public';
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'member';
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS created_at timestamptz;
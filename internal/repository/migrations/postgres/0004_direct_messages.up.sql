@@ -0,0 +1,5 @@
+-- Direct message rooms, found by the sorted IDs of their participants.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'room';
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS dm_key text;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_dm_key ON rooms (dm_key);
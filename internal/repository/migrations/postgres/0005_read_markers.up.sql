@@ -0,0 +1,3 @@
+-- Read markers: the last message each member has read.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS last_read_message_id uuid;
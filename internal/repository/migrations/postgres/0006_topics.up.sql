@@ -0,0 +1,4 @@
+-- Room topics, and message kinds for /me actions and topic changes.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS topic text;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'chat';
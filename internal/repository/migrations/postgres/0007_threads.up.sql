@@ -0,0 +1,4 @@
+-- Threaded replies point at the top-level message they answer.
+-- Databases that AutoMigrate already brought up to date keep their columns.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS parent_id uuid;
+CREATE INDEX IF NOT EXISTS idx_messages_parent_id ON messages (parent_id);
@@ -0,0 +1,2 @@
+ALTER TABLE rooms DROP COLUMN encrypted;
+ALTER TABLE users DROP COLUMN public_key;
@@ -0,0 +1,4 @@
+ALTER TABLE messages DROP COLUMN attachment_size;
+ALTER TABLE messages DROP COLUMN attachment_content_type;
+ALTER TABLE messages DROP COLUMN attachment_name;
+ALTER TABLE messages DROP COLUMN attachment_key;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_owner_id;
+ALTER TABLE users DROP COLUMN owner_id;
+ALTER TABLE users DROP COLUMN scopes;
+ALTER TABLE users DROP COLUMN kind;
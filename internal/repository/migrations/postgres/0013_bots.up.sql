@@ -0,0 +1,6 @@
+-- Bot accounts: what kind of user each is, the scopes a bot's API key
+-- grants, and the human user who owns it.
+ALTER TABLE users ADD COLUMN kind text NOT NULL DEFAULT 'human';
+ALTER TABLE users ADD COLUMN scopes text;
+ALTER TABLE users ADD COLUMN owner_id uuid;
+CREATE INDEX IF NOT EXISTS idx_users_owner_id ON users (owner_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_room_created_at;
+ALTER TABLE rooms DROP COLUMN retention_max_messages;
+ALTER TABLE rooms DROP COLUMN retention_max_age_days;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_search;
+DROP FUNCTION IF EXISTS message_search_text(bytea);
@@ -0,0 +1,6 @@
+-- Full-text search: index the words of every message so that searching does
+-- not decode and parse each message in the room. convert_from is not
+-- immutable, which indexes require, so it is wrapped in a function that is;
+-- content that is not valid text is indexed as empty.
+CREATE OR REPLACE FUNCTION message_search_text(content bytea) RETURNS text LANGUAGE plpgsql IMMUTABLE STRICT AS $$ BEGIN RETURN convert_from(content, 'UTF8'); EXCEPTION WHEN others THEN RETURN ''; END $$;
+CREATE INDEX IF NOT EXISTS idx_messages_search ON messages USING gin (to_tsvector('english', message_search_text(content)));
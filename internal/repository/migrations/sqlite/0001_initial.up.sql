@@ -0,0 +1,44 @@
+-- The schema the baseline AutoMigrate built before migrations were
+-- introduced. Every statement is guarded so that databases it built adopt
+-- this migration unchanged; the migrations after it add what later versions
+-- of the models need.
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name text,
+    api_key text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_api_key ON users (api_key);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id uuid PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_rooms_deleted_at ON rooms (deleted_at);
+
+CREATE TABLE IF NOT EXISTS room_members (
+    user_id uuid,
+    room_id uuid,
+    PRIMARY KEY (user_id, room_id),
+    CONSTRAINT fk_room_members_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_room_members_room FOREIGN KEY (room_id) REFERENCES rooms (id)
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id uuid PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    content blob,
+    sender_id uuid,
+    room_id uuid,
+    CONSTRAINT fk_messages_sender FOREIGN KEY (sender_id) REFERENCES users (id),
+    CONSTRAINT fk_messages_room FOREIGN KEY (room_id) REFERENCES rooms (id)
+);
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages (deleted_at);
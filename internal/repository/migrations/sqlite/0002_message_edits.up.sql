@@ -0,0 +1,2 @@
+-- Message edits: when a message was last edited, if ever.
+ALTER TABLE messages ADD COLUMN edited_at datetime;
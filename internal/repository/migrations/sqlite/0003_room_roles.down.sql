@@ -0,0 +1,4 @@
+ALTER TABLE room_members DROP COLUMN created_at;
+ALTER TABLE room_members DROP COLUMN role;
+ALTER TABLE rooms DROP COLUMN visibility;
+ALTER TABLE rooms DROP COLUMN owner_id;
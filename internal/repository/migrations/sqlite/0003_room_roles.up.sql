@@ -0,0 +1,5 @@
+-- Room ownership and visibility, and each member's role.
+ALTER TABLE rooms ADD COLUMN owner_id uuid;
+ALTER TABLE rooms ADD COLUMN visibility text NOT NULL DEFAULT 'public';
+ALTER TABLE room_members ADD COLUMN role text NOT NULL DEFAULT 'member';
+ALTER TABLE room_members ADD COLUMN created_at datetime;
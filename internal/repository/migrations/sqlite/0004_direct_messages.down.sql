@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_rooms_dm_key;
+ALTER TABLE rooms DROP COLUMN dm_key;
+ALTER TABLE rooms DROP COLUMN kind;
@@ -0,0 +1,4 @@
+-- Direct message rooms, found by the sorted IDs of their participants.
+ALTER TABLE rooms ADD COLUMN kind text NOT NULL DEFAULT 'room';
+ALTER TABLE rooms ADD COLUMN dm_key text;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_dm_key ON rooms (dm_key);
@@ -0,0 +1,2 @@
+-- Read markers: the last message each member has read.
+ALTER TABLE room_members ADD COLUMN last_read_message_id uuid;
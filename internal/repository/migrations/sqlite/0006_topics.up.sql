@@ -0,0 +1,3 @@
+-- Room topics, and message kinds for /me actions and topic changes.
+ALTER TABLE rooms ADD COLUMN topic text;
+ALTER TABLE messages ADD COLUMN kind text NOT NULL DEFAULT 'chat';
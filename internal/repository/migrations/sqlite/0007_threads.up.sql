@@ -0,0 +1,3 @@
+-- Threaded replies point at the top-level message they answer.
+ALTER TABLE messages ADD COLUMN parent_id uuid;
+CREATE INDEX IF NOT EXISTS idx_messages_parent_id ON messages (parent_id);
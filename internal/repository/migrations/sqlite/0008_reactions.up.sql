@@ -0,0 +1,8 @@
+-- Emoji reactions, one row per user per emoji on a message.
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id uuid,
+    user_id uuid,
+    emoji text,
+    created_at datetime,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
@@ -0,0 +1,4 @@
+-- End-to-end encrypted rooms: each user's public key for the key directory,
+-- and whether a room only accepts encrypted messages.
+ALTER TABLE users ADD COLUMN public_key text;
+ALTER TABLE rooms ADD COLUMN encrypted boolean NOT NULL DEFAULT false;
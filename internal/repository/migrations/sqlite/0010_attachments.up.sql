@@ -0,0 +1,5 @@
+-- File attachments: messages of kind 'file' name a blob in the blob store.
+ALTER TABLE messages ADD COLUMN attachment_key text;
+ALTER TABLE messages ADD COLUMN attachment_name text;
+ALTER TABLE messages ADD COLUMN attachment_content_type text;
+ALTER TABLE messages ADD COLUMN attachment_size integer;
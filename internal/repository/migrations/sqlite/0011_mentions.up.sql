@@ -0,0 +1,8 @@
+-- @mentions: which users each message named, for the mentions inbox.
+CREATE TABLE IF NOT EXISTS message_mentions (
+    message_id uuid,
+    user_id uuid,
+    created_at datetime,
+    PRIMARY KEY (message_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_message_mentions_user_id ON message_mentions (user_id);
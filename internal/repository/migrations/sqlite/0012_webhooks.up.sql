@@ -0,0 +1,35 @@
+-- Webhooks: incoming ones post to a room from a secret URL, outgoing ones
+-- are sent each new message, with a log of every delivery attempt.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    room_id uuid,
+    kind text NOT NULL,
+    name text,
+    token_hash text,
+    user_id uuid,
+    url text,
+    secret text,
+    created_by_id uuid
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_room_id ON webhooks (room_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhooks_token_hash ON webhooks (token_hash);
+CREATE INDEX IF NOT EXISTS idx_webhooks_deleted_at ON webhooks (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    webhook_id uuid,
+    message_id uuid,
+    attempt integer,
+    status_code integer,
+    error text,
+    duration_ms bigint,
+    succeeded boolean
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_deleted_at ON webhook_deliveries (deleted_at);
@@ -0,0 +1,5 @@
+-- Message retention: how long each room keeps its messages, on top of the
+-- server's own limits, and an index for finding the expired ones.
+ALTER TABLE rooms ADD COLUMN retention_max_age_days integer NOT NULL DEFAULT 0;
+ALTER TABLE rooms ADD COLUMN retention_max_messages integer NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_messages_room_created_at ON messages (room_id, created_at);
@@ -0,0 +1 @@
+-- SQLite has no full-text index to add; search there matches with LIKE.
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -15,7 +16,19 @@ import (
 
 var testDB *gorm.DB
 
+// TestMain runs the suite against a throwaway Postgres container, or against
+// an in-memory SQLite database when TEST_DB=sqlite.
 func TestMain(m *testing.M) {
+	if os.Getenv("TEST_DB") == "sqlite" {
+		store, err := Open("sqlite://:memory:")
+		if err != nil {
+			panic("failed to open sqlite test database: " + err.Error())
+		}
+		testDB = store.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+		migrate(testDB)
+		os.Exit(m.Run())
+	}
+
 	ctx := context.Background()
 
 	container, err := tcpostgres.Run(ctx,
@@ -42,19 +55,36 @@ func TestMain(m *testing.M) {
 		panic("failed to connect to test database: " + err.Error())
 	}
 
-	if err := db.AutoMigrate(&User{}, &Room{}, &Message{}); err != nil {
-		panic("failed to migrate test database: " + err.Error())
+	if err := setupJoinTables(db); err != nil {
+		panic("failed to register join tables: " + err.Error())
 	}
+	migrate(db)
 
 	testDB = db
 
 	os.Exit(m.Run())
 }
 
+func migrate(db *gorm.DB) {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		panic("failed to load migrations: " + err.Error())
+	}
+	if _, err := migrator.Up(); err != nil {
+		panic("failed to migrate test database: " + err.Error())
+	}
+}
+
 // truncate clears all tables between tests to ensure isolation.
 func truncate(t *testing.T) {
 	t.Helper()
-	testDB.Exec("TRUNCATE TABLE room_members, messages, rooms, users RESTART IDENTITY CASCADE")
+	if testDB.Dialector.Name() == "sqlite" {
+		for _, table := range []string{"webhook_deliveries", "webhooks", "message_mentions", "message_reactions", "room_members", "messages", "rooms", "users"} {
+			testDB.Exec("DELETE FROM " + table)
+		}
+		return
+	}
+	testDB.Exec("TRUNCATE TABLE webhook_deliveries, webhooks, message_mentions, message_reactions, room_members, messages, rooms, users RESTART IDENTITY CASCADE")
 }
 
 // helpers
@@ -136,6 +166,57 @@ func TestUserRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestUserRepository_SetPublicKey(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	users := NewUserRepository(testDB)
+
+	if err := users.SetPublicKey(u.ID, "cHVibGljIGtleQ=="); err != nil {
+		t.Fatalf("SetPublicKey: %v", err)
+	}
+	got, err := users.GetByID(u.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.PublicKey != "cHVibGljIGtleQ==" {
+		t.Errorf("expected the published key, got %q", got.PublicKey)
+	}
+}
+
+func TestUserRepository_ListBots(t *testing.T) {
+	truncate(t)
+	owner := createUser(t, "alice", HashAPIKey("k1"))
+	other := createUser(t, "bob", HashAPIKey("k2"))
+	users := NewUserRepository(testDB)
+
+	bot := &User{Name: "echo", APIKey: HashAPIKey("k3"), Kind: UserKindBot, Scopes: ScopeMessagesRead, OwnerID: &owner.ID}
+	if err := users.Create(bot); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := users.Create(&User{Name: "other", APIKey: HashAPIKey("k4"), Kind: UserKindBot, OwnerID: &other.ID}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	bots, err := users.ListBots(owner.ID)
+	if err != nil {
+		t.Fatalf("ListBots: %v", err)
+	}
+	if len(bots) != 1 || bots[0].ID != bot.ID {
+		t.Fatalf("expected only alice's bot, got %+v", bots)
+	}
+	if !bots[0].HasScope(ScopeMessagesRead) || bots[0].HasScope(ScopeMessagesWrite) {
+		t.Errorf("expected only the granted scope, got %q", bots[0].Scopes)
+	}
+
+	got, err := users.GetByID(owner.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Kind != UserKindHuman || !got.HasScope(ScopeMessagesWrite) {
+		t.Errorf("expected registered users to be unrestricted humans, got kind %q", got.Kind)
+	}
+}
+
 // ── RoomRepository ────────────────────────────────────────────────────────────
 
 func TestRoomRepository_CreateAndGetByID(t *testing.T) {
@@ -351,3 +432,805 @@ func TestMessageRepository_GetByRoom_IsolatedByRoom(t *testing.T) {
 		t.Errorf("room isolation failed: got %+v", msgs)
 	}
 }
+
+func TestMessageRepository_UpdateContent(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	msg := &Message{Content: []byte("before"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(msg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	editedAt := time.Now()
+	if err := repo.UpdateContent(msg.ID, []byte("after"), editedAt); err != nil {
+		t.Fatalf("UpdateContent: %v", err)
+	}
+
+	got, err := repo.GetByID(msg.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if string(got.Content) != "after" {
+		t.Errorf("expected content after, got %s", got.Content)
+	}
+	if got.EditedAt == nil {
+		t.Error("expected edited_at to be set")
+	}
+}
+
+func TestMessageRepository_Attachment(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	att := Attachment{Key: r.ID.String() + "/blob", Name: "build.log", ContentType: "text/plain; charset=utf-8", Size: 2048}
+	msg := &Message{Content: []byte("failing build"), Kind: MessageKindFile, SenderID: u.ID, RoomID: r.ID, Attachment: att}
+	if err := repo.Create(msg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	plain := &Message{Content: []byte("hi"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(plain); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetByID(msg.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Kind != MessageKindFile || got.Attachment != att {
+		t.Errorf("got kind %q attachment %+v, want file %+v", got.Kind, got.Attachment, att)
+	}
+	got, err = repo.GetByID(plain.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Attachment != (Attachment{}) {
+		t.Errorf("plain message has attachment %+v", got.Attachment)
+	}
+}
+
+func TestMessageRepository_Delete_SoftDeletes(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	msg := &Message{Content: []byte("oops"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(msg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Delete(msg.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	msgs, _ := repo.GetByRoom(r.ID, 10, 0)
+	if len(msgs) != 0 {
+		t.Errorf("expected deleted message to be hidden, got %d", len(msgs))
+	}
+
+	var count int64
+	testDB.Unscoped().Model(&Message{}).Where("id = ?", msg.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("expected row to be kept for soft delete, got %d", count)
+	}
+}
+
+func TestMessageRepository_GetByRoomBefore_PagesByID(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	var created []*Message
+	for _, content := range []string{"one", "two", "three", "four", "five"} {
+		msg := &Message{Content: []byte(content), SenderID: u.ID, RoomID: r.ID}
+		if err := repo.Create(msg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		created = append(created, msg)
+	}
+
+	newest, err := repo.GetByRoomBefore(r.ID, uuid.Nil, 2)
+	if err != nil {
+		t.Fatalf("GetByRoomBefore: %v", err)
+	}
+	if len(newest) != 2 || string(newest[0].Content) != "five" || string(newest[1].Content) != "four" {
+		t.Fatalf("unexpected newest page: %+v", newest)
+	}
+
+	older, err := repo.GetByRoomBefore(r.ID, newest[1].ID, 10)
+	if err != nil {
+		t.Fatalf("GetByRoomBefore: %v", err)
+	}
+	if len(older) != 3 || older[0].ID != created[2].ID {
+		t.Errorf("expected three older messages starting at three, got %+v", older)
+	}
+}
+
+func TestMessageRepository_GetByRoomAfter_IncludesReplies(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	parent := &Message{Content: []byte("one"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(parent); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, msg := range []*Message{
+		{Content: []byte("two"), SenderID: u.ID, RoomID: r.ID, ParentID: &parent.ID},
+		{Content: []byte("three"), SenderID: u.ID, RoomID: r.ID},
+	} {
+		if err := repo.Create(msg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	first, err := repo.GetByRoomAfter(r.ID, uuid.Nil, 2)
+	if err != nil {
+		t.Fatalf("GetByRoomAfter: %v", err)
+	}
+	if len(first) != 2 || string(first[0].Content) != "one" || string(first[1].Content) != "two" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first[0].Sender.Name != "alice" {
+		t.Errorf("expected the sender to be loaded, got %+v", first[0].Sender)
+	}
+
+	rest, err := repo.GetByRoomAfter(r.ID, first[1].ID, 2)
+	if err != nil {
+		t.Fatalf("GetByRoomAfter: %v", err)
+	}
+	if len(rest) != 1 || string(rest[0].Content) != "three" {
+		t.Errorf("expected only three after two, got %+v", rest)
+	}
+}
+
+func TestMessageRepository_Prune(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	other := createRoom(t, "random")
+	repo := NewMessageRepository(testDB)
+
+	now := time.Now()
+	var created []*Message
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour, time.Hour, 0} {
+		msg := &Message{
+			BaseModel: BaseModel{CreatedAt: now.Add(-age)},
+			Content:   []byte{byte('a' + i)},
+			SenderID:  u.ID,
+			RoomID:    r.ID,
+		}
+		if i == 0 {
+			msg.Attachment = Attachment{Key: "blob-key", Name: "a.txt"}
+		}
+		if err := repo.Create(msg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		created = append(created, msg)
+	}
+	if err := repo.Create(&Message{BaseModel: BaseModel{CreatedAt: now.Add(-72 * time.Hour)}, SenderID: u.ID, RoomID: other.ID}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.AddReaction(created[1].ID, u.ID, "👍"); err != nil {
+		t.Fatalf("AddReaction: %v", err)
+	}
+
+	if pruned, err := repo.Prune(r.ID, time.Time{}, 0, 10, false); err != nil || len(pruned) != 0 {
+		t.Fatalf("expected no limits to prune nothing, got %+v, %v", pruned, err)
+	}
+
+	// Older than 36 hours, in batches of one.
+	pruned, err := repo.Prune(r.ID, now.Add(-36*time.Hour), 0, 1, false)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].ID != created[0].ID || pruned[0].Attachment.Key != "blob-key" {
+		t.Fatalf("expected the oldest message and its attachment key, got %+v", pruned)
+	}
+	if pruned, err = repo.Prune(r.ID, now.Add(-36*time.Hour), 0, 10, false); err != nil || len(pruned) != 1 || pruned[0].ID != created[1].ID {
+		t.Fatalf("expected the second oldest message next, got %+v, %v", pruned, err)
+	}
+	var softDeleted int64
+	testDB.Unscoped().Model(&Message{}).Where("room_id = ? AND deleted_at IS NOT NULL", r.ID).Count(&softDeleted)
+	if softDeleted != 2 {
+		t.Errorf("expected 2 soft-deleted messages, got %d", softDeleted)
+	}
+
+	// Keep the newest two, removing the rest for good.
+	pruned, err = repo.Prune(r.ID, time.Time{}, 2, 10, true)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(pruned) != 3 {
+		t.Fatalf("expected the soft-deleted messages and the third removed, got %+v", pruned)
+	}
+	var left []Message
+	testDB.Unscoped().Where("room_id = ?", r.ID).Order("id ASC").Find(&left)
+	if len(left) != 2 || left[0].ID != created[3].ID {
+		t.Errorf("expected only the newest two messages left, got %+v", left)
+	}
+	var reactions int64
+	testDB.Model(&Reaction{}).Where("message_id = ?", created[1].ID).Count(&reactions)
+	if reactions != 0 {
+		t.Errorf("expected reactions to pruned messages removed, got %d", reactions)
+	}
+	if messages, _ := repo.GetByRoom(other.ID, 10, 0); len(messages) != 1 {
+		t.Errorf("expected other rooms untouched, got %+v", messages)
+	}
+}
+
+func TestMessageRepository_Prune_SoftDeletesThreadReplies(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	now := time.Now()
+	parent := &Message{BaseModel: BaseModel{CreatedAt: now.Add(-48 * time.Hour)}, Content: []byte("q"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(parent); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	reply := &Message{Content: []byte("a"), SenderID: u.ID, RoomID: r.ID, ParentID: &parent.ID}
+	if err := repo.Create(reply); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	pruned, err := repo.Prune(r.ID, now.Add(-24*time.Hour), 0, 10, false)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(pruned) != 2 || pruned[0].ID != parent.ID || pruned[1].ID != reply.ID {
+		t.Fatalf("expected the parent and its reply, got %+v", pruned)
+	}
+	if replies, err := repo.GetReplies(parent.ID); err != nil || len(replies) != 0 {
+		t.Errorf("expected no replies left, got %+v, %v", replies, err)
+	}
+	if summaries, err := repo.ThreadSummaries([]uuid.UUID{parent.ID}); err != nil || len(summaries) != 0 {
+		t.Errorf("expected no thread left, got %+v, %v", summaries, err)
+	}
+}
+
+func TestMessageRepository_Prune_HardDeletesThreadReplies(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewMessageRepository(testDB)
+
+	now := time.Now()
+	parent := &Message{BaseModel: BaseModel{CreatedAt: now.Add(-48 * time.Hour)}, Content: []byte("q"), SenderID: u.ID, RoomID: r.ID}
+	if err := repo.Create(parent); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	reply := &Message{Content: []byte("a"), SenderID: u.ID, RoomID: r.ID, ParentID: &parent.ID, Attachment: Attachment{Key: "reply-blob"}}
+	if err := repo.Create(reply); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.AddReaction(reply.ID, u.ID, "👍"); err != nil {
+		t.Fatalf("AddReaction: %v", err)
+	}
+
+	pruned, err := repo.Prune(r.ID, now.Add(-24*time.Hour), 0, 10, true)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(pruned) != 2 || pruned[0].ID != parent.ID || pruned[1].ID != reply.ID || pruned[1].Attachment.Key != "reply-blob" {
+		t.Fatalf("expected the parent and its reply with its attachment key, got %+v", pruned)
+	}
+	var left, reactions int64
+	testDB.Unscoped().Model(&Message{}).Where("room_id = ?", r.ID).Count(&left)
+	testDB.Model(&Reaction{}).Where("message_id = ?", reply.ID).Count(&reactions)
+	if left != 0 || reactions != 0 {
+		t.Errorf("expected no messages or reactions left, got %d and %d", left, reactions)
+	}
+}
+
+func TestRoomRepository_CreateWithOwner(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	repo := NewRoomRepository(testDB)
+
+	r := &Room{Name: "secret", Visibility: RoomVisibilityPrivate}
+	if err := repo.CreateWithOwner(r, u.ID); err != nil {
+		t.Fatalf("CreateWithOwner: %v", err)
+	}
+
+	member, err := repo.GetMember(r.ID, u.ID)
+	if err != nil {
+		t.Fatalf("GetMember: %v", err)
+	}
+	if member.Role != RoleOwner {
+		t.Errorf("expected owner role, got %s", member.Role)
+	}
+	if r.OwnerID == nil || *r.OwnerID != u.ID {
+		t.Errorf("expected owner_id %s, got %v", u.ID, r.OwnerID)
+	}
+}
+
+func TestRoomRepository_Import(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	createUser(t, "bob", HashAPIKey("k2"))
+	rooms := NewRoomRepository(testDB)
+
+	// bob exported from elsewhere clashes with the bob here, by name, and
+	// carol, by ID, with alice.
+	bobID, parentID, replyID := uuid.New(), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	sent := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	authors := []User{
+		{BaseModel: BaseModel{ID: bobID}, Name: "bob"},
+		{BaseModel: BaseModel{ID: alice.ID}, Name: "carol"},
+	}
+	messages := []Message{
+		{BaseModel: BaseModel{ID: parentID, CreatedAt: sent}, Content: []byte("hello"), Kind: MessageKindChat, SenderID: alice.ID},
+		{BaseModel: BaseModel{ID: replyID, CreatedAt: sent.Add(time.Minute)}, Content: []byte("hi"), Kind: MessageKindChat, SenderID: bobID, ParentID: &parentID},
+	}
+	room := &Room{Name: "archive", Visibility: RoomVisibilityPrivate}
+	if err := rooms.Import(room, alice.ID, authors, messages); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	got, err := NewMessageRepository(testDB).GetByRoomAfter(room.ID, uuid.Nil, 10)
+	if err != nil {
+		t.Fatalf("GetByRoomAfter: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != parentID || got[1].ID != replyID {
+		t.Fatalf("expected both messages with their IDs, got %+v", got)
+	}
+	if !got[0].CreatedAt.Equal(sent) || got[1].ParentID == nil || *got[1].ParentID != parentID {
+		t.Errorf("expected timestamps and threads kept, got %+v", got)
+	}
+	if got[0].Sender.Name != "carol" || got[0].SenderID == alice.ID {
+		t.Errorf("expected carol to be given a new ID, got %+v", got[0].Sender)
+	}
+	if got[1].Sender.Name != "bob-2" || got[1].SenderID != bobID {
+		t.Errorf("expected bob to keep his ID and be renamed, got %+v", got[1].Sender)
+	}
+	if member, err := rooms.GetMember(room.ID, alice.ID); err != nil || member.Role != RoleOwner {
+		t.Errorf("expected the importer to own the room, got %+v, %v", member, err)
+	}
+
+	again := &Room{Name: "again"}
+	if err := rooms.Import(again, alice.ID, nil, messages[:1]); !errors.Is(err, ErrMessagesExist) {
+		t.Errorf("expected ErrMessagesExist importing twice, got %v", err)
+	}
+	if _, err := rooms.GetByID(again.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected a refused import to create no room, got %v", err)
+	}
+}
+
+func TestRoomRepository_Retention(t *testing.T) {
+	truncate(t)
+	repo := NewRoomRepository(testDB)
+	kept := createRoom(t, "general")
+	createRoom(t, "random")
+
+	if err := repo.SetRetention(kept.ID, Retention{MaxAgeDays: 30, MaxMessages: 1000}); err != nil {
+		t.Fatalf("SetRetention: %v", err)
+	}
+	got, err := repo.GetByID(kept.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Retention != (Retention{MaxAgeDays: 30, MaxMessages: 1000}) {
+		t.Errorf("unexpected retention: %+v", got.Retention)
+	}
+
+	own, err := repo.ListRetained(false)
+	if err != nil {
+		t.Fatalf("ListRetained: %v", err)
+	}
+	if len(own) != 1 || own[0].ID != kept.ID || own[0].Retention.MaxAgeDays != 30 {
+		t.Errorf("expected only the room with a policy, got %+v", own)
+	}
+	if all, err := repo.ListRetained(true); err != nil || len(all) != 2 {
+		t.Errorf("expected every room, got %+v, %v", all, err)
+	}
+}
+
+func TestRetention_Stricter(t *testing.T) {
+	tests := []struct {
+		a, b, want Retention
+	}{
+		{Retention{}, Retention{}, Retention{}},
+		{Retention{MaxAgeDays: 30}, Retention{}, Retention{MaxAgeDays: 30}},
+		{Retention{}, Retention{MaxMessages: 100}, Retention{MaxMessages: 100}},
+		{Retention{MaxAgeDays: 30, MaxMessages: 50}, Retention{MaxAgeDays: 7, MaxMessages: 100}, Retention{MaxAgeDays: 7, MaxMessages: 50}},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Stricter(tt.b); got != tt.want {
+			t.Errorf("%+v.Stricter(%+v) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRoomRepository_ListVisible_HidesPrivateRoomsFromNonMembers(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	repo := NewRoomRepository(testDB)
+
+	_ = repo.CreateWithOwner(&Room{Name: "lobby", Visibility: RoomVisibilityPublic}, alice.ID)
+	_ = repo.CreateWithOwner(&Room{Name: "invite", Visibility: RoomVisibilityInviteOnly}, alice.ID)
+	_ = repo.CreateWithOwner(&Room{Name: "secret", Visibility: RoomVisibilityPrivate}, alice.ID)
+
+	forAlice, err := repo.ListVisible(alice.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("ListVisible: %v", err)
+	}
+	if len(forAlice) != 3 {
+		t.Errorf("expected owner to see 3 rooms, got %d", len(forAlice))
+	}
+
+	forBob, err := repo.ListVisible(bob.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("ListVisible: %v", err)
+	}
+	if len(forBob) != 2 {
+		t.Errorf("expected non-member to see 2 rooms, got %d", len(forBob))
+	}
+}
+
+func TestRoomRepository_SetMemberRole(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	repo := NewRoomRepository(testDB)
+
+	if err := repo.AddMember(r.ID, u.ID); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	if err := repo.SetMemberRole(r.ID, u.ID, RoleModerator); err != nil {
+		t.Fatalf("SetMemberRole: %v", err)
+	}
+
+	member, err := repo.GetMember(r.ID, u.ID)
+	if err != nil {
+		t.Fatalf("GetMember: %v", err)
+	}
+	if member.Role != RoleModerator {
+		t.Errorf("expected moderator, got %s", member.Role)
+	}
+}
+
+func TestRoomRepository_CreateDM_FoundByKeyInAnyOrder(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	repo := NewRoomRepository(testDB)
+
+	dm, err := repo.CreateDM([]uuid.UUID{alice.ID, bob.ID})
+	if err != nil {
+		t.Fatalf("CreateDM: %v", err)
+	}
+	if dm.Kind != RoomKindDM || dm.Visibility != RoomVisibilityPrivate {
+		t.Errorf("expected private dm, got kind=%s visibility=%s", dm.Kind, dm.Visibility)
+	}
+
+	got, err := repo.GetByDMKey(DMKey([]uuid.UUID{bob.ID, alice.ID}))
+	if err != nil {
+		t.Fatalf("GetByDMKey: %v", err)
+	}
+	if got.ID != dm.ID {
+		t.Errorf("expected dm %s, got %s", dm.ID, got.ID)
+	}
+	if len(got.Members) != 2 {
+		t.Errorf("expected 2 members, got %d", len(got.Members))
+	}
+
+	if _, err := repo.CreateDM([]uuid.UUID{bob.ID, alice.ID}); err == nil {
+		t.Error("expected duplicate dm to be rejected")
+	}
+}
+
+func TestRoomRepository_UnreadCounts_FollowReadMarker(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	r := createRoom(t, "general")
+	rooms := NewRoomRepository(testDB)
+	messages := NewMessageRepository(testDB)
+
+	_ = rooms.AddMember(r.ID, alice.ID)
+	_ = rooms.AddMember(r.ID, bob.ID)
+
+	var sent []*Message
+	for _, content := range []string{"one", "two", "three"} {
+		msg := &Message{Content: []byte(content), SenderID: bob.ID, RoomID: r.ID}
+		if err := messages.Create(msg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		sent = append(sent, msg)
+	}
+	// Own messages never count as unread.
+	_ = messages.Create(&Message{Content: []byte("mine"), SenderID: alice.ID, RoomID: r.ID})
+
+	unread := func() int {
+		t.Helper()
+		counts, err := rooms.UnreadCounts(alice.ID)
+		if err != nil {
+			t.Fatalf("UnreadCounts: %v", err)
+		}
+		if len(counts) != 1 {
+			t.Fatalf("expected 1 room, got %d", len(counts))
+		}
+		return counts[0].Count
+	}
+
+	if got := unread(); got != 3 {
+		t.Errorf("expected 3 unread before reading, got %d", got)
+	}
+
+	if err := rooms.MarkRead(r.ID, alice.ID, sent[1].ID); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if got := unread(); got != 1 {
+		t.Errorf("expected 1 unread, got %d", got)
+	}
+
+	// Marking an older message does not move the marker back.
+	if err := rooms.MarkRead(r.ID, alice.ID, sent[0].ID); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if got := unread(); got != 1 {
+		t.Errorf("expected marker to stay put, got %d unread", got)
+	}
+}
+
+func TestMessageRepository_Search_OnlyMemberRooms(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	general := createRoom(t, "general")
+	secret := createRoom(t, "secret")
+	rooms := NewRoomRepository(testDB)
+	messages := NewMessageRepository(testDB)
+
+	_ = rooms.AddMember(general.ID, alice.ID)
+	_ = rooms.AddMember(general.ID, bob.ID)
+	_ = rooms.AddMember(secret.ID, bob.ID)
+
+	_ = messages.Create(&Message{Content: []byte("deploying the release tonight"), SenderID: bob.ID, RoomID: general.ID})
+	_ = messages.Create(&Message{Content: []byte("lunch anyone?"), SenderID: alice.ID, RoomID: general.ID})
+	_ = messages.Create(&Message{Content: []byte("the release is delayed"), SenderID: bob.ID, RoomID: secret.ID})
+
+	hits, err := messages.Search(MessageSearch{Query: "releases", UserID: alice.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].RoomID != general.ID || hits[0].Sender.Name != "bob" {
+		t.Errorf("unexpected hit %+v", hits[0])
+	}
+
+	hits, err = messages.Search(MessageSearch{Query: "release", UserID: alice.ID, Author: "alice", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Errorf("expected no hits by alice, got %d", len(hits))
+	}
+}
+
+func TestMessageRepository_Threads(t *testing.T) {
+	truncate(t)
+	u := createUser(t, "alice", HashAPIKey("k1"))
+	r := createRoom(t, "general")
+	messages := NewMessageRepository(testDB)
+
+	parent := &Message{Content: []byte("anyone for lunch?"), SenderID: u.ID, RoomID: r.ID}
+	_ = messages.Create(parent)
+	_ = messages.Create(&Message{Content: []byte("me"), SenderID: u.ID, RoomID: r.ID, ParentID: &parent.ID})
+	_ = messages.Create(&Message{Content: []byte("me too"), SenderID: u.ID, RoomID: r.ID, ParentID: &parent.ID})
+
+	page, err := messages.GetByRoomBefore(r.ID, uuid.Nil, 10)
+	if err != nil {
+		t.Fatalf("GetByRoomBefore: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != parent.ID {
+		t.Fatalf("expected only the parent in history, got %d messages", len(page))
+	}
+
+	replies, err := messages.GetReplies(parent.ID)
+	if err != nil {
+		t.Fatalf("GetReplies: %v", err)
+	}
+	if len(replies) != 2 || string(replies[0].Content) != "me" {
+		t.Fatalf("unexpected replies %+v", replies)
+	}
+
+	summaries, err := messages.ThreadSummaries([]uuid.UUID{parent.ID})
+	if err != nil {
+		t.Fatalf("ThreadSummaries: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].ReplyCount != 2 {
+		t.Fatalf("unexpected summaries %+v", summaries)
+	}
+	if !summaries[0].LastReplyAt.Equal(replies[1].CreatedAt) {
+		t.Errorf("expected last reply at %v, got %v", replies[1].CreatedAt, summaries[0].LastReplyAt)
+	}
+}
+
+func TestMessageRepository_Reactions(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	r := createRoom(t, "general")
+	messages := NewMessageRepository(testDB)
+
+	msg := &Message{Content: []byte("shipped!"), SenderID: alice.ID, RoomID: r.ID}
+	_ = messages.Create(msg)
+
+	if added, err := messages.AddReaction(msg.ID, alice.ID, "🎉"); err != nil || !added {
+		t.Fatalf("AddReaction: added=%v err=%v", added, err)
+	}
+	if added, err := messages.AddReaction(msg.ID, alice.ID, "🎉"); err != nil || added {
+		t.Fatalf("expected duplicate reaction to be ignored, added=%v err=%v", added, err)
+	}
+	_, _ = messages.AddReaction(msg.ID, bob.ID, "🎉")
+	_, _ = messages.AddReaction(msg.ID, bob.ID, "👍")
+
+	counts, err := messages.ReactionCounts([]uuid.UUID{msg.ID}, alice.ID)
+	if err != nil {
+		t.Fatalf("ReactionCounts: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("expected 2 emoji, got %+v", counts)
+	}
+	if counts[0].Emoji != "🎉" || counts[0].Count != 2 || !counts[0].Mine {
+		t.Errorf("unexpected first count %+v", counts[0])
+	}
+	if counts[1].Emoji != "👍" || counts[1].Count != 1 || counts[1].Mine {
+		t.Errorf("unexpected second count %+v", counts[1])
+	}
+
+	if removed, err := messages.RemoveReaction(msg.ID, bob.ID, "👍"); err != nil || !removed {
+		t.Fatalf("RemoveReaction: removed=%v err=%v", removed, err)
+	}
+	counts, _ = messages.ReactionCounts([]uuid.UUID{msg.ID}, alice.ID)
+	if len(counts) != 1 {
+		t.Errorf("expected 1 emoji after removal, got %+v", counts)
+	}
+}
+
+func TestMessageRepository_MentionsOf(t *testing.T) {
+	truncate(t)
+	alice := createUser(t, "alice", HashAPIKey("k1"))
+	bob := createUser(t, "bob", HashAPIKey("k2"))
+	general := createRoom(t, "general")
+	left := createRoom(t, "left")
+	rooms := NewRoomRepository(testDB)
+	messages := NewMessageRepository(testDB)
+
+	_ = rooms.AddMember(general.ID, alice.ID)
+	_ = rooms.AddMember(general.ID, bob.ID)
+	_ = rooms.AddMember(left.ID, alice.ID)
+
+	var mentions []*Message
+	for _, room := range []*Room{general, general, left, general} {
+		msg := &Message{Content: []byte("@alice look"), SenderID: bob.ID, RoomID: room.ID, Mentions: []Mention{{UserID: alice.ID}}}
+		if err := messages.Create(msg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		mentions = append(mentions, msg)
+	}
+	_ = messages.Create(&Message{Content: []byte("no mention"), SenderID: bob.ID, RoomID: general.ID})
+	_ = messages.Delete(mentions[3].ID)
+	_ = rooms.RemoveMember(left.ID, alice.ID)
+
+	got, err := messages.MentionsOf(alice.ID, uuid.Nil, 10)
+	if err != nil {
+		t.Fatalf("MentionsOf: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != mentions[1].ID || got[1].ID != mentions[0].ID {
+		t.Fatalf("expected the two live mentions in general, newest first, got %+v", got)
+	}
+	if got[0].Sender.Name != "bob" || got[0].Room.Name != "general" {
+		t.Errorf("expected sender and room to be loaded, got %+v", got[0])
+	}
+
+	older, err := messages.MentionsOf(alice.ID, mentions[1].ID, 10)
+	if err != nil || len(older) != 1 || older[0].ID != mentions[0].ID {
+		t.Errorf("MentionsOf before the newest = %+v, %v", older, err)
+	}
+	if none, _ := messages.MentionsOf(bob.ID, uuid.Nil, 10); len(none) != 0 {
+		t.Errorf("expected no mentions of bob, got %d", len(none))
+	}
+}
+
+// ── WebhookRepository ─────────────────────────────────────────────────────────
+
+func TestWebhookRepository_IncomingGetsItsOwnUser(t *testing.T) {
+	truncate(t)
+	owner := createUser(t, "alice", HashAPIKey("k1"))
+	room := createRoom(t, "general")
+	hooks := NewWebhookRepository(testDB)
+
+	token := HashAPIKey("secret-token")
+	hook := &Webhook{RoomID: room.ID, Kind: WebhookKindIncoming, Name: "ci", TokenHash: &token, CreatedByID: owner.ID}
+	if err := hooks.Create(hook); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if hook.UserID == nil {
+		t.Fatal("expected an incoming webhook to be given a user")
+	}
+	user, err := NewUserRepository(testDB).GetByID(*hook.UserID)
+	if err != nil || user.Name != "ci" {
+		t.Errorf("webhook user = %+v, %v", user, err)
+	}
+
+	got, err := hooks.GetByToken("secret-token")
+	if err != nil || got.ID != hook.ID {
+		t.Errorf("GetByToken = %+v, %v", got, err)
+	}
+	if _, err := hooks.GetByToken("wrong"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound for an unknown token, got %v", err)
+	}
+}
+
+func TestWebhookRepository_IncomingRejectsTakenName(t *testing.T) {
+	truncate(t)
+	owner := createUser(t, "alice", HashAPIKey("k1"))
+	room := createRoom(t, "general")
+	hooks := NewWebhookRepository(testDB)
+
+	token := HashAPIKey("secret-token")
+	hook := &Webhook{RoomID: room.ID, Kind: WebhookKindIncoming, Name: "alice", TokenHash: &token, CreatedByID: owner.ID}
+	if err := hooks.Create(hook); !errors.Is(err, ErrNameTaken) {
+		t.Fatalf("expected ErrNameTaken, got %v", err)
+	}
+	var users int64
+	testDB.Model(&User{}).Where("name = ?", "alice").Count(&users)
+	if users != 1 {
+		t.Errorf("expected one user named alice, got %d", users)
+	}
+	if _, err := hooks.GetByToken("secret-token"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected no webhook to be stored, got %v", err)
+	}
+}
+
+func TestWebhookRepository_OutgoingAndDeliveries(t *testing.T) {
+	truncate(t)
+	owner := createUser(t, "alice", HashAPIKey("k1"))
+	room := createRoom(t, "general")
+	other := createRoom(t, "other")
+	hooks := NewWebhookRepository(testDB)
+
+	out := &Webhook{RoomID: room.ID, Kind: WebhookKindOutgoing, Name: "archive", URL: "https://example.com/hook", Secret: "s", CreatedByID: owner.ID}
+	gone := &Webhook{RoomID: room.ID, Kind: WebhookKindOutgoing, Name: "old", URL: "https://example.com/old", Secret: "s", CreatedByID: owner.ID}
+	elsewhere := &Webhook{RoomID: other.ID, Kind: WebhookKindOutgoing, Name: "x", URL: "https://example.com/x", Secret: "s", CreatedByID: owner.ID}
+	for _, hook := range []*Webhook{out, gone, elsewhere} {
+		if err := hooks.Create(hook); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if out.UserID != nil {
+		t.Error("expected outgoing webhooks not to be given a user")
+	}
+	_ = hooks.Delete(gone.ID)
+
+	got, err := hooks.ListOutgoing(room.ID)
+	if err != nil || len(got) != 1 || got[0].ID != out.ID {
+		t.Fatalf("ListOutgoing = %+v, %v", got, err)
+	}
+
+	messageID := uuid.New()
+	for attempt := 1; attempt <= 3; attempt++ {
+		d := &WebhookDelivery{WebhookID: out.ID, MessageID: messageID, Attempt: attempt, StatusCode: 500}
+		if err := hooks.RecordDelivery(d); err != nil {
+			t.Fatalf("RecordDelivery: %v", err)
+		}
+	}
+	deliveries, err := hooks.Deliveries(out.ID, 2)
+	if err != nil || len(deliveries) != 2 || deliveries[0].Attempt != 3 || deliveries[1].Attempt != 2 {
+		t.Errorf("expected the two newest attempts, newest first, got %+v, %v", deliveries, err)
+	}
+}
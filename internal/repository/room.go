@@ -1,14 +1,117 @@
 package repository
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/limits"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Room visibilities. Public rooms are listed for everyone and can be joined
+// freely; invite-only rooms are listed but require an invitation; private
+// rooms are only visible to their members.
+const (
+	RoomVisibilityPublic     = "public"
+	RoomVisibilityInviteOnly = "invite_only"
+	RoomVisibilityPrivate    = "private"
+)
+
+// Room kinds. Direct message rooms are private rooms whose membership is
+// fixed by their participants and identified by DMKey.
+const (
+	RoomKindRoom = "room"
+	RoomKindDM   = "dm"
 )
 
+// Member roles, from most to least privileged.
+const (
+	RoleOwner     = "owner"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
+)
+
+// ErrMessagesExist is returned by Import when a message being imported is
+// already stored, for instance because the export was imported before.
+var ErrMessagesExist = errors.New("messages already exist")
+
 type Room struct {
 	BaseModel
-	Name    string
-	Members []User `gorm:"many2many:room_members;"`
+	Name       string
+	Topic      string
+	OwnerID    *uuid.UUID `gorm:"type:uuid"`
+	Visibility string     `gorm:"not null;default:public"`
+	Kind       string     `gorm:"not null;default:room"`
+	DMKey      *string    `gorm:"uniqueIndex"`
+	// Encrypted rooms only accept end-to-end encrypted messages. It is set
+	// when the room is created and never changes.
+	Encrypted bool `gorm:"not null;default:false"`
+	// Retention is the room's own limit on how long messages are kept. The
+	// server's limit applies as well, wherever it is stricter.
+	Retention Retention `gorm:"embedded;embeddedPrefix:retention_"`
+	Members   []User    `gorm:"many2many:room_members;"`
+}
+
+// Retention limits how long messages are kept: for MaxAgeDays days, and only
+// the newest MaxMessages of them. A zero field sets no limit.
+type Retention struct {
+	MaxAgeDays  int `gorm:"not null;default:0"`
+	MaxMessages int `gorm:"not null;default:0"`
+}
+
+// IsZero reports whether r keeps messages forever.
+func (r Retention) IsZero() bool {
+	return r.MaxAgeDays == 0 && r.MaxMessages == 0
+}
+
+// Stricter combines r with other, taking the stricter limit of each.
+func (r Retention) Stricter(other Retention) Retention {
+	return Retention{
+		MaxAgeDays:  stricterLimit(r.MaxAgeDays, other.MaxAgeDays),
+		MaxMessages: stricterLimit(r.MaxMessages, other.MaxMessages),
+	}
+}
+
+func stricterLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// DMKey identifies the direct message room between a set of users regardless
+// of the order they are given in.
+func DMKey(userIDs []uuid.UUID) string {
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	slices.Sort(ids)
+	return strings.Join(slices.Compact(ids), ",")
+}
+
+// RoomMember is the room_members join table behind Room.Members, extended with
+// the member's role and how far through the room they have read.
+type RoomMember struct {
+	RoomID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Role              string     `gorm:"not null;default:member"`
+	LastReadMessageID *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt         time.Time
+	User              User `gorm:"foreignKey:UserID"`
+}
+
+// UnreadCount is the number of messages in a room a member has not read.
+type UnreadCount struct {
+	RoomID uuid.UUID
+	Count  int
 }
 
 type RoomRepository struct {
@@ -23,6 +126,139 @@ func (r *RoomRepository) Create(room *Room) error {
 	return r.db.Create(room).Error
 }
 
+// CreateWithOwner creates the room and adds ownerID to it as its owner in a
+// single transaction.
+func (r *RoomRepository) CreateWithOwner(room *Room, ownerID uuid.UUID) error {
+	room.OwnerID = &ownerID
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		return tx.Create(&RoomMember{RoomID: room.ID, UserID: ownerID, Role: RoleOwner}).Error
+	})
+}
+
+// CreateDM creates a private direct message room for userIDs, all of whom
+// become ordinary members. It fails on the DMKey unique index if the room
+// already exists.
+func (r *RoomRepository) CreateDM(userIDs []uuid.UUID) (*Room, error) {
+	key := DMKey(userIDs)
+	room := &Room{
+		Kind:       RoomKindDM,
+		Visibility: RoomVisibilityPrivate,
+		DMKey:      &key,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if err := tx.Create(&RoomMember{RoomID: room.ID, UserID: userID, Role: RoleMember}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return room, err
+}
+
+// Import creates room, owned by ownerID, holding messages exported from
+// another room, keeping their IDs and timestamps. authors are created as users
+// who cannot sign in; one whose ID is taken is given a new one, and one whose
+// name is taken has it suffixed. It fails with ErrMessagesExist, creating
+// nothing, if any of the messages is already stored.
+func (r *RoomRepository) Import(room *Room, ownerID uuid.UUID, authors []User, messages []Message) error {
+	ids := make([]uuid.UUID, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+
+	room.OwnerID = &ownerID
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for chunk := range slices.Chunk(ids, importBatchSize) {
+			var existing int64
+			if err := tx.Unscoped().Model(&Message{}).Where("id IN ?", chunk).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing > 0 {
+				return ErrMessagesExist
+			}
+		}
+
+		senders := make(map[uuid.UUID]uuid.UUID, len(authors))
+		for _, author := range authors {
+			exported := author.ID
+			user, err := placeholderUser(tx, author)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			senders[exported] = user.ID
+		}
+
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&RoomMember{RoomID: room.ID, UserID: ownerID, Role: RoleOwner}).Error; err != nil {
+			return err
+		}
+
+		for i := range messages {
+			messages[i].RoomID = room.ID
+			if id, ok := senders[messages[i].SenderID]; ok {
+				messages[i].SenderID = id
+			}
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(messages, importBatchSize).Error
+	})
+}
+
+// importBatchSize bounds the rows Import checks or inserts per statement.
+const importBatchSize = 500
+
+// placeholderUser makes an imported message author into a user who cannot
+// sign in, keeping their ID and name unless another user has them.
+func placeholderUser(tx *gorm.DB, author User) (*User, error) {
+	unusable := make([]byte, 32)
+	if _, err := rand.Read(unusable); err != nil {
+		return nil, err
+	}
+	user := &User{Name: author.Name, APIKey: HashAPIKey(hex.EncodeToString(unusable))}
+
+	var n int64
+	if err := tx.Unscoped().Model(&User{}).Where("id = ?", author.ID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		user.ID = author.ID
+	}
+
+	base := []rune(author.Name)
+	for i := 2; ; i++ {
+		if err := tx.Model(&User{}).Where("name = ?", user.Name).Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return user, nil
+		}
+		suffix := []rune("-" + strconv.Itoa(i))
+		user.Name = string(base[:min(len(base), limits.MaxUserNameLength-len(suffix))]) + string(suffix)
+	}
+}
+
+// GetByDMKey returns gorm.ErrRecordNotFound if no direct message room exists
+// for the key.
+func (r *RoomRepository) GetByDMKey(key string) (*Room, error) {
+	var room Room
+	err := r.db.Preload("Members").First(&room, "dm_key = ?", key).Error
+	return &room, err
+}
+
 func (r *RoomRepository) GetByID(id uuid.UUID) (*Room, error) {
 	var room Room
 	err := r.db.Preload("Members").First(&room, "id = ?", id).Error
@@ -39,6 +275,31 @@ func (r *RoomRepository) List(limit, offset int) ([]Room, error) {
 	return rooms, err
 }
 
+// ListVisible lists the rooms userID can see: every non-private room plus the
+// private rooms they are a member of.
+func (r *RoomRepository) ListVisible(userID uuid.UUID, limit, offset int) ([]Room, error) {
+	var rooms []Room
+	err := r.db.Preload("Members").
+		Where("visibility <> ? OR id IN (SELECT room_id FROM room_members WHERE user_id = ?)", RoomVisibilityPrivate, userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&rooms).Error
+	return rooms, err
+}
+
+// ListRetained returns the rooms with a retention policy of their own, or
+// every room if all is set, loading only their IDs and retention.
+func (r *RoomRepository) ListRetained(all bool) ([]Room, error) {
+	var rooms []Room
+	query := r.db.Select("id", "retention_max_age_days", "retention_max_messages")
+	if !all {
+		query = query.Where("retention_max_age_days > 0 OR retention_max_messages > 0")
+	}
+	err := query.Order("id ASC").Find(&rooms).Error
+	return rooms, err
+}
+
 func (r *RoomRepository) Update(room *Room) error {
 	return r.db.Save(room).Error
 }
@@ -48,9 +309,78 @@ func (r *RoomRepository) Delete(id uuid.UUID) error {
 }
 
 func (r *RoomRepository) AddMember(roomID, userID uuid.UUID) error {
-	return r.db.Exec("INSERT INTO room_members (room_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", roomID, userID).Error
+	return r.AddMemberWithRole(roomID, userID, RoleMember)
+}
+
+// AddMemberWithRole is a no-op if userID is already a member; it does not
+// change an existing member's role.
+func (r *RoomRepository) AddMemberWithRole(roomID, userID uuid.UUID, role string) error {
+	member := RoomMember{RoomID: roomID, UserID: userID, Role: role}
+	return r.db.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&member).Error
 }
 
 func (r *RoomRepository) RemoveMember(roomID, userID uuid.UUID) error {
 	return r.db.Exec("DELETE FROM room_members WHERE room_id = ? AND user_id = ?", roomID, userID).Error
 }
+
+// GetMember returns gorm.ErrRecordNotFound if userID is not a member of the
+// room.
+func (r *RoomRepository) GetMember(roomID, userID uuid.UUID) (*RoomMember, error) {
+	var member RoomMember
+	err := r.db.First(&member, "room_id = ? AND user_id = ?", roomID, userID).Error
+	return &member, err
+}
+
+func (r *RoomRepository) ListMembers(roomID uuid.UUID) ([]RoomMember, error) {
+	var members []RoomMember
+	err := r.db.Preload("User").
+		Where("room_id = ?", roomID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *RoomRepository) SetMemberRole(roomID, userID uuid.UUID, role string) error {
+	return r.db.Model(&RoomMember{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+func (r *RoomRepository) SetTopic(roomID uuid.UUID, topic string) error {
+	return r.db.Model(&Room{}).Where("id = ?", roomID).Update("topic", topic).Error
+}
+
+func (r *RoomRepository) SetRetention(roomID uuid.UUID, retention Retention) error {
+	return r.db.Model(&Room{}).Where("id = ?", roomID).Updates(map[string]any{
+		"retention_max_age_days": retention.MaxAgeDays,
+		"retention_max_messages": retention.MaxMessages,
+	}).Error
+}
+
+// MarkRead moves the member's read marker forward to messageID. Markers never
+// move backwards, so acknowledging an older message is a no-op.
+func (r *RoomRepository) MarkRead(roomID, userID, messageID uuid.UUID) error {
+	return r.db.Model(&RoomMember{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Where("last_read_message_id IS NULL OR last_read_message_id < ?", messageID).
+		Update("last_read_message_id", messageID).Error
+}
+
+// UnreadCounts returns, for every room userID is a member of, how many
+// messages from other users arrived after their read marker. Message IDs are
+// UUIDv7, so comparing them orders messages by creation time.
+func (r *RoomRepository) UnreadCounts(userID uuid.UUID) ([]UnreadCount, error) {
+	var counts []UnreadCount
+	err := r.db.Table("room_members AS rm").
+		Select("rm.room_id, COUNT(m.id) AS count").
+		Joins(`LEFT JOIN messages m ON m.room_id = rm.room_id
+			AND m.deleted_at IS NULL
+			AND m.sender_id <> rm.user_id
+			AND (rm.last_read_message_id IS NULL OR m.id > rm.last_read_message_id)`).
+		Where("rm.user_id = ?", userID).
+		Group("rm.room_id").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -13,11 +15,56 @@ func HashAPIKey(key string) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// User kinds. Bots are created by a human user, who owns them, and their API
+// keys only allow what their scopes grant.
+const (
+	UserKindHuman = "human"
+	UserKindBot   = "bot"
+)
+
+// Scopes a bot's API key can be granted.
+const (
+	// ScopeRoomsRead lists rooms, their members and their webhooks.
+	ScopeRoomsRead = "rooms:read"
+	// ScopeRoomsWrite creates and manages rooms, DMs and webhooks.
+	ScopeRoomsWrite = "rooms:write"
+	// ScopeMessagesRead connects to the WebSocket and reads history,
+	// search, mentions and attachments.
+	ScopeMessagesRead = "messages:read"
+	// ScopeMessagesWrite sends, edits, deletes and reacts to messages, and
+	// uploads attachments.
+	ScopeMessagesWrite = "messages:write"
+	// ScopeProfileWrite renames the bot and publishes its public key.
+	ScopeProfileWrite = "profile:write"
+)
+
+// Scopes is every scope, in the order they are documented.
+var Scopes = []string{ScopeRoomsRead, ScopeRoomsWrite, ScopeMessagesRead, ScopeMessagesWrite, ScopeProfileWrite}
+
 type User struct {
 	BaseModel
 	Name   string
 	APIKey string `gorm:"uniqueIndex"`
-	Rooms  []Room `gorm:"many2many:room_members;"`
+	Kind   string `gorm:"not null;default:human"`
+	// Scopes is the space-separated scopes granted to a bot. Human users
+	// may do everything, whatever it holds.
+	Scopes string
+	// OwnerID is the human user who created a bot.
+	OwnerID *uuid.UUID `gorm:"type:uuid;index"`
+	// PublicKey is the user's base64 X25519 key for encrypted rooms, empty
+	// until their client publishes one.
+	PublicKey string
+	Rooms     []Room `gorm:"many2many:room_members;"`
+}
+
+// IsBot reports whether the user is a bot.
+func (u *User) IsBot() bool {
+	return u.Kind == UserKindBot
+}
+
+// HasScope reports whether the user may do what scope grants.
+func (u *User) HasScope(scope string) bool {
+	return !u.IsBot() || slices.Contains(strings.Fields(u.Scopes), scope)
 }
 
 type UserRepository struct {
@@ -44,6 +91,13 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*User, error) {
 	return &user, err
 }
 
+// GetByName returns the earliest registered user with the given name.
+func (r *UserRepository) GetByName(name string) (*User, error) {
+	var user User
+	err := r.db.Where("name = ?", name).Order("created_at ASC").First(&user).Error
+	return &user, err
+}
+
 func (r *UserRepository) List(limit, offset int) ([]User, error) {
 	var users []User
 	err := r.db.Order("created_at DESC").
@@ -53,10 +107,28 @@ func (r *UserRepository) List(limit, offset int) ([]User, error) {
 	return users, err
 }
 
+// ListBots returns the bots ownerID created, oldest first.
+func (r *UserRepository) ListBots(ownerID uuid.UUID) ([]User, error) {
+	var bots []User
+	err := r.db.Where("kind = ? AND owner_id = ?", UserKindBot, ownerID).
+		Order("created_at ASC").
+		Find(&bots).Error
+	return bots, err
+}
+
 func (r *UserRepository) Update(user *User) error {
 	return r.db.Save(user).Error
 }
 
+func (r *UserRepository) UpdateName(id uuid.UUID, name string) error {
+	return r.db.Model(&User{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// SetPublicKey publishes the user's key for encrypted rooms.
+func (r *UserRepository) SetPublicKey(id uuid.UUID, publicKey string) error {
+	return r.db.Model(&User{}).Where("id = ?", id).Update("public_key", publicKey).Error
+}
+
 func (r *UserRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&User{}, "id = ?", id).Error
 }
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook kinds. Incoming webhooks accept messages posted to a secret URL;
+// outgoing webhooks POST a room's new messages to a URL of the owner's
+// choosing.
+const (
+	WebhookKindIncoming = "incoming"
+	WebhookKindOutgoing = "outgoing"
+)
+
+// ErrNameTaken is returned by Create when an incoming webhook is named after
+// an existing user, whose name its messages would otherwise be sent under.
+var ErrNameTaken = errors.New("name is already taken")
+
+type Webhook struct {
+	BaseModel
+	RoomID uuid.UUID `gorm:"type:uuid;index"`
+	Kind   string    `gorm:"not null"`
+	Name   string
+	// TokenHash is the hash of the token in an incoming webhook's URL.
+	TokenHash *string `gorm:"uniqueIndex"`
+	// UserID is who messages posted to an incoming webhook are sent as: a
+	// user of the webhook's name created along with it.
+	UserID *uuid.UUID `gorm:"type:uuid"`
+	// URL and Secret are set on outgoing webhooks. Deliveries are signed with
+	// Secret, so it is kept as it was issued.
+	URL         string
+	Secret      string
+	CreatedByID uuid.UUID `gorm:"type:uuid"`
+}
+
+// WebhookDelivery records one attempt to deliver a message to an outgoing
+// webhook. A failed attempt is retried as a new delivery.
+type WebhookDelivery struct {
+	BaseModel
+	WebhookID  uuid.UUID `gorm:"type:uuid;index"`
+	MessageID  uuid.UUID `gorm:"type:uuid"`
+	Attempt    int
+	StatusCode int
+	Error      string
+	DurationMS int64
+	Succeeded  bool
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create stores a webhook. An incoming webhook is given a user of its own to
+// send as, which cannot be signed in as, in the same transaction. It fails
+// with ErrNameTaken if a user already has the webhook's name.
+func (r *WebhookRepository) Create(hook *Webhook) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if hook.Kind == WebhookKindIncoming && hook.UserID == nil {
+			var taken int64
+			if err := tx.Model(&User{}).Where("name = ?", hook.Name).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return ErrNameTaken
+			}
+			unusable := make([]byte, 32)
+			if _, err := rand.Read(unusable); err != nil {
+				return err
+			}
+			user := &User{Name: hook.Name, APIKey: HashAPIKey(hex.EncodeToString(unusable))}
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			hook.UserID = &user.ID
+		}
+		return tx.Create(hook).Error
+	})
+}
+
+func (r *WebhookRepository) GetByID(id uuid.UUID) (*Webhook, error) {
+	var hook Webhook
+	err := r.db.First(&hook, "id = ?", id).Error
+	return &hook, err
+}
+
+// GetByToken returns the incoming webhook whose URL carries token, or
+// gorm.ErrRecordNotFound.
+func (r *WebhookRepository) GetByToken(token string) (*Webhook, error) {
+	var hook Webhook
+	err := r.db.First(&hook, "kind = ? AND token_hash = ?", WebhookKindIncoming, HashAPIKey(token)).Error
+	return &hook, err
+}
+
+// ListByRoom returns a room's webhooks of both kinds, oldest first.
+func (r *WebhookRepository) ListByRoom(roomID uuid.UUID) ([]Webhook, error) {
+	var hooks []Webhook
+	err := r.db.Where("room_id = ?", roomID).Order("id ASC").Find(&hooks).Error
+	return hooks, err
+}
+
+// ListOutgoing returns the outgoing webhooks a room's messages are sent to.
+func (r *WebhookRepository) ListOutgoing(roomID uuid.UUID) ([]Webhook, error) {
+	var hooks []Webhook
+	err := r.db.Where("room_id = ? AND kind = ?", roomID, WebhookKindOutgoing).Order("id ASC").Find(&hooks).Error
+	return hooks, err
+}
+
+func (r *WebhookRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&Webhook{}, "id = ?", id).Error
+}
+
+func (r *WebhookRepository) RecordDelivery(delivery *WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// Deliveries returns the newest delivery attempts to a webhook, newest first.
+func (r *WebhookRepository) Deliveries(webhookID uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := r.db.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
@@ -0,0 +1,132 @@
+// Package retention prunes messages that have outlived their room's retention
+// policy or the server's, whichever is stricter. A Janitor runs in the
+// background, deleting expired messages a batch at a time so that no single
+// statement holds the messages table for long.
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/blob"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+)
+
+// defaultBatchSize is used when Config.BatchSize is not set.
+const defaultBatchSize = 1000
+
+type RoomStore interface {
+	ListRetained(all bool) ([]repository.Room, error)
+}
+
+type MessageStore interface {
+	Prune(roomID uuid.UUID, before time.Time, keep, limit int, hard bool) ([]repository.Message, error)
+}
+
+type Config struct {
+	// Server is the server-wide policy, applied to every room along with the
+	// room's own.
+	Server repository.Retention
+	// Interval is the time between passes over the rooms.
+	Interval time.Duration
+	// BatchSize is how many messages are deleted at a time. It defaults to
+	// 1000.
+	BatchSize int
+	// HardDelete removes pruned messages for good, along with the contents
+	// of their attachments, rather than soft-deleting them.
+	HardDelete bool
+}
+
+// Janitor periodically prunes expired messages. Janitors on different nodes do
+// not coordinate, so only one node sharing a database should run one.
+type Janitor struct {
+	rooms    RoomStore
+	messages MessageStore
+	blobs    blob.Store
+	cfg      Config
+	now      func() time.Time
+}
+
+// NewJanitor returns a janitor for cfg. blobs is only used with
+// Config.HardDelete, and may otherwise be nil.
+func NewJanitor(rooms RoomStore, messages MessageStore, blobs blob.Store, cfg Config) *Janitor {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	return &Janitor{rooms: rooms, messages: messages, blobs: blobs, cfg: cfg, now: time.Now}
+}
+
+// Run prunes expired messages straight away and then every Config.Interval
+// until ctx is done.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if n, err := j.Prune(ctx); err != nil {
+			slog.Error("failed to prune messages", "error", err, "pruned", n)
+		} else if n > 0 {
+			slog.Info("pruned expired messages", "pruned", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune makes one pass over the rooms with a retention policy, deleting their
+// expired messages. It returns how many it deleted, stopping at the first
+// error or when ctx is done.
+func (j *Janitor) Prune(ctx context.Context) (int, error) {
+	rooms, err := j.rooms.ListRetained(!j.cfg.Server.IsZero())
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, room := range rooms {
+		policy := room.Retention.Stricter(j.cfg.Server)
+		var before time.Time
+		if policy.MaxAgeDays > 0 {
+			before = j.now().AddDate(0, 0, -policy.MaxAgeDays)
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				return pruned, err
+			}
+			expired, err := j.messages.Prune(room.ID, before, policy.MaxMessages, j.cfg.BatchSize, j.cfg.HardDelete)
+			if err != nil {
+				return pruned, err
+			}
+			pruned += len(expired)
+			if j.cfg.HardDelete {
+				j.deleteAttachments(ctx, expired)
+			}
+			if len(expired) < j.cfg.BatchSize {
+				break
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// deleteAttachments removes the contents of the files among messages. A blob
+// that cannot be deleted is left behind and logged, as its message is gone.
+func (j *Janitor) deleteAttachments(ctx context.Context, messages []repository.Message) {
+	if j.blobs == nil {
+		return
+	}
+	for _, m := range messages {
+		if m.Attachment.Key == "" {
+			continue
+		}
+		if err := j.blobs.Delete(ctx, m.Attachment.Key); err != nil {
+			slog.Warn("failed to delete pruned attachment", "error", err, "message_id", m.ID)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package retention
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pruneCall struct {
+	roomID uuid.UUID
+	before time.Time
+	keep   int
+	hard   bool
+}
+
+// fakeStore holds a number of expired messages per room, handing them out a
+// batch at a time.
+type fakeStore struct {
+	rooms   []repository.Room
+	all     bool
+	expired map[uuid.UUID]int
+	calls   []pruneCall
+}
+
+func (s *fakeStore) ListRetained(all bool) ([]repository.Room, error) {
+	s.all = all
+	return s.rooms, nil
+}
+
+func (s *fakeStore) Prune(roomID uuid.UUID, before time.Time, keep, limit int, hard bool) ([]repository.Message, error) {
+	s.calls = append(s.calls, pruneCall{roomID: roomID, before: before, keep: keep, hard: hard})
+	n := min(s.expired[roomID], limit)
+	s.expired[roomID] -= n
+	messages := make([]repository.Message, n)
+	for i := range messages {
+		messages[i].ID = uuid.New()
+		messages[i].Attachment.Key = "blob-" + messages[i].ID.String()
+	}
+	return messages, nil
+}
+
+type fakeBlobs struct {
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (b *fakeBlobs) Put(context.Context, string, io.Reader) error { return nil }
+
+func (b *fakeBlobs) Get(context.Context, string) (io.ReadCloser, error) { return nil, nil }
+
+func (b *fakeBlobs) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	b.deleted = append(b.deleted, key)
+	b.mu.Unlock()
+	return nil
+}
+
+func TestJanitor_Prune(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	strict := repository.Room{BaseModel: repository.BaseModel{ID: uuid.New()}, Retention: repository.Retention{MaxAgeDays: 7}}
+	loose := repository.Room{BaseModel: repository.BaseModel{ID: uuid.New()}, Retention: repository.Retention{MaxAgeDays: 90, MaxMessages: 500}}
+	store := &fakeStore{
+		rooms:   []repository.Room{strict, loose},
+		expired: map[uuid.UUID]int{strict.ID: 5, loose.ID: 1},
+	}
+
+	j := NewJanitor(store, store, nil, Config{Server: repository.Retention{MaxAgeDays: 30}, BatchSize: 2})
+	j.now = func() time.Time { return now }
+
+	pruned, err := j.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 6, pruned)
+	assert.True(t, store.all, "a server policy should apply to every room")
+
+	// Five expired messages in batches of two take three calls.
+	require.Len(t, store.calls, 4)
+	assert.Equal(t, pruneCall{roomID: strict.ID, before: now.AddDate(0, 0, -7)}, store.calls[0])
+	assert.Equal(t, strict.ID, store.calls[2].roomID)
+	assert.Equal(t, pruneCall{roomID: loose.ID, before: now.AddDate(0, 0, -30), keep: 500}, store.calls[3])
+}
+
+func TestJanitor_Prune_OnlyRoomPolicies(t *testing.T) {
+	store := &fakeStore{expired: map[uuid.UUID]int{}}
+
+	pruned, err := NewJanitor(store, store, nil, Config{}).Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+	assert.False(t, store.all, "without a server policy only rooms with their own should be listed")
+}
+
+func TestJanitor_Prune_HardDeletesAttachments(t *testing.T) {
+	room := repository.Room{BaseModel: repository.BaseModel{ID: uuid.New()}, Retention: repository.Retention{MaxMessages: 10}}
+	store := &fakeStore{rooms: []repository.Room{room}, expired: map[uuid.UUID]int{room.ID: 3}}
+	blobs := &fakeBlobs{}
+
+	pruned, err := NewJanitor(store, store, blobs, Config{HardDelete: true}).Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, pruned)
+	assert.True(t, store.calls[0].hard)
+	assert.Len(t, blobs.deleted, 3)
+}
+
+func TestJanitor_Prune_StopsWhenCancelled(t *testing.T) {
+	room := repository.Room{BaseModel: repository.BaseModel{ID: uuid.New()}, Retention: repository.Retention{MaxAgeDays: 1}}
+	store := &fakeStore{rooms: []repository.Room{room}, expired: map[uuid.UUID]int{room.ID: 3}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewJanitor(store, store, nil, Config{}).Prune(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, store.calls)
+}
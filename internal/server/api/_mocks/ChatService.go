@@ -7,6 +7,7 @@ package mocks
 import (
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/repository"
 	"github.com/EwanGreer/chatatui/internal/service"
 	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
@@ -39,36 +40,37 @@ func (_m *MockChatService) EXPECT() *MockChatService_Expecter {
 	return &MockChatService_Expecter{mock: &_m.Mock}
 }
 
-// AddRoomMember provides a mock function for the type MockChatService
-func (_mock *MockChatService) AddRoomMember(roomID uuid.UUID, userID uuid.UUID) error {
-	ret := _mock.Called(roomID, userID)
+// DeleteMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) DeleteMessage(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID) error {
+	ret := _mock.Called(id, senderID, roomID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AddRoomMember")
+		panic("no return value specified for DeleteMessage")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
-		r0 = returnFunc(roomID, userID)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(id, senderID, roomID)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockChatService_AddRoomMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddRoomMember'
-type MockChatService_AddRoomMember_Call struct {
+// MockChatService_DeleteMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessage'
+type MockChatService_DeleteMessage_Call struct {
 	*mock.Call
 }
 
-// AddRoomMember is a helper method to define mock.On call
+// DeleteMessage is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - senderID uuid.UUID
 //   - roomID uuid.UUID
-//   - userID uuid.UUID
-func (_e *MockChatService_Expecter) AddRoomMember(roomID interface{}, userID interface{}) *MockChatService_AddRoomMember_Call {
-	return &MockChatService_AddRoomMember_Call{Call: _e.mock.On("AddRoomMember", roomID, userID)}
+func (_e *MockChatService_Expecter) DeleteMessage(id interface{}, senderID interface{}, roomID interface{}) *MockChatService_DeleteMessage_Call {
+	return &MockChatService_DeleteMessage_Call{Call: _e.mock.On("DeleteMessage", id, senderID, roomID)}
 }
 
-func (_c *MockChatService_AddRoomMember_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID)) *MockChatService_AddRoomMember_Call {
+func (_c *MockChatService_DeleteMessage_Call) Run(run func(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID)) *MockChatService_DeleteMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 uuid.UUID
 		if args[0] != nil {
@@ -78,94 +80,329 @@ func (_c *MockChatService_AddRoomMember_Call) Run(run func(roomID uuid.UUID, use
 		if args[1] != nil {
 			arg1 = args[1].(uuid.UUID)
 		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockChatService_AddRoomMember_Call) Return(err error) *MockChatService_AddRoomMember_Call {
+func (_c *MockChatService_DeleteMessage_Call) Return(err error) *MockChatService_DeleteMessage_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockChatService_AddRoomMember_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID) error) *MockChatService_AddRoomMember_Call {
+func (_c *MockChatService_DeleteMessage_Call) RunAndReturn(run func(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID) error) *MockChatService_DeleteMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EditMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) EditMessage(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID, content []byte) (time.Time, error) {
+	ret := _mock.Called(id, senderID, roomID, content)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EditMessage")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, []byte) (time.Time, error)); ok {
+		return returnFunc(id, senderID, roomID, content)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, []byte) time.Time); ok {
+		r0 = returnFunc(id, senderID, roomID, content)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID, []byte) error); ok {
+		r1 = returnFunc(id, senderID, roomID, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_EditMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EditMessage'
+type MockChatService_EditMessage_Call struct {
+	*mock.Call
+}
+
+// EditMessage is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - senderID uuid.UUID
+//   - roomID uuid.UUID
+//   - content []byte
+func (_e *MockChatService_Expecter) EditMessage(id interface{}, senderID interface{}, roomID interface{}, content interface{}) *MockChatService_EditMessage_Call {
+	return &MockChatService_EditMessage_Call{Call: _e.mock.On("EditMessage", id, senderID, roomID, content)}
+}
+
+func (_c *MockChatService_EditMessage_Call) Run(run func(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID, content []byte)) *MockChatService_EditMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 []byte
+		if args[3] != nil {
+			arg3 = args[3].([]byte)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_EditMessage_Call) Return(time1 time.Time, err error) *MockChatService_EditMessage_Call {
+	_c.Call.Return(time1, err)
+	return _c
+}
+
+func (_c *MockChatService_EditMessage_Call) RunAndReturn(run func(id uuid.UUID, senderID uuid.UUID, roomID uuid.UUID, content []byte) (time.Time, error)) *MockChatService_EditMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetMessageHistory provides a mock function for the type MockChatService
-func (_mock *MockChatService) GetMessageHistory(roomID uuid.UUID, limit int, offset int) ([]service.MessageInfo, error) {
-	ret := _mock.Called(roomID, limit, offset)
+// ExportMessages provides a mock function for the type MockChatService
+func (_mock *MockChatService) ExportMessages(roomID uuid.UUID, viewerID uuid.UUID, after uuid.UUID, limit int) ([]service.MessageInfo, error) {
+	ret := _mock.Called(roomID, viewerID, after, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessageHistory")
+		panic("no return value specified for ExportMessages")
 	}
 
 	var r0 []service.MessageInfo
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, int, int) ([]service.MessageInfo, error)); ok {
-		return returnFunc(roomID, limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) ([]service.MessageInfo, error)); ok {
+		return returnFunc(roomID, viewerID, after, limit)
 	}
-	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, int, int) []service.MessageInfo); ok {
-		r0 = returnFunc(roomID, limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) []service.MessageInfo); ok {
+		r0 = returnFunc(roomID, viewerID, after, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]service.MessageInfo)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, int, int) error); ok {
-		r1 = returnFunc(roomID, limit, offset)
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(roomID, viewerID, after, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockChatService_GetMessageHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageHistory'
-type MockChatService_GetMessageHistory_Call struct {
+// MockChatService_ExportMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportMessages'
+type MockChatService_ExportMessages_Call struct {
 	*mock.Call
 }
 
-// GetMessageHistory is a helper method to define mock.On call
+// ExportMessages is a helper method to define mock.On call
 //   - roomID uuid.UUID
+//   - viewerID uuid.UUID
+//   - after uuid.UUID
 //   - limit int
-//   - offset int
-func (_e *MockChatService_Expecter) GetMessageHistory(roomID interface{}, limit interface{}, offset interface{}) *MockChatService_GetMessageHistory_Call {
-	return &MockChatService_GetMessageHistory_Call{Call: _e.mock.On("GetMessageHistory", roomID, limit, offset)}
+func (_e *MockChatService_Expecter) ExportMessages(roomID interface{}, viewerID interface{}, after interface{}, limit interface{}) *MockChatService_ExportMessages_Call {
+	return &MockChatService_ExportMessages_Call{Call: _e.mock.On("ExportMessages", roomID, viewerID, after, limit)}
 }
 
-func (_c *MockChatService_GetMessageHistory_Call) Run(run func(roomID uuid.UUID, limit int, offset int)) *MockChatService_GetMessageHistory_Call {
+func (_c *MockChatService_ExportMessages_Call) Run(run func(roomID uuid.UUID, viewerID uuid.UUID, after uuid.UUID, limit int)) *MockChatService_ExportMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 uuid.UUID
 		if args[0] != nil {
 			arg0 = args[0].(uuid.UUID)
 		}
-		var arg1 int
+		var arg1 uuid.UUID
 		if args[1] != nil {
-			arg1 = args[1].(int)
+			arg1 = args[1].(uuid.UUID)
 		}
-		var arg2 int
+		var arg2 uuid.UUID
 		if args[2] != nil {
-			arg2 = args[2].(int)
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockChatService_GetMessageHistory_Call) Return(messageInfos []service.MessageInfo, err error) *MockChatService_GetMessageHistory_Call {
+func (_c *MockChatService_ExportMessages_Call) Return(messageInfos []service.MessageInfo, err error) *MockChatService_ExportMessages_Call {
 	_c.Call.Return(messageInfos, err)
 	return _c
 }
 
-func (_c *MockChatService_GetMessageHistory_Call) RunAndReturn(run func(roomID uuid.UUID, limit int, offset int) ([]service.MessageInfo, error)) *MockChatService_GetMessageHistory_Call {
+func (_c *MockChatService_ExportMessages_Call) RunAndReturn(run func(roomID uuid.UUID, viewerID uuid.UUID, after uuid.UUID, limit int) ([]service.MessageInfo, error)) *MockChatService_ExportMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachment provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetAttachment(id uuid.UUID, viewerID uuid.UUID) (*service.MessageInfo, error) {
+	ret := _mock.Called(id, viewerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachment")
+	}
+
+	var r0 *service.MessageInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*service.MessageInfo, error)); ok {
+		return returnFunc(id, viewerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *service.MessageInfo); ok {
+		r0 = returnFunc(id, viewerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.MessageInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(id, viewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachment'
+type MockChatService_GetAttachment_Call struct {
+	*mock.Call
+}
+
+// GetAttachment is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - viewerID uuid.UUID
+func (_e *MockChatService_Expecter) GetAttachment(id interface{}, viewerID interface{}) *MockChatService_GetAttachment_Call {
+	return &MockChatService_GetAttachment_Call{Call: _e.mock.On("GetAttachment", id, viewerID)}
+}
+
+func (_c *MockChatService_GetAttachment_Call) Run(run func(id uuid.UUID, viewerID uuid.UUID)) *MockChatService_GetAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetAttachment_Call) Return(messageInfo *service.MessageInfo, err error) *MockChatService_GetAttachment_Call {
+	_c.Call.Return(messageInfo, err)
+	return _c
+}
+
+func (_c *MockChatService_GetAttachment_Call) RunAndReturn(run func(id uuid.UUID, viewerID uuid.UUID) (*service.MessageInfo, error)) *MockChatService_GetAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessagesBefore provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetMessagesBefore(roomID uuid.UUID, viewerID uuid.UUID, before uuid.UUID, limit int) (service.MessagePage, error) {
+	ret := _mock.Called(roomID, viewerID, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesBefore")
+	}
+
+	var r0 service.MessagePage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) (service.MessagePage, error)); ok {
+		return returnFunc(roomID, viewerID, before, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) service.MessagePage); ok {
+		r0 = returnFunc(roomID, viewerID, before, limit)
+	} else {
+		r0 = ret.Get(0).(service.MessagePage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(roomID, viewerID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_GetMessagesBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessagesBefore'
+type MockChatService_GetMessagesBefore_Call struct {
+	*mock.Call
+}
+
+// GetMessagesBefore is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - viewerID uuid.UUID
+//   - before uuid.UUID
+//   - limit int
+func (_e *MockChatService_Expecter) GetMessagesBefore(roomID interface{}, viewerID interface{}, before interface{}, limit interface{}) *MockChatService_GetMessagesBefore_Call {
+	return &MockChatService_GetMessagesBefore_Call{Call: _e.mock.On("GetMessagesBefore", roomID, viewerID, before, limit)}
+}
+
+func (_c *MockChatService_GetMessagesBefore_Call) Run(run func(roomID uuid.UUID, viewerID uuid.UUID, before uuid.UUID, limit int)) *MockChatService_GetMessagesBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_GetMessagesBefore_Call) Return(messagePage service.MessagePage, err error) *MockChatService_GetMessagesBefore_Call {
+	_c.Call.Return(messagePage, err)
+	return _c
+}
+
+func (_c *MockChatService_GetMessagesBefore_Call) RunAndReturn(run func(roomID uuid.UUID, viewerID uuid.UUID, before uuid.UUID, limit int) (service.MessagePage, error)) *MockChatService_GetMessagesBefore_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -232,58 +469,52 @@ func (_c *MockChatService_GetRoom_Call) RunAndReturn(run func(id uuid.UUID) (*se
 	return _c
 }
 
-// PersistMessage provides a mock function for the type MockChatService
-func (_mock *MockChatService) PersistMessage(content []byte, senderID uuid.UUID, roomID uuid.UUID) (uuid.UUID, time.Time, error) {
-	ret := _mock.Called(content, senderID, roomID)
+// GetThread provides a mock function for the type MockChatService
+func (_mock *MockChatService) GetThread(roomID uuid.UUID, viewerID uuid.UUID, parentID uuid.UUID) ([]service.MessageInfo, error) {
+	ret := _mock.Called(roomID, viewerID, parentID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PersistMessage")
+		panic("no return value specified for GetThread")
 	}
 
-	var r0 uuid.UUID
-	var r1 time.Time
-	var r2 error
-	if returnFunc, ok := ret.Get(0).(func([]byte, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, error)); ok {
-		return returnFunc(content, senderID, roomID)
+	var r0 []service.MessageInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) ([]service.MessageInfo, error)); ok {
+		return returnFunc(roomID, viewerID, parentID)
 	}
-	if returnFunc, ok := ret.Get(0).(func([]byte, uuid.UUID, uuid.UUID) uuid.UUID); ok {
-		r0 = returnFunc(content, senderID, roomID)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) []service.MessageInfo); ok {
+		r0 = returnFunc(roomID, viewerID, parentID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(uuid.UUID)
+			r0 = ret.Get(0).([]service.MessageInfo)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func([]byte, uuid.UUID, uuid.UUID) time.Time); ok {
-		r1 = returnFunc(content, senderID, roomID)
-	} else {
-		r1 = ret.Get(1).(time.Time)
-	}
-	if returnFunc, ok := ret.Get(2).(func([]byte, uuid.UUID, uuid.UUID) error); ok {
-		r2 = returnFunc(content, senderID, roomID)
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(roomID, viewerID, parentID)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
-	return r0, r1, r2
+	return r0, r1
 }
 
-// MockChatService_PersistMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PersistMessage'
-type MockChatService_PersistMessage_Call struct {
+// MockChatService_GetThread_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetThread'
+type MockChatService_GetThread_Call struct {
 	*mock.Call
 }
 
-// PersistMessage is a helper method to define mock.On call
-//   - content []byte
-//   - senderID uuid.UUID
+// GetThread is a helper method to define mock.On call
 //   - roomID uuid.UUID
-func (_e *MockChatService_Expecter) PersistMessage(content interface{}, senderID interface{}, roomID interface{}) *MockChatService_PersistMessage_Call {
-	return &MockChatService_PersistMessage_Call{Call: _e.mock.On("PersistMessage", content, senderID, roomID)}
+//   - viewerID uuid.UUID
+//   - parentID uuid.UUID
+func (_e *MockChatService_Expecter) GetThread(roomID interface{}, viewerID interface{}, parentID interface{}) *MockChatService_GetThread_Call {
+	return &MockChatService_GetThread_Call{Call: _e.mock.On("GetThread", roomID, viewerID, parentID)}
 }
 
-func (_c *MockChatService_PersistMessage_Call) Run(run func(content []byte, senderID uuid.UUID, roomID uuid.UUID)) *MockChatService_PersistMessage_Call {
+func (_c *MockChatService_GetThread_Call) Run(run func(roomID uuid.UUID, viewerID uuid.UUID, parentID uuid.UUID)) *MockChatService_GetThread_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 []byte
+		var arg0 uuid.UUID
 		if args[0] != nil {
-			arg0 = args[0].([]byte)
+			arg0 = args[0].(uuid.UUID)
 		}
 		var arg1 uuid.UUID
 		if args[1] != nil {
@@ -302,12 +533,1365 @@ func (_c *MockChatService_PersistMessage_Call) Run(run func(content []byte, send
 	return _c
 }
 
-func (_c *MockChatService_PersistMessage_Call) Return(uUID uuid.UUID, time1 time.Time, err error) *MockChatService_PersistMessage_Call {
-	_c.Call.Return(uUID, time1, err)
+func (_c *MockChatService_GetThread_Call) Return(messageInfos []service.MessageInfo, err error) *MockChatService_GetThread_Call {
+	_c.Call.Return(messageInfos, err)
+	return _c
+}
+
+func (_c *MockChatService_GetThread_Call) RunAndReturn(run func(roomID uuid.UUID, viewerID uuid.UUID, parentID uuid.UUID) ([]service.MessageInfo, error)) *MockChatService_GetThread_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportRoom provides a mock function for the type MockChatService
+func (_mock *MockChatService) ImportRoom(actorID uuid.UUID, room service.RoomInfo, messages []service.MessageInfo) (*service.RoomInfo, error) {
+	ret := _mock.Called(actorID, room, messages)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportRoom")
+	}
+
+	var r0 *service.RoomInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, service.RoomInfo, []service.MessageInfo) (*service.RoomInfo, error)); ok {
+		return returnFunc(actorID, room, messages)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, service.RoomInfo, []service.MessageInfo) *service.RoomInfo); ok {
+		r0 = returnFunc(actorID, room, messages)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RoomInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, service.RoomInfo, []service.MessageInfo) error); ok {
+		r1 = returnFunc(actorID, room, messages)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_ImportRoom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportRoom'
+type MockChatService_ImportRoom_Call struct {
+	*mock.Call
+}
+
+// ImportRoom is a helper method to define mock.On call
+//   - actorID uuid.UUID
+//   - room service.RoomInfo
+//   - messages []service.MessageInfo
+func (_e *MockChatService_Expecter) ImportRoom(actorID interface{}, room interface{}, messages interface{}) *MockChatService_ImportRoom_Call {
+	return &MockChatService_ImportRoom_Call{Call: _e.mock.On("ImportRoom", actorID, room, messages)}
+}
+
+func (_c *MockChatService_ImportRoom_Call) Run(run func(actorID uuid.UUID, room service.RoomInfo, messages []service.MessageInfo)) *MockChatService_ImportRoom_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 service.RoomInfo
+		if args[1] != nil {
+			arg1 = args[1].(service.RoomInfo)
+		}
+		var arg2 []service.MessageInfo
+		if args[2] != nil {
+			arg2 = args[2].([]service.MessageInfo)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_ImportRoom_Call) Return(roomInfo *service.RoomInfo, err error) *MockChatService_ImportRoom_Call {
+	_c.Call.Return(roomInfo, err)
+	return _c
+}
+
+func (_c *MockChatService_ImportRoom_Call) RunAndReturn(run func(actorID uuid.UUID, room service.RoomInfo, messages []service.MessageInfo) (*service.RoomInfo, error)) *MockChatService_ImportRoom_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InviteMember provides a mock function for the type MockChatService
+func (_mock *MockChatService) InviteMember(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID) error {
+	ret := _mock.Called(roomID, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InviteMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, actorID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_InviteMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InviteMember'
+type MockChatService_InviteMember_Call struct {
+	*mock.Call
+}
+
+// InviteMember is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockChatService_Expecter) InviteMember(roomID interface{}, actorID interface{}, userID interface{}) *MockChatService_InviteMember_Call {
+	return &MockChatService_InviteMember_Call{Call: _e.mock.On("InviteMember", roomID, actorID, userID)}
+}
+
+func (_c *MockChatService_InviteMember_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID)) *MockChatService_InviteMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_InviteMember_Call) Return(err error) *MockChatService_InviteMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_InviteMember_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID) error) *MockChatService_InviteMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// JoinRoom provides a mock function for the type MockChatService
+func (_mock *MockChatService) JoinRoom(roomID uuid.UUID, userID uuid.UUID) error {
+	ret := _mock.Called(roomID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JoinRoom")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_JoinRoom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JoinRoom'
+type MockChatService_JoinRoom_Call struct {
+	*mock.Call
+}
+
+// JoinRoom is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockChatService_Expecter) JoinRoom(roomID interface{}, userID interface{}) *MockChatService_JoinRoom_Call {
+	return &MockChatService_JoinRoom_Call{Call: _e.mock.On("JoinRoom", roomID, userID)}
+}
+
+func (_c *MockChatService_JoinRoom_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID)) *MockChatService_JoinRoom_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_JoinRoom_Call) Return(err error) *MockChatService_JoinRoom_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_JoinRoom_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID) error) *MockChatService_JoinRoom_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function for the type MockChatService
+func (_mock *MockChatService) ListMembers(roomID uuid.UUID, actorID uuid.UUID) ([]service.MemberInfo, error) {
+	ret := _mock.Called(roomID, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []service.MemberInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) ([]service.MemberInfo, error)); ok {
+		return returnFunc(roomID, actorID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) []service.MemberInfo); ok {
+		r0 = returnFunc(roomID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.MemberInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(roomID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockChatService_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+func (_e *MockChatService_Expecter) ListMembers(roomID interface{}, actorID interface{}) *MockChatService_ListMembers_Call {
+	return &MockChatService_ListMembers_Call{Call: _e.mock.On("ListMembers", roomID, actorID)}
+}
+
+func (_c *MockChatService_ListMembers_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID)) *MockChatService_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_ListMembers_Call) Return(memberInfos []service.MemberInfo, err error) *MockChatService_ListMembers_Call {
+	_c.Call.Return(memberInfos, err)
+	return _c
+}
+
+func (_c *MockChatService_ListMembers_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID) ([]service.MemberInfo, error)) *MockChatService_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkRead provides a mock function for the type MockChatService
+func (_mock *MockChatService) MarkRead(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID) error {
+	ret := _mock.Called(roomID, userID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, userID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_MarkRead_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkRead'
+type MockChatService_MarkRead_Call struct {
+	*mock.Call
+}
+
+// MarkRead is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+//   - messageID uuid.UUID
+func (_e *MockChatService_Expecter) MarkRead(roomID interface{}, userID interface{}, messageID interface{}) *MockChatService_MarkRead_Call {
+	return &MockChatService_MarkRead_Call{Call: _e.mock.On("MarkRead", roomID, userID, messageID)}
+}
+
+func (_c *MockChatService_MarkRead_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID)) *MockChatService_MarkRead_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_MarkRead_Call) Return(err error) *MockChatService_MarkRead_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_MarkRead_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID) error) *MockChatService_MarkRead_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Mentions provides a mock function for the type MockChatService
+func (_mock *MockChatService) Mentions(userID uuid.UUID, before uuid.UUID, limit int) ([]service.MessageInfo, error) {
+	ret := _mock.Called(userID, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Mentions")
+	}
+
+	var r0 []service.MessageInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) ([]service.MessageInfo, error)); ok {
+		return returnFunc(userID, before, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) []service.MessageInfo); ok {
+		r0 = returnFunc(userID, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.MessageInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(userID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_Mentions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Mentions'
+type MockChatService_Mentions_Call struct {
+	*mock.Call
+}
+
+// Mentions is a helper method to define mock.On call
+//   - userID uuid.UUID
+//   - before uuid.UUID
+//   - limit int
+func (_e *MockChatService_Expecter) Mentions(userID interface{}, before interface{}, limit interface{}) *MockChatService_Mentions_Call {
+	return &MockChatService_Mentions_Call{Call: _e.mock.On("Mentions", userID, before, limit)}
+}
+
+func (_c *MockChatService_Mentions_Call) Run(run func(userID uuid.UUID, before uuid.UUID, limit int)) *MockChatService_Mentions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_Mentions_Call) Return(messageInfos []service.MessageInfo, err error) *MockChatService_Mentions_Call {
+	_c.Call.Return(messageInfos, err)
+	return _c
+}
+
+func (_c *MockChatService_Mentions_Call) RunAndReturn(run func(userID uuid.UUID, before uuid.UUID, limit int) ([]service.MessageInfo, error)) *MockChatService_Mentions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OpenDM provides a mock function for the type MockChatService
+func (_mock *MockChatService) OpenDM(actorID uuid.UUID, participantIDs []uuid.UUID) (*service.RoomInfo, bool, error) {
+	ret := _mock.Called(actorID, participantIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenDM")
+	}
+
+	var r0 *service.RoomInfo
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, []uuid.UUID) (*service.RoomInfo, bool, error)); ok {
+		return returnFunc(actorID, participantIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, []uuid.UUID) *service.RoomInfo); ok {
+		r0 = returnFunc(actorID, participantIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RoomInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, []uuid.UUID) bool); ok {
+		r1 = returnFunc(actorID, participantIDs)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(uuid.UUID, []uuid.UUID) error); ok {
+		r2 = returnFunc(actorID, participantIDs)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockChatService_OpenDM_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenDM'
+type MockChatService_OpenDM_Call struct {
+	*mock.Call
+}
+
+// OpenDM is a helper method to define mock.On call
+//   - actorID uuid.UUID
+//   - participantIDs []uuid.UUID
+func (_e *MockChatService_Expecter) OpenDM(actorID interface{}, participantIDs interface{}) *MockChatService_OpenDM_Call {
+	return &MockChatService_OpenDM_Call{Call: _e.mock.On("OpenDM", actorID, participantIDs)}
+}
+
+func (_c *MockChatService_OpenDM_Call) Run(run func(actorID uuid.UUID, participantIDs []uuid.UUID)) *MockChatService_OpenDM_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_OpenDM_Call) Return(roomInfo *service.RoomInfo, b bool, err error) *MockChatService_OpenDM_Call {
+	_c.Call.Return(roomInfo, b, err)
+	return _c
+}
+
+func (_c *MockChatService_OpenDM_Call) RunAndReturn(run func(actorID uuid.UUID, participantIDs []uuid.UUID) (*service.RoomInfo, bool, error)) *MockChatService_OpenDM_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PersistAttachment provides a mock function for the type MockChatService
+func (_mock *MockChatService) PersistAttachment(senderID uuid.UUID, roomID uuid.UUID, caption []byte, att service.AttachmentInfo) (*service.MessageInfo, error) {
+	ret := _mock.Called(senderID, roomID, caption, att)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PersistAttachment")
+	}
+
+	var r0 *service.MessageInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, []byte, service.AttachmentInfo) (*service.MessageInfo, error)); ok {
+		return returnFunc(senderID, roomID, caption, att)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, []byte, service.AttachmentInfo) *service.MessageInfo); ok {
+		r0 = returnFunc(senderID, roomID, caption, att)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.MessageInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, []byte, service.AttachmentInfo) error); ok {
+		r1 = returnFunc(senderID, roomID, caption, att)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_PersistAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PersistAttachment'
+type MockChatService_PersistAttachment_Call struct {
+	*mock.Call
+}
+
+// PersistAttachment is a helper method to define mock.On call
+//   - senderID uuid.UUID
+//   - roomID uuid.UUID
+//   - caption []byte
+//   - att service.AttachmentInfo
+func (_e *MockChatService_Expecter) PersistAttachment(senderID interface{}, roomID interface{}, caption interface{}, att interface{}) *MockChatService_PersistAttachment_Call {
+	return &MockChatService_PersistAttachment_Call{Call: _e.mock.On("PersistAttachment", senderID, roomID, caption, att)}
+}
+
+func (_c *MockChatService_PersistAttachment_Call) Run(run func(senderID uuid.UUID, roomID uuid.UUID, caption []byte, att service.AttachmentInfo)) *MockChatService_PersistAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 []byte
+		if args[2] != nil {
+			arg2 = args[2].([]byte)
+		}
+		var arg3 service.AttachmentInfo
+		if args[3] != nil {
+			arg3 = args[3].(service.AttachmentInfo)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_PersistAttachment_Call) Return(messageInfo *service.MessageInfo, err error) *MockChatService_PersistAttachment_Call {
+	_c.Call.Return(messageInfo, err)
+	return _c
+}
+
+func (_c *MockChatService_PersistAttachment_Call) RunAndReturn(run func(senderID uuid.UUID, roomID uuid.UUID, caption []byte, att service.AttachmentInfo) (*service.MessageInfo, error)) *MockChatService_PersistAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PersistMessage provides a mock function for the type MockChatService
+func (_mock *MockChatService) PersistMessage(content []byte, kind string, senderID uuid.UUID, roomID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error) {
+	ret := _mock.Called(content, kind, senderID, roomID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PersistMessage")
+	}
+
+	var r0 uuid.UUID
+	var r1 time.Time
+	var r2 []uuid.UUID
+	var r3 error
+	if returnFunc, ok := ret.Get(0).(func([]byte, string, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)); ok {
+		return returnFunc(content, kind, senderID, roomID)
+	}
+	if returnFunc, ok := ret.Get(0).(func([]byte, string, uuid.UUID, uuid.UUID) uuid.UUID); ok {
+		r0 = returnFunc(content, kind, senderID, roomID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func([]byte, string, uuid.UUID, uuid.UUID) time.Time); ok {
+		r1 = returnFunc(content, kind, senderID, roomID)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(2).(func([]byte, string, uuid.UUID, uuid.UUID) []uuid.UUID); ok {
+		r2 = returnFunc(content, kind, senderID, roomID)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).([]uuid.UUID)
+		}
+	}
+	if returnFunc, ok := ret.Get(3).(func([]byte, string, uuid.UUID, uuid.UUID) error); ok {
+		r3 = returnFunc(content, kind, senderID, roomID)
+	} else {
+		r3 = ret.Error(3)
+	}
+	return r0, r1, r2, r3
+}
+
+// MockChatService_PersistMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PersistMessage'
+type MockChatService_PersistMessage_Call struct {
+	*mock.Call
+}
+
+// PersistMessage is a helper method to define mock.On call
+//   - content []byte
+//   - kind string
+//   - senderID uuid.UUID
+//   - roomID uuid.UUID
+func (_e *MockChatService_Expecter) PersistMessage(content interface{}, kind interface{}, senderID interface{}, roomID interface{}) *MockChatService_PersistMessage_Call {
+	return &MockChatService_PersistMessage_Call{Call: _e.mock.On("PersistMessage", content, kind, senderID, roomID)}
+}
+
+func (_c *MockChatService_PersistMessage_Call) Run(run func(content []byte, kind string, senderID uuid.UUID, roomID uuid.UUID)) *MockChatService_PersistMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []byte
+		if args[0] != nil {
+			arg0 = args[0].([]byte)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_PersistMessage_Call) Return(uUID uuid.UUID, time1 time.Time, uUIDs []uuid.UUID, err error) *MockChatService_PersistMessage_Call {
+	_c.Call.Return(uUID, time1, uUIDs, err)
+	return _c
+}
+
+func (_c *MockChatService_PersistMessage_Call) RunAndReturn(run func(content []byte, kind string, senderID uuid.UUID, roomID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)) *MockChatService_PersistMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PersistReply provides a mock function for the type MockChatService
+func (_mock *MockChatService) PersistReply(content []byte, senderID uuid.UUID, roomID uuid.UUID, parentID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error) {
+	ret := _mock.Called(content, senderID, roomID, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PersistReply")
+	}
+
+	var r0 uuid.UUID
+	var r1 time.Time
+	var r2 []uuid.UUID
+	var r3 error
+	if returnFunc, ok := ret.Get(0).(func([]byte, uuid.UUID, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)); ok {
+		return returnFunc(content, senderID, roomID, parentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func([]byte, uuid.UUID, uuid.UUID, uuid.UUID) uuid.UUID); ok {
+		r0 = returnFunc(content, senderID, roomID, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func([]byte, uuid.UUID, uuid.UUID, uuid.UUID) time.Time); ok {
+		r1 = returnFunc(content, senderID, roomID, parentID)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(2).(func([]byte, uuid.UUID, uuid.UUID, uuid.UUID) []uuid.UUID); ok {
+		r2 = returnFunc(content, senderID, roomID, parentID)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).([]uuid.UUID)
+		}
+	}
+	if returnFunc, ok := ret.Get(3).(func([]byte, uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r3 = returnFunc(content, senderID, roomID, parentID)
+	} else {
+		r3 = ret.Error(3)
+	}
+	return r0, r1, r2, r3
+}
+
+// MockChatService_PersistReply_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PersistReply'
+type MockChatService_PersistReply_Call struct {
+	*mock.Call
+}
+
+// PersistReply is a helper method to define mock.On call
+//   - content []byte
+//   - senderID uuid.UUID
+//   - roomID uuid.UUID
+//   - parentID uuid.UUID
+func (_e *MockChatService_Expecter) PersistReply(content interface{}, senderID interface{}, roomID interface{}, parentID interface{}) *MockChatService_PersistReply_Call {
+	return &MockChatService_PersistReply_Call{Call: _e.mock.On("PersistReply", content, senderID, roomID, parentID)}
+}
+
+func (_c *MockChatService_PersistReply_Call) Run(run func(content []byte, senderID uuid.UUID, roomID uuid.UUID, parentID uuid.UUID)) *MockChatService_PersistReply_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []byte
+		if args[0] != nil {
+			arg0 = args[0].([]byte)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_PersistReply_Call) Return(uUID uuid.UUID, time1 time.Time, uUIDs []uuid.UUID, err error) *MockChatService_PersistReply_Call {
+	_c.Call.Return(uUID, time1, uUIDs, err)
+	return _c
+}
+
+func (_c *MockChatService_PersistReply_Call) RunAndReturn(run func(content []byte, senderID uuid.UUID, roomID uuid.UUID, parentID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)) *MockChatService_PersistReply_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// React provides a mock function for the type MockChatService
+func (_mock *MockChatService) React(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(messageID, userID, roomID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for React")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(messageID, userID, roomID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(messageID, userID, roomID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(messageID, userID, roomID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_React_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'React'
+type MockChatService_React_Call struct {
+	*mock.Call
+}
+
+// React is a helper method to define mock.On call
+//   - messageID uuid.UUID
+//   - userID uuid.UUID
+//   - roomID uuid.UUID
+//   - emoji string
+func (_e *MockChatService_Expecter) React(messageID interface{}, userID interface{}, roomID interface{}, emoji interface{}) *MockChatService_React_Call {
+	return &MockChatService_React_Call{Call: _e.mock.On("React", messageID, userID, roomID, emoji)}
+}
+
+func (_c *MockChatService_React_Call) Run(run func(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string)) *MockChatService_React_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_React_Call) Return(b bool, err error) *MockChatService_React_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockChatService_React_Call) RunAndReturn(run func(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string) (bool, error)) *MockChatService_React_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockChatService
+func (_mock *MockChatService) RemoveMember(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID) error {
+	ret := _mock.Called(roomID, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, actorID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockChatService_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockChatService_Expecter) RemoveMember(roomID interface{}, actorID interface{}, userID interface{}) *MockChatService_RemoveMember_Call {
+	return &MockChatService_RemoveMember_Call{Call: _e.mock.On("RemoveMember", roomID, actorID, userID)}
+}
+
+func (_c *MockChatService_RemoveMember_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID)) *MockChatService_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_RemoveMember_Call) Return(err error) *MockChatService_RemoveMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_RemoveMember_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID) error) *MockChatService_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RoomKeys provides a mock function for the type MockChatService
+func (_mock *MockChatService) RoomKeys(roomID uuid.UUID, actorID uuid.UUID) ([]service.MemberKey, error) {
+	ret := _mock.Called(roomID, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RoomKeys")
+	}
+
+	var r0 []service.MemberKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) ([]service.MemberKey, error)); ok {
+		return returnFunc(roomID, actorID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) []service.MemberKey); ok {
+		r0 = returnFunc(roomID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.MemberKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(roomID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_RoomKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RoomKeys'
+type MockChatService_RoomKeys_Call struct {
+	*mock.Call
+}
+
+// RoomKeys is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+func (_e *MockChatService_Expecter) RoomKeys(roomID interface{}, actorID interface{}) *MockChatService_RoomKeys_Call {
+	return &MockChatService_RoomKeys_Call{Call: _e.mock.On("RoomKeys", roomID, actorID)}
+}
+
+func (_c *MockChatService_RoomKeys_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID)) *MockChatService_RoomKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_RoomKeys_Call) Return(memberKeys []service.MemberKey, err error) *MockChatService_RoomKeys_Call {
+	_c.Call.Return(memberKeys, err)
+	return _c
+}
+
+func (_c *MockChatService_RoomKeys_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID) ([]service.MemberKey, error)) *MockChatService_RoomKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchMessages provides a mock function for the type MockChatService
+func (_mock *MockChatService) SearchMessages(actorID uuid.UUID, query service.SearchQuery) ([]service.MessageInfo, error) {
+	ret := _mock.Called(actorID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchMessages")
+	}
+
+	var r0 []service.MessageInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, service.SearchQuery) ([]service.MessageInfo, error)); ok {
+		return returnFunc(actorID, query)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, service.SearchQuery) []service.MessageInfo); ok {
+		r0 = returnFunc(actorID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.MessageInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, service.SearchQuery) error); ok {
+		r1 = returnFunc(actorID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_SearchMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchMessages'
+type MockChatService_SearchMessages_Call struct {
+	*mock.Call
+}
+
+// SearchMessages is a helper method to define mock.On call
+//   - actorID uuid.UUID
+//   - query service.SearchQuery
+func (_e *MockChatService_Expecter) SearchMessages(actorID interface{}, query interface{}) *MockChatService_SearchMessages_Call {
+	return &MockChatService_SearchMessages_Call{Call: _e.mock.On("SearchMessages", actorID, query)}
+}
+
+func (_c *MockChatService_SearchMessages_Call) Run(run func(actorID uuid.UUID, query service.SearchQuery)) *MockChatService_SearchMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 service.SearchQuery
+		if args[1] != nil {
+			arg1 = args[1].(service.SearchQuery)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SearchMessages_Call) Return(messageInfos []service.MessageInfo, err error) *MockChatService_SearchMessages_Call {
+	_c.Call.Return(messageInfos, err)
+	return _c
+}
+
+func (_c *MockChatService_SearchMessages_Call) RunAndReturn(run func(actorID uuid.UUID, query service.SearchQuery) ([]service.MessageInfo, error)) *MockChatService_SearchMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberRole provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetMemberRole(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID, role string) error {
+	ret := _mock.Called(roomID, actorID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = returnFunc(roomID, actorID, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockChatService_SetMemberRole_Call struct {
+	*mock.Call
+}
+
+// SetMemberRole is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+//   - userID uuid.UUID
+//   - role string
+func (_e *MockChatService_Expecter) SetMemberRole(roomID interface{}, actorID interface{}, userID interface{}, role interface{}) *MockChatService_SetMemberRole_Call {
+	return &MockChatService_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", roomID, actorID, userID, role)}
+}
+
+func (_c *MockChatService_SetMemberRole_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID, role string)) *MockChatService_SetMemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetMemberRole_Call) Return(err error) *MockChatService_SetMemberRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_SetMemberRole_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID, userID uuid.UUID, role string) error) *MockChatService_SetMemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRetention provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetRetention(roomID uuid.UUID, actorID uuid.UUID, retention repository.Retention) error {
+	ret := _mock.Called(roomID, actorID, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRetention")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, repository.Retention) error); ok {
+		r0 = returnFunc(roomID, actorID, retention)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_SetRetention_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRetention'
+type MockChatService_SetRetention_Call struct {
+	*mock.Call
+}
+
+// SetRetention is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+//   - retention repository.Retention
+func (_e *MockChatService_Expecter) SetRetention(roomID interface{}, actorID interface{}, retention interface{}) *MockChatService_SetRetention_Call {
+	return &MockChatService_SetRetention_Call{Call: _e.mock.On("SetRetention", roomID, actorID, retention)}
+}
+
+func (_c *MockChatService_SetRetention_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID, retention repository.Retention)) *MockChatService_SetRetention_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 repository.Retention
+		if args[2] != nil {
+			arg2 = args[2].(repository.Retention)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetRetention_Call) Return(err error) *MockChatService_SetRetention_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_SetRetention_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID, retention repository.Retention) error) *MockChatService_SetRetention_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTopic provides a mock function for the type MockChatService
+func (_mock *MockChatService) SetTopic(roomID uuid.UUID, actorID uuid.UUID, topic string) error {
+	ret := _mock.Called(roomID, actorID, topic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTopic")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = returnFunc(roomID, actorID, topic)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockChatService_SetTopic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTopic'
+type MockChatService_SetTopic_Call struct {
+	*mock.Call
+}
+
+// SetTopic is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - actorID uuid.UUID
+//   - topic string
+func (_e *MockChatService_Expecter) SetTopic(roomID interface{}, actorID interface{}, topic interface{}) *MockChatService_SetTopic_Call {
+	return &MockChatService_SetTopic_Call{Call: _e.mock.On("SetTopic", roomID, actorID, topic)}
+}
+
+func (_c *MockChatService_SetTopic_Call) Run(run func(roomID uuid.UUID, actorID uuid.UUID, topic string)) *MockChatService_SetTopic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_SetTopic_Call) Return(err error) *MockChatService_SetTopic_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockChatService_SetTopic_Call) RunAndReturn(run func(roomID uuid.UUID, actorID uuid.UUID, topic string) error) *MockChatService_SetTopic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unreact provides a mock function for the type MockChatService
+func (_mock *MockChatService) Unreact(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(messageID, userID, roomID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unreact")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(messageID, userID, roomID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(messageID, userID, roomID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(messageID, userID, roomID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_Unreact_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unreact'
+type MockChatService_Unreact_Call struct {
+	*mock.Call
+}
+
+// Unreact is a helper method to define mock.On call
+//   - messageID uuid.UUID
+//   - userID uuid.UUID
+//   - roomID uuid.UUID
+//   - emoji string
+func (_e *MockChatService_Expecter) Unreact(messageID interface{}, userID interface{}, roomID interface{}, emoji interface{}) *MockChatService_Unreact_Call {
+	return &MockChatService_Unreact_Call{Call: _e.mock.On("Unreact", messageID, userID, roomID, emoji)}
+}
+
+func (_c *MockChatService_Unreact_Call) Run(run func(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string)) *MockChatService_Unreact_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_Unreact_Call) Return(b bool, err error) *MockChatService_Unreact_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockChatService_Unreact_Call) RunAndReturn(run func(messageID uuid.UUID, userID uuid.UUID, roomID uuid.UUID, emoji string) (bool, error)) *MockChatService_Unreact_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnreadCounts provides a mock function for the type MockChatService
+func (_mock *MockChatService) UnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	ret := _mock.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnreadCounts")
+	}
+
+	var r0 map[uuid.UUID]int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) (map[uuid.UUID]int, error)); ok {
+		return returnFunc(userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) map[uuid.UUID]int); ok {
+		r0 = returnFunc(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]int)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = returnFunc(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockChatService_UnreadCounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnreadCounts'
+type MockChatService_UnreadCounts_Call struct {
+	*mock.Call
+}
+
+// UnreadCounts is a helper method to define mock.On call
+//   - userID uuid.UUID
+func (_e *MockChatService_Expecter) UnreadCounts(userID interface{}) *MockChatService_UnreadCounts_Call {
+	return &MockChatService_UnreadCounts_Call{Call: _e.mock.On("UnreadCounts", userID)}
+}
+
+func (_c *MockChatService_UnreadCounts_Call) Run(run func(userID uuid.UUID)) *MockChatService_UnreadCounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockChatService_UnreadCounts_Call) Return(mapping map[uuid.UUID]int, err error) *MockChatService_UnreadCounts_Call {
+	_c.Call.Return(mapping, err)
 	return _c
 }
 
-func (_c *MockChatService_PersistMessage_Call) RunAndReturn(run func(content []byte, senderID uuid.UUID, roomID uuid.UUID) (uuid.UUID, time.Time, error)) *MockChatService_PersistMessage_Call {
+func (_c *MockChatService_UnreadCounts_Call) RunAndReturn(run func(userID uuid.UUID) (map[uuid.UUID]int, error)) *MockChatService_UnreadCounts_Call {
 	_c.Call.Return(run)
 	return _c
 }
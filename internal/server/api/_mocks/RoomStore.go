@@ -6,6 +6,7 @@ package mocks
 
 import (
 	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -36,121 +37,133 @@ func (_m *MockRoomStore) EXPECT() *MockRoomStore_Expecter {
 	return &MockRoomStore_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function for the type MockRoomStore
-func (_mock *MockRoomStore) Create(room *repository.Room) error {
-	ret := _mock.Called(room)
+// CreateWithOwner provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) CreateWithOwner(room *repository.Room, ownerID uuid.UUID) error {
+	ret := _mock.Called(room, ownerID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for CreateWithOwner")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(*repository.Room) error); ok {
-		r0 = returnFunc(room)
+	if returnFunc, ok := ret.Get(0).(func(*repository.Room, uuid.UUID) error); ok {
+		r0 = returnFunc(room, ownerID)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockRoomStore_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type MockRoomStore_Create_Call struct {
+// MockRoomStore_CreateWithOwner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWithOwner'
+type MockRoomStore_CreateWithOwner_Call struct {
 	*mock.Call
 }
 
-// Create is a helper method to define mock.On call
+// CreateWithOwner is a helper method to define mock.On call
 //   - room *repository.Room
-func (_e *MockRoomStore_Expecter) Create(room interface{}) *MockRoomStore_Create_Call {
-	return &MockRoomStore_Create_Call{Call: _e.mock.On("Create", room)}
+//   - ownerID uuid.UUID
+func (_e *MockRoomStore_Expecter) CreateWithOwner(room interface{}, ownerID interface{}) *MockRoomStore_CreateWithOwner_Call {
+	return &MockRoomStore_CreateWithOwner_Call{Call: _e.mock.On("CreateWithOwner", room, ownerID)}
 }
 
-func (_c *MockRoomStore_Create_Call) Run(run func(room *repository.Room)) *MockRoomStore_Create_Call {
+func (_c *MockRoomStore_CreateWithOwner_Call) Run(run func(room *repository.Room, ownerID uuid.UUID)) *MockRoomStore_CreateWithOwner_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 *repository.Room
 		if args[0] != nil {
 			arg0 = args[0].(*repository.Room)
 		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRoomStore_Create_Call) Return(err error) *MockRoomStore_Create_Call {
+func (_c *MockRoomStore_CreateWithOwner_Call) Return(err error) *MockRoomStore_CreateWithOwner_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockRoomStore_Create_Call) RunAndReturn(run func(room *repository.Room) error) *MockRoomStore_Create_Call {
+func (_c *MockRoomStore_CreateWithOwner_Call) RunAndReturn(run func(room *repository.Room, ownerID uuid.UUID) error) *MockRoomStore_CreateWithOwner_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// List provides a mock function for the type MockRoomStore
-func (_mock *MockRoomStore) List(limit int, offset int) ([]repository.Room, error) {
-	ret := _mock.Called(limit, offset)
+// ListVisible provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) ListVisible(userID uuid.UUID, limit int, offset int) ([]repository.Room, error) {
+	ret := _mock.Called(userID, limit, offset)
 
 	if len(ret) == 0 {
-		panic("no return value specified for List")
+		panic("no return value specified for ListVisible")
 	}
 
 	var r0 []repository.Room
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(int, int) ([]repository.Room, error)); ok {
-		return returnFunc(limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, int, int) ([]repository.Room, error)); ok {
+		return returnFunc(userID, limit, offset)
 	}
-	if returnFunc, ok := ret.Get(0).(func(int, int) []repository.Room); ok {
-		r0 = returnFunc(limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, int, int) []repository.Room); ok {
+		r0 = returnFunc(userID, limit, offset)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]repository.Room)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(int, int) error); ok {
-		r1 = returnFunc(limit, offset)
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, int, int) error); ok {
+		r1 = returnFunc(userID, limit, offset)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockRoomStore_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
-type MockRoomStore_List_Call struct {
+// MockRoomStore_ListVisible_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVisible'
+type MockRoomStore_ListVisible_Call struct {
 	*mock.Call
 }
 
-// List is a helper method to define mock.On call
+// ListVisible is a helper method to define mock.On call
+//   - userID uuid.UUID
 //   - limit int
 //   - offset int
-func (_e *MockRoomStore_Expecter) List(limit interface{}, offset interface{}) *MockRoomStore_List_Call {
-	return &MockRoomStore_List_Call{Call: _e.mock.On("List", limit, offset)}
+func (_e *MockRoomStore_Expecter) ListVisible(userID interface{}, limit interface{}, offset interface{}) *MockRoomStore_ListVisible_Call {
+	return &MockRoomStore_ListVisible_Call{Call: _e.mock.On("ListVisible", userID, limit, offset)}
 }
 
-func (_c *MockRoomStore_List_Call) Run(run func(limit int, offset int)) *MockRoomStore_List_Call {
+func (_c *MockRoomStore_ListVisible_Call) Run(run func(userID uuid.UUID, limit int, offset int)) *MockRoomStore_ListVisible_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int
+		var arg0 uuid.UUID
 		if args[0] != nil {
-			arg0 = args[0].(int)
+			arg0 = args[0].(uuid.UUID)
 		}
 		var arg1 int
 		if args[1] != nil {
 			arg1 = args[1].(int)
 		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockRoomStore_List_Call) Return(rooms []repository.Room, err error) *MockRoomStore_List_Call {
+func (_c *MockRoomStore_ListVisible_Call) Return(rooms []repository.Room, err error) *MockRoomStore_ListVisible_Call {
 	_c.Call.Return(rooms, err)
 	return _c
 }
 
-func (_c *MockRoomStore_List_Call) RunAndReturn(run func(limit int, offset int) ([]repository.Room, error)) *MockRoomStore_List_Call {
+func (_c *MockRoomStore_ListVisible_Call) RunAndReturn(run func(userID uuid.UUID, limit int, offset int) ([]repository.Room, error)) *MockRoomStore_ListVisible_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -86,3 +87,179 @@ func (_c *MockUserStore_Create_Call) RunAndReturn(run func(user *repository.User
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetByName provides a mock function for the type MockUserStore
+func (_mock *MockUserStore) GetByName(name string) (*repository.User, error) {
+	ret := _mock.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByName")
+	}
+
+	var r0 *repository.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*repository.User, error)); ok {
+		return returnFunc(name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) *repository.User); ok {
+		r0 = returnFunc(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserStore_GetByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByName'
+type MockUserStore_GetByName_Call struct {
+	*mock.Call
+}
+
+// GetByName is a helper method to define mock.On call
+//   - name string
+func (_e *MockUserStore_Expecter) GetByName(name interface{}) *MockUserStore_GetByName_Call {
+	return &MockUserStore_GetByName_Call{Call: _e.mock.On("GetByName", name)}
+}
+
+func (_c *MockUserStore_GetByName_Call) Run(run func(name string)) *MockUserStore_GetByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserStore_GetByName_Call) Return(user *repository.User, err error) *MockUserStore_GetByName_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUserStore_GetByName_Call) RunAndReturn(run func(name string) (*repository.User, error)) *MockUserStore_GetByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPublicKey provides a mock function for the type MockUserStore
+func (_mock *MockUserStore) SetPublicKey(id uuid.UUID, publicKey string) error {
+	ret := _mock.Called(id, publicKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPublicKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = returnFunc(id, publicKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserStore_SetPublicKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPublicKey'
+type MockUserStore_SetPublicKey_Call struct {
+	*mock.Call
+}
+
+// SetPublicKey is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - publicKey string
+func (_e *MockUserStore_Expecter) SetPublicKey(id interface{}, publicKey interface{}) *MockUserStore_SetPublicKey_Call {
+	return &MockUserStore_SetPublicKey_Call{Call: _e.mock.On("SetPublicKey", id, publicKey)}
+}
+
+func (_c *MockUserStore_SetPublicKey_Call) Run(run func(id uuid.UUID, publicKey string)) *MockUserStore_SetPublicKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserStore_SetPublicKey_Call) Return(err error) *MockUserStore_SetPublicKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserStore_SetPublicKey_Call) RunAndReturn(run func(id uuid.UUID, publicKey string) error) *MockUserStore_SetPublicKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateName provides a mock function for the type MockUserStore
+func (_mock *MockUserStore) UpdateName(id uuid.UUID, name string) error {
+	ret := _mock.Called(id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateName")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = returnFunc(id, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserStore_UpdateName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateName'
+type MockUserStore_UpdateName_Call struct {
+	*mock.Call
+}
+
+// UpdateName is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - name string
+func (_e *MockUserStore_Expecter) UpdateName(id interface{}, name interface{}) *MockUserStore_UpdateName_Call {
+	return &MockUserStore_UpdateName_Call{Call: _e.mock.On("UpdateName", id, name)}
+}
+
+func (_c *MockUserStore_UpdateName_Call) Run(run func(id uuid.UUID, name string)) *MockUserStore_UpdateName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserStore_UpdateName_Call) Return(err error) *MockUserStore_UpdateName_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserStore_UpdateName_Call) RunAndReturn(run func(id uuid.UUID, name string) error) *MockUserStore_UpdateName_Call {
+	_c.Call.Return(run)
+	return _c
+}
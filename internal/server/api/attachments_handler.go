@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EwanGreer/chatatui/internal/blob"
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead allows for the form's boundaries, headers and caption on
+// top of the file itself.
+const multipartOverhead = 64 << 10
+
+type AttachmentsHandler struct {
+	hub      *hub.Hub
+	svc      ChatService
+	blobs    blob.Store
+	maxBytes int64
+}
+
+func NewAttachmentsHandler(h *hub.Hub, svc ChatService, blobs blob.Store, maxBytes int64) *AttachmentsHandler {
+	return &AttachmentsHandler{hub: h, svc: svc, blobs: blobs, maxBytes: maxBytes}
+}
+
+// Upload stores the multipart form's "file" part and posts it to the room as a
+// file message, with the optional "caption" field as its text. The message is
+// announced to the room, the uploader's connections included, and returned.
+func (h *AttachmentsHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+multipartOverhead)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", fmt.Sprintf("files must be %d bytes or smaller", h.maxBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "expected a multipart form with a file")
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "expected a multipart form with a file")
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	if header.Size > h.maxBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", fmt.Sprintf("files must be %d bytes or smaller", h.maxBytes))
+		return
+	}
+
+	caption := strings.TrimSpace(r.FormValue("caption"))
+	if len(caption) > limits.MaxMessageLength {
+		writeError(w, http.StatusBadRequest, "CAPTION_TOO_LONG", fmt.Sprintf("caption must be %d characters or fewer", limits.MaxMessageLength))
+		return
+	}
+
+	name := attachmentName(header.Filename)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_FILE_NAME", "file needs a name")
+		return
+	}
+
+	contentType, err := sniffContentType(file, header.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to read file")
+		return
+	}
+
+	blobID, err := uuid.NewV7()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to store file")
+		return
+	}
+	key := roomID.String() + "/" + blobID.String()
+	if err := h.blobs.Put(r.Context(), key, file); err != nil {
+		slog.Error("failed to store attachment", "error", err, "room_id", roomID)
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to store file")
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	info, err := h.svc.PersistAttachment(user.ID, roomID, []byte(caption), service.AttachmentInfo{
+		Key:         key,
+		Name:        name,
+		ContentType: contentType,
+		Size:        header.Size,
+	})
+	if err != nil {
+		if delErr := h.blobs.Delete(r.Context(), key); delErr != nil {
+			slog.Error("failed to remove orphaned attachment", "error", delErr, "key", key)
+		}
+		if errors.Is(err, service.ErrNotEncrypted) {
+			writeError(w, http.StatusBadRequest, "ENCRYPTED_ROOM", "files cannot be sent to encrypted rooms")
+			return
+		}
+		writeServiceError(w, err, "failed to send file")
+		return
+	}
+	info.Author = user.Name
+
+	wire := wireMessages([]service.MessageInfo{*info})[0]
+	if wireBytes, err := wire.Marshal(); err != nil {
+		slog.Error("failed to marshal file message", "error", err, "room_id", roomID)
+	} else {
+		h.hub.Publish(roomID, wireBytes)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(wire)
+}
+
+// Download streams the file attached to a message.
+func (h *AttachmentsHandler) Download(w http.ResponseWriter, r *http.Request) {
+	messageID, err := uuid.Parse(chi.URLParam(r, "messageID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MESSAGE_ID", "invalid message id")
+		return
+	}
+
+	viewer := middleware.UserFromContext(r.Context())
+	info, err := h.svc.GetAttachment(messageID, viewer.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotAttachment) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "message has no attachment")
+			return
+		}
+		writeServiceError(w, err, "failed to get attachment")
+		return
+	}
+
+	content, err := h.blobs.Get(r.Context(), info.Attachment.Key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "attachment is no longer available")
+			return
+		}
+		slog.Error("failed to open attachment", "error", err, "message_id", messageID)
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get attachment")
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	w.Header().Set("Content-Type", info.Attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, content)
+}
+
+// attachmentName reduces an uploaded file's name to its base name, capped at
+// limits.MaxAttachmentNameLength bytes.
+func attachmentName(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, `\`, "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	for len(name) > limits.MaxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// sniffContentType returns declared, unless it is missing or generic, in which
+// case the type is detected from the file's first bytes. file is rewound.
+func sniffContentType(file io.ReadSeeker, declared string) (string, error) {
+	if declared != "" && declared != "application/octet-stream" {
+		if _, _, err := mime.ParseMediaType(declared); err == nil {
+			return declared, nil
+		}
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/blob"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttachmentsRouter(t *testing.T, actor *repository.User, svc ChatService, blobs blob.Store, maxBytes int64) http.Handler {
+	ah := NewAttachmentsHandler(hub.NewHub(hub.NewMemoryBroker()), svc, blobs, maxBytes)
+	r := chi.NewRouter()
+	r.Post("/rooms/{roomID}/attachments", ah.Upload)
+	r.Get("/attachments/{messageID}", ah.Download)
+	return authenticatedAs(t, actor, r)
+}
+
+// uploadRequest builds a multipart upload of a file called name.
+func uploadRequest(t *testing.T, roomID uuid.UUID, name, content, caption string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, _ = io.WriteString(part, content)
+	require.NoError(t, form.WriteField("caption", caption))
+	require.NoError(t, form.Close())
+
+	req := authedRequest(http.MethodPost, "/rooms/"+roomID.String()+"/attachments", "")
+	req.Body = io.NopCloser(&body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func newBlobStore(t *testing.T) *blob.FileStore {
+	t.Helper()
+	store, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return store
+}
+
+func TestAttachmentsHandler_Upload(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+	blobs := newBlobStore(t)
+
+	var stored service.AttachmentInfo
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().PersistAttachment(actor.ID, roomID, []byte("the logs"), mock.Anything).
+		RunAndReturn(func(_, _ uuid.UUID, caption []byte, att service.AttachmentInfo) (*service.MessageInfo, error) {
+			stored = att
+			return &service.MessageInfo{ID: uuid.New(), RoomID: roomID, Content: string(caption), Kind: repository.MessageKindFile, Attachment: &att}, nil
+		})
+
+	w := httptest.NewRecorder()
+	newAttachmentsRouter(t, actor, svc, blobs, 1<<20).
+		ServeHTTP(w, uploadRequest(t, roomID, "../../build.log", "line one\n", " the logs "))
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var wire hub.WireMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wire))
+	assert.Equal(t, hub.MessageTypeFile, wire.Type)
+	assert.Equal(t, "alice", wire.Author)
+	assert.Equal(t, &hub.Attachment{Name: "build.log", ContentType: "text/plain; charset=utf-8", Size: 9}, wire.Attachment)
+
+	assert.True(t, strings.HasPrefix(stored.Key, roomID.String()+"/"))
+	r, err := blobs.Get(context.Background(), stored.Key)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	_ = r.Close()
+	assert.Equal(t, "line one\n", string(data))
+}
+
+func TestAttachmentsHandler_Upload_Refused(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	t.Run("too large", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, mocks.NewMockChatService(t), newBlobStore(t), 8).
+			ServeHTTP(w, uploadRequest(t, roomID, "big.bin", strings.Repeat("x", 9), ""))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "FILE_TOO_LARGE", parseErrorResponse(t, w.Body.Bytes()).Code)
+	})
+
+	t.Run("not a member leaves nothing stored", func(t *testing.T) {
+		blobs := newBlobStore(t)
+		var key string
+		svc := mocks.NewMockChatService(t)
+		svc.EXPECT().PersistAttachment(actor.ID, roomID, mock.Anything, mock.Anything).
+			RunAndReturn(func(_, _ uuid.UUID, _ []byte, att service.AttachmentInfo) (*service.MessageInfo, error) {
+				key = att.Key
+				return nil, service.ErrNotRoomMember
+			})
+
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, svc, blobs, 1<<20).
+			ServeHTTP(w, uploadRequest(t, roomID, "a.txt", "hi", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		_, err := blobs.Get(context.Background(), key)
+		assert.ErrorIs(t, err, blob.ErrNotFound)
+	})
+
+	t.Run("encrypted room", func(t *testing.T) {
+		svc := mocks.NewMockChatService(t)
+		svc.EXPECT().PersistAttachment(actor.ID, roomID, mock.Anything, mock.Anything).Return(nil, service.ErrNotEncrypted)
+
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, svc, newBlobStore(t), 1<<20).
+			ServeHTTP(w, uploadRequest(t, roomID, "a.txt", "hi", ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "ENCRYPTED_ROOM", parseErrorResponse(t, w.Body.Bytes()).Code)
+	})
+}
+
+func TestAttachmentsHandler_Download(t *testing.T) {
+	msgID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	blobs := newBlobStore(t)
+	require.NoError(t, blobs.Put(context.Background(), "room/blob", strings.NewReader("PNG")))
+
+	t.Run("streams the file", func(t *testing.T) {
+		svc := mocks.NewMockChatService(t)
+		svc.EXPECT().GetAttachment(msgID, actor.ID).Return(&service.MessageInfo{
+			Attachment: &service.AttachmentInfo{Key: "room/blob", Name: "shot.png", ContentType: "image/png", Size: 3},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, svc, blobs, 1<<20).
+			ServeHTTP(w, authedRequest(http.MethodGet, "/attachments/"+msgID.String(), ""))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "PNG", w.Body.String())
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=shot.png`, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		svc := mocks.NewMockChatService(t)
+		svc.EXPECT().GetAttachment(msgID, actor.ID).Return(nil, service.ErrNotRoomMember)
+
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, svc, blobs, 1<<20).
+			ServeHTTP(w, authedRequest(http.MethodGet, "/attachments/"+msgID.String(), ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("blob missing", func(t *testing.T) {
+		svc := mocks.NewMockChatService(t)
+		svc.EXPECT().GetAttachment(msgID, actor.ID).Return(&service.MessageInfo{
+			Attachment: &service.AttachmentInfo{Key: "room/gone", Name: "gone.txt"},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		newAttachmentsRouter(t, actor, svc, blobs, 1<<20).
+			ServeHTTP(w, authedRequest(http.MethodGet, "/attachments/"+msgID.String(), ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BotStore interface {
+	Create(user *repository.User) error
+	GetByID(id uuid.UUID) (*repository.User, error)
+	GetByName(name string) (*repository.User, error)
+	ListBots(ownerID uuid.UUID) ([]repository.User, error)
+	Delete(id uuid.UUID) error
+}
+
+// BotsHandler lets human users create bot accounts, whose API keys are
+// limited to the scopes they are given.
+type BotsHandler struct {
+	users BotStore
+}
+
+func NewBotsHandler(users BotStore) *BotsHandler {
+	return &BotsHandler{users: users}
+}
+
+type createBotRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type botResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	// APIKey is only returned when the bot is created.
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// Create adds a bot owned by the caller. The response carries its API key,
+// which is not shown again.
+func (h *BotsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	owner := middleware.UserFromContext(r.Context())
+	if owner.IsBot() {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "bots cannot create bots")
+		return
+	}
+
+	var req createBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "NAME_REQUIRED", "name is required")
+		return
+	}
+	if len(name) > limits.MaxUserNameLength {
+		writeError(w, http.StatusBadRequest, "NAME_TOO_LONG", fmt.Sprintf("name must be %d characters or fewer", limits.MaxUserNameLength))
+		return
+	}
+
+	scopes, ok := parseScopes(req.Scopes)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_SCOPES", "scopes must be one or more of "+strings.Join(repository.Scopes, ", "))
+		return
+	}
+
+	if _, err := h.users.GetByName(name); err == nil {
+		writeError(w, http.StatusConflict, "NAME_TAKEN", "name is already taken")
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to check name")
+		return
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to generate api key")
+		return
+	}
+
+	bot := &repository.User{
+		Name:    name,
+		APIKey:  repository.HashAPIKey(apiKey),
+		Kind:    repository.UserKindBot,
+		Scopes:  strings.Join(scopes, " "),
+		OwnerID: &owner.ID,
+	}
+	if err := h.users.Create(bot); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create bot")
+		return
+	}
+
+	resp := toBotResponse(*bot)
+	resp.APIKey = apiKey
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// List returns the caller's bots.
+func (h *BotsHandler) List(w http.ResponseWriter, r *http.Request) {
+	owner := middleware.UserFromContext(r.Context())
+	bots, err := h.users.ListBots(owner.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list bots")
+		return
+	}
+
+	resp := make([]botResponse, len(bots))
+	for i, bot := range bots {
+		resp[i] = toBotResponse(bot)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Delete removes one of the caller's bots, revoking its API key. Connections
+// it already has open stay up until they close.
+func (h *BotsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	botID, err := uuid.Parse(chi.URLParam(r, "botID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BOT_ID", "invalid bot id")
+		return
+	}
+
+	owner := middleware.UserFromContext(r.Context())
+	bot, err := h.users.GetByID(botID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get bot")
+		return
+	}
+	// Users and other people's bots are reported as missing too, so that
+	// IDs cannot be probed.
+	if err != nil || !bot.IsBot() || bot.OwnerID == nil || *bot.OwnerID != owner.ID {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "bot not found")
+		return
+	}
+
+	if err := h.users.Delete(bot.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to delete bot")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseScopes checks that every requested scope exists, returning them
+// without duplicates in the order repository.Scopes lists them.
+func parseScopes(requested []string) ([]string, bool) {
+	if len(requested) == 0 {
+		return nil, false
+	}
+	for _, scope := range requested {
+		if !slices.Contains(repository.Scopes, scope) {
+			return nil, false
+		}
+	}
+	var scopes []string
+	for _, scope := range repository.Scopes {
+		if slices.Contains(requested, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, true
+}
+
+func toBotResponse(bot repository.User) botResponse {
+	return botResponse{
+		ID:        bot.ID.String(),
+		Name:      bot.Name,
+		Scopes:    strings.Fields(bot.Scopes),
+		CreatedAt: bot.CreatedAt,
+	}
+}
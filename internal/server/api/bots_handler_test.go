@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newBotsRouter(t *testing.T, actor *repository.User, bots BotStore) http.Handler {
+	bh := NewBotsHandler(bots)
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler { return authenticatedAs(t, actor, next) })
+	r.Post("/bots", bh.Create)
+	r.Delete("/bots/{botID}", bh.Delete)
+	return r
+}
+
+func TestBotsHandler_Create(t *testing.T) {
+	owner := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Kind: repository.UserKindHuman}
+
+	tests := []struct {
+		name       string
+		actor      *repository.User
+		body       string
+		setup      func(*mocks.MockBotStore)
+		wantStatus int
+		wantCode   string
+		wantScopes []string
+	}{
+		{
+			name:  "creates a bot with its scopes",
+			actor: owner,
+			body:  `{"name":"echo","scopes":["messages:write","rooms:read","messages:write"]}`,
+			setup: func(m *mocks.MockBotStore) {
+				m.EXPECT().GetByName("echo").Return(nil, gorm.ErrRecordNotFound)
+				m.EXPECT().Create(mock.MatchedBy(func(u *repository.User) bool {
+					return u.Kind == repository.UserKindBot && u.Scopes == "rooms:read messages:write" && *u.OwnerID == owner.ID
+				})).Return(nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantScopes: []string{"rooms:read", "messages:write"},
+		},
+		{
+			name:       "rejects unknown scopes",
+			actor:      owner,
+			body:       `{"name":"echo","scopes":["admin"]}`,
+			setup:      func(*mocks.MockBotStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_SCOPES",
+		},
+		{
+			name:       "needs at least one scope",
+			actor:      owner,
+			body:       `{"name":"echo"}`,
+			setup:      func(*mocks.MockBotStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_SCOPES",
+		},
+		{
+			name:  "name taken",
+			actor: owner,
+			body:  `{"name":"alice","scopes":["rooms:read"]}`,
+			setup: func(m *mocks.MockBotStore) {
+				m.EXPECT().GetByName("alice").Return(&repository.User{}, nil)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "NAME_TAKEN",
+		},
+		{
+			name:       "bots cannot create bots",
+			actor:      &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Kind: repository.UserKindBot},
+			body:       `{"name":"echo","scopes":["rooms:read"]}`,
+			setup:      func(*mocks.MockBotStore) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "FORBIDDEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bots := mocks.NewMockBotStore(t)
+			tt.setup(bots)
+
+			w := httptest.NewRecorder()
+			newBotsRouter(t, tt.actor, bots).ServeHTTP(w, authedRequest(http.MethodPost, "/bots", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+				return
+			}
+			var resp botResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.APIKey, 64)
+			assert.Equal(t, tt.wantScopes, resp.Scopes)
+		})
+	}
+}
+
+func TestBotsHandler_Delete_OnlyOwnBots(t *testing.T) {
+	owner := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	otherOwner := uuid.New()
+	mine := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Kind: repository.UserKindBot, OwnerID: &owner.ID}
+	theirs := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Kind: repository.UserKindBot, OwnerID: &otherOwner}
+
+	bots := mocks.NewMockBotStore(t)
+	bots.EXPECT().GetByID(mine.ID).Return(mine, nil)
+	bots.EXPECT().GetByID(theirs.ID).Return(theirs, nil)
+	bots.EXPECT().Delete(mine.ID).Return(nil)
+	router := newBotsRouter(t, owner, bots)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodDelete, "/bots/"+mine.ID.String(), ""))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodDelete, "/bots/"+theirs.ID.String(), ""))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DMsHandler struct {
+	svc   ChatService
+	users UserDirectory
+}
+
+func NewDMsHandler(svc ChatService, users UserDirectory) *DMsHandler {
+	return &DMsHandler{svc: svc, users: users}
+}
+
+type openDMRequest struct {
+	UserIDs []string `json:"user_ids"`
+	Names   []string `json:"names"`
+}
+
+// Open returns the direct message room between the caller and the given users,
+// identified by user_ids or names, creating it on first use.
+func (h *DMsHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req openDMRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	if len(req.UserIDs) == 0 && len(req.Names) == 0 {
+		writeError(w, http.StatusBadRequest, "USER_REQUIRED", "user_ids or names is required")
+		return
+	}
+
+	var participants []repository.User
+	for _, raw := range req.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_USER_ID", "invalid user id")
+			return
+		}
+		user, err := h.users.GetByID(id)
+		if !h.checkLookup(w, err) {
+			return
+		}
+		participants = append(participants, *user)
+	}
+	for _, name := range req.Names {
+		user, err := h.users.GetByName(name)
+		if !h.checkLookup(w, err) {
+			return
+		}
+		participants = append(participants, *user)
+	}
+
+	participantIDs := make([]uuid.UUID, len(participants))
+	for i, p := range participants {
+		participantIDs[i] = p.ID
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	room, created, err := h.svc.OpenDM(actor.ID, participantIDs)
+	if err != nil {
+		writeServiceError(w, err, "failed to open direct message")
+		return
+	}
+
+	resp := roomResponse{
+		ID:         room.ID.String(),
+		Name:       dmName(participants, actor.ID),
+		Visibility: room.Visibility,
+		Kind:       room.Kind,
+		Encrypted:  room.Encrypted,
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *DMsHandler) checkLookup(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+		return false
+	}
+	writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to look up user")
+	return false
+}
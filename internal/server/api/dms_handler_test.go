@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newDMsRouter(t *testing.T, actor *repository.User, svc ChatService, dir UserDirectory) http.Handler {
+	h := NewDMsHandler(svc, dir)
+	r := chi.NewRouter()
+	r.Post("/dms", h.Open)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestDMsHandler_OpenByName(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+	bob := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "bob"}
+	roomID := uuid.New()
+
+	tests := []struct {
+		name       string
+		created    bool
+		wantStatus int
+	}{
+		{name: "creates new conversation", created: true, wantStatus: http.StatusCreated},
+		{name: "returns existing conversation", created: false, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			svc.EXPECT().OpenDM(actor.ID, []uuid.UUID{bob.ID}).Return(&service.RoomInfo{
+				ID:         roomID,
+				Visibility: repository.RoomVisibilityPrivate,
+				Kind:       repository.RoomKindDM,
+			}, tt.created, nil)
+			dir := mocks.NewMockUserDirectory(t)
+			dir.EXPECT().GetByName("bob").Return(bob, nil)
+
+			router := newDMsRouter(t, actor, svc, dir)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, authedRequest(http.MethodPost, "/dms", `{"names":["bob"]}`))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			var resp roomResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, roomResponse{
+				ID:         roomID.String(),
+				Name:       "bob",
+				Visibility: repository.RoomVisibilityPrivate,
+				Kind:       repository.RoomKindDM,
+			}, resp)
+		})
+	}
+}
+
+func TestDMsHandler_UnknownUser(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	dir := mocks.NewMockUserDirectory(t)
+	dir.EXPECT().GetByName("nobody").Return(nil, gorm.ErrRecordNotFound)
+
+	router := newDMsRouter(t, actor, mocks.NewMockChatService(t), dir)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPost, "/dms", `{"names":["nobody"]}`))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "USER_NOT_FOUND", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestDMsHandler_InvalidParticipants(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().OpenDM(actor.ID, []uuid.UUID{actor.ID}).Return(nil, false, service.ErrInvalidDM)
+	dir := mocks.NewMockUserDirectory(t)
+	dir.EXPECT().GetByName("alice").Return(actor, nil)
+
+	router := newDMsRouter(t, actor, svc, dir)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPost, "/dms", `{"names":["alice"]}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "INVALID_PARTICIPANTS", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
@@ -2,7 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/EwanGreer/chatatui/internal/service"
+	"gorm.io/gorm"
 )
 
 type apiError struct {
@@ -15,3 +19,26 @@ func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(apiError{Error: message, Code: code})
 }
+
+// writeServiceError maps errors returned by the service layer to API errors,
+// falling back to a 500 with message for anything unexpected.
+func writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+	case errors.Is(err, service.ErrNotRoomMember):
+		writeError(w, http.StatusForbidden, "NOT_A_MEMBER", "you are not a member of this room")
+	case errors.Is(err, service.ErrForbidden):
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "you do not have permission to do that")
+	case errors.Is(err, service.ErrInvalidRole):
+		writeError(w, http.StatusBadRequest, "INVALID_ROLE", "role must be moderator or member")
+	case errors.Is(err, service.ErrInvalidDM):
+		writeError(w, http.StatusBadRequest, "INVALID_PARTICIPANTS", "direct messages need 1 to 7 other participants")
+	case errors.Is(err, service.ErrInvalidWebhook):
+		writeError(w, http.StatusBadRequest, "INVALID_WEBHOOK", "webhooks are incoming, or outgoing with an http(s) url; encrypted rooms have no incoming webhooks")
+	case errors.Is(err, service.ErrNameTaken):
+		writeError(w, http.StatusConflict, "NAME_TAKEN", "name is already taken")
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", message)
+	}
+}
@@ -0,0 +1,380 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Export formats. JSONL exports can be imported again; Markdown ones are
+// transcripts for people to read.
+const (
+	ExportFormatJSONL    = "jsonl"
+	ExportFormatMarkdown = "markdown"
+)
+
+// exportVersion is written in the first line of every JSONL export. Imports of
+// any other version are refused.
+const exportVersion = 1
+
+// exportPageSize is how many messages are loaded at a time while streaming an
+// export.
+const exportPageSize = 500
+
+// Line types in a JSONL export: one room, then its messages.
+const (
+	exportLineRoom    = "room"
+	exportLineMessage = "message"
+)
+
+type exportRoom struct {
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Topic      string    `json:"topic,omitempty"`
+	Visibility string    `json:"visibility"`
+	Kind       string    `json:"kind"`
+	Encrypted  bool      `json:"encrypted"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+type exportMessage struct {
+	Type       string            `json:"type"`
+	ID         uuid.UUID         `json:"id"`
+	ParentID   *uuid.UUID        `json:"parent_id,omitempty"`
+	Kind       string            `json:"kind"`
+	AuthorID   uuid.UUID         `json:"author_id"`
+	Author     string            `json:"author"`
+	Content    string            `json:"content"`
+	CreatedAt  time.Time         `json:"created_at"`
+	EditedAt   *time.Time        `json:"edited_at,omitempty"`
+	Attachment *exportAttachment `json:"attachment,omitempty"`
+}
+
+// exportAttachment describes a file message. Its contents are not exported.
+type exportAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type importResponse struct {
+	roomResponse
+	Messages int `json:"messages"`
+}
+
+type ExportHandler struct {
+	svc      ChatService
+	maxBytes int64
+}
+
+func NewExportHandler(svc ChatService, maxBytes int64) *ExportHandler {
+	return &ExportHandler{svc: svc, maxBytes: maxBytes}
+}
+
+// Export streams a room's whole history, thread replies included, oldest
+// first. ?format=jsonl, the default, writes the room and then each message as
+// a line of JSON, which Import accepts; ?format=markdown writes a transcript.
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", ExportFormatJSONL:
+		format = ExportFormatJSONL
+	case ExportFormatMarkdown:
+	default:
+		writeError(w, http.StatusBadRequest, "INVALID_FORMAT", "format must be jsonl or markdown")
+		return
+	}
+
+	room, err := h.svc.GetRoom(roomID)
+	if err != nil {
+		writeServiceError(w, err, "failed to get room")
+		return
+	}
+
+	// Load the first page before writing anything, so that a viewer who may
+	// not export the room gets an error rather than an empty export.
+	viewer := middleware.UserFromContext(r.Context())
+	page, err := h.svc.ExportMessages(roomID, viewer.ID, uuid.Nil, exportPageSize)
+	if err != nil {
+		writeServiceError(w, err, "failed to export room")
+		return
+	}
+
+	var out exportWriter
+	buf := bufio.NewWriter(w)
+	filename := room.Name
+	if filename == "" {
+		filename = room.ID.String()
+	}
+	if format == ExportFormatJSONL {
+		out = &jsonlExport{enc: json.NewEncoder(buf)}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		filename += ".jsonl"
+	} else {
+		out = &markdownExport{w: buf}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		filename += ".md"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	if err := out.room(room, time.Now().UTC()); err != nil {
+		return
+	}
+	for {
+		for _, m := range page {
+			if err := out.message(m); err != nil {
+				return
+			}
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+		page, err = h.svc.ExportMessages(roomID, viewer.ID, page[len(page)-1].ID, exportPageSize)
+		if err != nil {
+			// The response has begun, so abort it for the client to see
+			// that the export is incomplete.
+			slog.Error("failed to export room", "error", err, "room_id", roomID)
+			panic(http.ErrAbortHandler)
+		}
+	}
+	_ = buf.Flush()
+}
+
+// Import creates a room from a JSONL export in the request body, owned by the
+// caller, with ?name= overriding the exported room's name. The messages keep
+// their IDs, so an export can only be imported once per server.
+func (h *ExportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes)
+	dec := json.NewDecoder(r.Body)
+
+	var header exportRoom
+	if err := dec.Decode(&header); err != nil || header.Type != exportLineRoom {
+		writeImportError(w, err, "expected a JSONL export beginning with its room")
+		return
+	}
+	if header.Version != exportVersion {
+		writeError(w, http.StatusBadRequest, "UNSUPPORTED_VERSION", fmt.Sprintf("only version %d exports can be imported", exportVersion))
+		return
+	}
+
+	var messages []service.MessageInfo
+	for {
+		var m exportMessage
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil || m.Type != exportLineMessage {
+			writeImportError(w, err, "expected a message on every line after the room")
+			return
+		}
+		info := service.MessageInfo{
+			ID:        m.ID,
+			Author:    m.Author,
+			AuthorID:  m.AuthorID,
+			Content:   m.Content,
+			Kind:      m.Kind,
+			CreatedAt: m.CreatedAt,
+			EditedAt:  m.EditedAt,
+			ParentID:  m.ParentID,
+		}
+		if a := m.Attachment; a != nil {
+			info.Attachment = &service.AttachmentInfo{Name: a.Name, ContentType: a.ContentType, Size: a.Size}
+		}
+		messages = append(messages, info)
+	}
+
+	name := header.Name
+	if override := r.URL.Query().Get("name"); override != "" {
+		name = override
+	}
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "NAME_REQUIRED", "room name is required")
+		return
+	}
+	if len(name) > limits.MaxRoomNameLength {
+		writeError(w, http.StatusBadRequest, "NAME_TOO_LONG", fmt.Sprintf("room name must be %d characters or fewer", limits.MaxRoomNameLength))
+		return
+	}
+	if len(header.Topic) > limits.MaxTopicLength {
+		writeError(w, http.StatusBadRequest, "TOPIC_TOO_LONG", fmt.Sprintf("topic must be %d characters or fewer", limits.MaxTopicLength))
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	room, err := h.svc.ImportRoom(user.ID, service.RoomInfo{
+		Name:       name,
+		Topic:      header.Topic,
+		Visibility: header.Visibility,
+		Kind:       header.Kind,
+		Encrypted:  header.Encrypted,
+	}, messages)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidImport):
+			writeError(w, http.StatusBadRequest, "INVALID_EXPORT", err.Error())
+		case errors.Is(err, service.ErrAlreadyImported):
+			writeError(w, http.StatusConflict, "ALREADY_IMPORTED", "these messages have already been imported")
+		default:
+			writeServiceError(w, err, "failed to import room")
+		}
+		return
+	}
+
+	resp := importResponse{
+		roomResponse: roomResponse{
+			ID:         room.ID.String(),
+			Name:       room.Name,
+			Topic:      room.Topic,
+			Visibility: room.Visibility,
+			Kind:       room.Kind,
+			Encrypted:  room.Encrypted,
+		},
+		Messages: len(messages),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeImportError reports an import body that could not be read, telling
+// one that was too large apart from one that was malformed.
+func writeImportError(w http.ResponseWriter, err error, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", fmt.Sprintf("imports must be %d bytes or smaller", tooLarge.Limit))
+		return
+	}
+	writeError(w, http.StatusBadRequest, "INVALID_EXPORT", message)
+}
+
+// exportWriter writes an export in one of the formats: the room first, then
+// each of its messages.
+type exportWriter interface {
+	room(room *service.RoomInfo, exportedAt time.Time) error
+	message(m service.MessageInfo) error
+}
+
+type jsonlExport struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlExport) room(room *service.RoomInfo, exportedAt time.Time) error {
+	return e.enc.Encode(exportRoom{
+		Type:       exportLineRoom,
+		Version:    exportVersion,
+		ID:         room.ID.String(),
+		Name:       room.Name,
+		Topic:      room.Topic,
+		Visibility: room.Visibility,
+		Kind:       room.Kind,
+		Encrypted:  room.Encrypted,
+		ExportedAt: exportedAt,
+	})
+}
+
+func (e *jsonlExport) message(m service.MessageInfo) error {
+	line := exportMessage{
+		Type:      exportLineMessage,
+		ID:        m.ID,
+		ParentID:  m.ParentID,
+		Kind:      m.Kind,
+		AuthorID:  m.AuthorID,
+		Author:    m.Author,
+		Content:   m.Content,
+		CreatedAt: m.CreatedAt,
+		EditedAt:  m.EditedAt,
+	}
+	if a := m.Attachment; a != nil {
+		line.Attachment = &exportAttachment{Name: a.Name, ContentType: a.ContentType, Size: a.Size}
+	}
+	return e.enc.Encode(line)
+}
+
+// markdownExport writes a transcript with a heading for each day, in UTC, and
+// a list item for each message.
+type markdownExport struct {
+	w   io.Writer
+	day string
+}
+
+func (e *markdownExport) room(room *service.RoomInfo, exportedAt time.Time) error {
+	title := room.Name
+	if title == "" {
+		title = room.ID.String()
+	}
+	text := "# " + title + "\n\n"
+	if room.Topic != "" {
+		text += "Topic: " + room.Topic + "\n\n"
+	}
+	text += "Exported " + exportedAt.Format("2006-01-02 15:04 UTC") + ". Times are in UTC.\n"
+	if room.Encrypted {
+		text += "\nThis room is end-to-end encrypted, so what its messages say could not be included.\n"
+	}
+	_, err := io.WriteString(e.w, text)
+	return err
+}
+
+func (e *markdownExport) message(m service.MessageInfo) error {
+	var text string
+	if day := m.CreatedAt.UTC().Format("2006-01-02"); day != e.day {
+		e.day = day
+		text = "\n## " + day + "\n\n"
+	}
+
+	content := m.Content
+	if e2e.IsEnvelope([]byte(content)) {
+		content = "🔒 encrypted message"
+	}
+	line := m.CreatedAt.UTC().Format("15:04") + " "
+	if m.ParentID != nil {
+		line += "↳ "
+	}
+	switch m.Kind {
+	case repository.MessageKindAction:
+		line += "_* " + m.Author + " " + content + "_"
+	case repository.MessageKindFile:
+		line += "**" + m.Author + "** sent "
+		if m.Attachment != nil {
+			line += "`" + m.Attachment.Name + "`"
+		} else {
+			line += "a file"
+		}
+		if content != "" {
+			line += ": " + content
+		}
+	default:
+		line += "**" + m.Author + "**: " + content
+	}
+	if m.EditedAt != nil {
+		line += " _(edited)_"
+	}
+	// Indent any further lines of the message to keep them in its item.
+	text += "- " + strings.ReplaceAll(line, "\n", "\n  ") + "\n"
+	_, err := io.WriteString(e.w, text)
+	return err
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportRouter(t *testing.T, actor *repository.User, svc ChatService, maxBytes int64) http.Handler {
+	h := NewExportHandler(svc, maxBytes)
+	r := chi.NewRouter()
+	r.Get("/rooms/{roomID}/export", h.Export)
+	r.Post("/rooms/import", h.Import)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestExportHandler_RoundTrip(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+	parentID := uuid.New()
+	sent := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	edited := sent.Add(time.Hour)
+	messages := []service.MessageInfo{
+		{ID: parentID, Author: "alice", AuthorID: actor.ID, Content: "hello", Kind: repository.MessageKindChat, CreatedAt: sent, EditedAt: &edited},
+		{ID: uuid.New(), Author: "bob", AuthorID: uuid.New(), Content: "hi", Kind: repository.MessageKindChat, CreatedAt: sent, ParentID: &parentID},
+		{
+			ID: uuid.New(), Author: "bob", AuthorID: uuid.New(), Kind: repository.MessageKindFile, CreatedAt: sent,
+			Attachment: &service.AttachmentInfo{Name: "a.txt", ContentType: "text/plain", Size: 3},
+		},
+	}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID, Name: "general", Topic: "hello", Visibility: repository.RoomVisibilityPublic, Kind: repository.RoomKindRoom}, nil)
+	svc.EXPECT().ExportMessages(roomID, actor.ID, uuid.Nil, exportPageSize).Return(messages, nil)
+
+	w := httptest.NewRecorder()
+	newExportRouter(t, actor, svc, 1<<20).ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/export", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "general.jsonl")
+	assert.Len(t, strings.Split(strings.TrimSpace(w.Body.String()), "\n"), 4)
+
+	svc.EXPECT().ImportRoom(actor.ID, mock.Anything, mock.Anything).
+		Run(func(_ uuid.UUID, room service.RoomInfo, imported []service.MessageInfo) {
+			assert.Equal(t, "restored", room.Name)
+			assert.Equal(t, "hello", room.Topic)
+			assert.Equal(t, repository.RoomVisibilityPublic, room.Visibility)
+			require.Len(t, imported, len(messages))
+			for i := range messages {
+				assert.Equal(t, messages[i].ID, imported[i].ID)
+				assert.Equal(t, messages[i].AuthorID, imported[i].AuthorID)
+				assert.Equal(t, messages[i].Author, imported[i].Author)
+				assert.Equal(t, messages[i].Content, imported[i].Content)
+				assert.True(t, messages[i].CreatedAt.Equal(imported[i].CreatedAt))
+				assert.Equal(t, messages[i].ParentID, imported[i].ParentID)
+				assert.Equal(t, messages[i].Attachment, imported[i].Attachment)
+			}
+			assert.True(t, edited.Equal(*imported[0].EditedAt))
+		}).
+		Return(&service.RoomInfo{ID: uuid.New(), Name: "restored", Visibility: repository.RoomVisibilityPublic, Kind: repository.RoomKindRoom}, nil)
+
+	w2 := httptest.NewRecorder()
+	newExportRouter(t, actor, svc, 1<<20).ServeHTTP(w2, authedRequest(http.MethodPost, "/rooms/import?name=restored", w.Body.String()))
+	require.Equal(t, http.StatusCreated, w2.Code, w2.Body.String())
+	assert.Contains(t, w2.Body.String(), `"messages":3`)
+}
+
+func TestExportHandler_Markdown(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+	sent := time.Date(2024, 3, 1, 9, 5, 0, 0, time.UTC)
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID, Name: "general"}, nil)
+	svc.EXPECT().ExportMessages(roomID, actor.ID, uuid.Nil, exportPageSize).Return([]service.MessageInfo{
+		{ID: uuid.New(), Author: "alice", Content: "hello\nworld", Kind: repository.MessageKindChat, CreatedAt: sent},
+		{ID: uuid.New(), Author: "bob", Content: "waves", Kind: repository.MessageKindAction, CreatedAt: sent.Add(24 * time.Hour)},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newExportRouter(t, actor, svc, 1<<20).ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/export?format=markdown", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "# general\n"))
+	assert.Contains(t, body, "## 2024-03-01\n\n- 09:05 **alice**: hello\n  world\n")
+	assert.Contains(t, body, "## 2024-03-02\n\n- 09:05 _* bob waves_\n")
+}
+
+func TestExportHandler_ExportErrors(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+
+	tests := []struct {
+		name       string
+		target     string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "unknown format",
+			target:     "/rooms/" + roomID.String() + "/export?format=pdf",
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_FORMAT",
+		},
+		{
+			name:   "private room the viewer is not in",
+			target: "/rooms/" + roomID.String() + "/export",
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID}, nil)
+				m.EXPECT().ExportMessages(roomID, actor.ID, uuid.Nil, exportPageSize).Return(nil, service.ErrNotRoomMember)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "NOT_A_MEMBER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newExportRouter(t, actor, svc, 1<<20).ServeHTTP(w, authedRequest(http.MethodGet, tt.target, ""))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+		})
+	}
+}
+
+func TestExportHandler_ImportErrors(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	header := `{"type":"room","version":1,"name":"general","visibility":"public","kind":"room"}` + "\n"
+	message := `{"type":"message","id":"` + uuid.NewString() + `","kind":"chat","author_id":"` + uuid.NewString() + `","author":"bob","content":"hi","created_at":"2024-03-01T09:00:00Z"}` + "\n"
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		maxBytes   int64
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "not an export",
+			body:       "hello",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_EXPORT",
+		},
+		{
+			name:       "messages before the room",
+			body:       message + header,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_EXPORT",
+		},
+		{
+			name:       "unknown version",
+			body:       strings.Replace(header, `"version":1`, `"version":2`, 1),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "UNSUPPORTED_VERSION",
+		},
+		{
+			name:       "too large",
+			body:       header + message,
+			maxBytes:   int64(len(header) + 10),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   "IMPORT_TOO_LARGE",
+		},
+		{
+			name:       "name too long",
+			target:     "/rooms/import?name=" + strings.Repeat("x", 16),
+			body:       header,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "NAME_TOO_LONG",
+		},
+		{
+			name: "invalid messages",
+			body: header + message,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().ImportRoom(actor.ID, mock.Anything, mock.Anything).Return(nil, service.ErrInvalidImport)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_EXPORT",
+		},
+		{
+			name: "already imported",
+			body: header + message,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().ImportRoom(actor.ID, mock.Anything, mock.Anything).Return(nil, service.ErrAlreadyImported)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "ALREADY_IMPORTED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			if tt.setup != nil {
+				tt.setup(svc)
+			}
+			target := tt.target
+			if target == "" {
+				target = "/rooms/import"
+			}
+			maxBytes := tt.maxBytes
+			if maxBytes == 0 {
+				maxBytes = 1 << 20
+			}
+
+			w := httptest.NewRecorder()
+			newExportRouter(t, actor, svc, maxBytes).ServeHTTP(w, authedRequest(http.MethodPost, target, tt.body))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+		})
+	}
+}
@@ -3,8 +3,10 @@ package api
 import (
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/blob"
 	"github.com/EwanGreer/chatatui/internal/config"
 	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
 	"github.com/EwanGreer/chatatui/internal/server/hub"
 	"github.com/EwanGreer/chatatui/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -14,9 +16,31 @@ import (
 
 type ChatService interface {
 	GetRoom(id uuid.UUID) (*service.RoomInfo, error)
-	AddRoomMember(roomID, userID uuid.UUID) error
-	GetMessageHistory(roomID uuid.UUID, limit, offset int) ([]service.MessageInfo, error)
-	PersistMessage(content []byte, senderID, roomID uuid.UUID) (uuid.UUID, time.Time, error)
+	JoinRoom(roomID, userID uuid.UUID) error
+	InviteMember(roomID, actorID, userID uuid.UUID) error
+	RemoveMember(roomID, actorID, userID uuid.UUID) error
+	SetMemberRole(roomID, actorID, userID uuid.UUID, role string) error
+	ListMembers(roomID, actorID uuid.UUID) ([]service.MemberInfo, error)
+	RoomKeys(roomID, actorID uuid.UUID) ([]service.MemberKey, error)
+	OpenDM(actorID uuid.UUID, participantIDs []uuid.UUID) (*service.RoomInfo, bool, error)
+	GetMessagesBefore(roomID, viewerID, before uuid.UUID, limit int) (service.MessagePage, error)
+	SetTopic(roomID, actorID uuid.UUID, topic string) error
+	SetRetention(roomID, actorID uuid.UUID, retention repository.Retention) error
+	GetThread(roomID, viewerID, parentID uuid.UUID) ([]service.MessageInfo, error)
+	PersistMessage(content []byte, kind string, senderID, roomID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)
+	PersistReply(content []byte, senderID, roomID, parentID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error)
+	EditMessage(id, senderID, roomID uuid.UUID, content []byte) (time.Time, error)
+	DeleteMessage(id, senderID, roomID uuid.UUID) error
+	React(messageID, userID, roomID uuid.UUID, emoji string) (bool, error)
+	Unreact(messageID, userID, roomID uuid.UUID, emoji string) (bool, error)
+	MarkRead(roomID, userID, messageID uuid.UUID) error
+	UnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error)
+	SearchMessages(actorID uuid.UUID, query service.SearchQuery) ([]service.MessageInfo, error)
+	Mentions(userID, before uuid.UUID, limit int) ([]service.MessageInfo, error)
+	PersistAttachment(senderID, roomID uuid.UUID, caption []byte, att service.AttachmentInfo) (*service.MessageInfo, error)
+	GetAttachment(id, viewerID uuid.UUID) (*service.MessageInfo, error)
+	ExportMessages(roomID, viewerID, after uuid.UUID, limit int) ([]service.MessageInfo, error)
+	ImportRoom(actorID uuid.UUID, room service.RoomInfo, messages []service.MessageInfo) (*service.RoomInfo, error)
 }
 
 type Handler struct {
@@ -29,14 +53,37 @@ type Handler struct {
 	wsHandler       *WSHandler
 	registerHandler *RegisterHandler
 	roomsHandler    *RoomsHandler
+	messagesHandler *MessagesHandler
+	membersHandler  *MembersHandler
+	dmsHandler      *DMsHandler
+	unreadHandler   *UnreadHandler
+	searchHandler   *SearchHandler
+	mentionsHandler *MentionsHandler
+	topicHandler    *TopicHandler
+	retention       *RetentionHandler
+	usersHandler    *UsersHandler
+	botsHandler     *BotsHandler
+	attachments     *AttachmentsHandler
+	webhooks        *WebhooksHandler
+	exports         *ExportHandler
 }
 
-func NewHandler(h *hub.Hub, users middleware.UserLookup, userStore UserStore, roomStore RoomStore, svc ChatService, cfg config.ServerConfig, rl *middleware.RateLimiter) *Handler {
+func NewHandler(h *hub.Hub, users middleware.UserLookup, userStore UserStore, bots BotStore, userDir UserDirectory, roomStore RoomStore, svc ChatService, hooks WebhookService, blobs blob.Store, cfg config.ServerConfig, rl *middleware.RateLimiter) *Handler {
 	r := chi.NewRouter()
 	r.Use(chimw.Logger)
 	r.Use(chimw.Recoverer)
 	r.Use(chimw.Heartbeat("/up"))
 
+	flood := hub.NewFloodControl(hub.FloodConfig{
+		Rate:            cfg.FloodRatePerSec,
+		Burst:           cfg.FloodBurst,
+		MuteAfter:       cfg.FloodMuteAfter,
+		MuteFor:         time.Duration(cfg.FloodMuteSecs) * time.Second,
+		DisconnectAfter: cfg.FloodDisconnectAfter,
+	})
+
+	retention := repository.Retention{MaxAgeDays: cfg.RetentionMaxAgeDays, MaxMessages: cfg.RetentionMaxMessages}
+
 	return &Handler{
 		Router:          r,
 		Hub:             h,
@@ -44,14 +91,28 @@ func NewHandler(h *hub.Hub, users middleware.UserLookup, userStore UserStore, ro
 		Config:          cfg,
 		RateLimiter:     rl,
 		userLookup:      users,
-		wsHandler:       NewWSHandler(h, svc, cfg.MessageHistoryLimit),
+		wsHandler:       NewWSHandler(h, svc, cfg.MessageHistoryLimit, cfg.ReadReceipts, flood),
 		registerHandler: NewRegisterHandler(userStore),
-		roomsHandler:    NewRoomsHandler(roomStore, cfg.RoomListLimit),
+		roomsHandler:    NewRoomsHandler(roomStore, cfg.RoomListLimit, retention),
+		messagesHandler: NewMessagesHandler(svc, cfg.MessageHistoryLimit),
+		membersHandler:  NewMembersHandler(h, svc, userDir),
+		dmsHandler:      NewDMsHandler(svc, userDir),
+		unreadHandler:   NewUnreadHandler(svc),
+		searchHandler:   NewSearchHandler(svc, cfg.MessageHistoryLimit),
+		mentionsHandler: NewMentionsHandler(svc, cfg.MessageHistoryLimit),
+		topicHandler:    NewTopicHandler(h, svc),
+		retention:       NewRetentionHandler(svc, retention),
+		usersHandler:    NewUsersHandler(userStore),
+		botsHandler:     NewBotsHandler(bots),
+		attachments:     NewAttachmentsHandler(h, svc, blobs, cfg.MaxAttachmentBytes),
+		webhooks:        NewWebhooksHandler(h, svc, hooks, cfg.MessageHistoryLimit),
+		exports:         NewExportHandler(svc, cfg.MaxImportBytes),
 	}
 }
 
 func (h *Handler) Routes() chi.Router {
 	h.Router.Post("/register", h.registerHandler.Handle)
+	h.Router.With(h.webhooks.Authenticate, h.RateLimiter.Middleware).Post("/hooks/{token}", h.webhooks.Incoming)
 
 	h.Router.Group(func(r chi.Router) {
 		r.Use(
@@ -59,9 +120,52 @@ func (h *Handler) Routes() chi.Router {
 			h.RateLimiter.Middleware,
 		)
 
-		r.Get("/rooms", h.roomsHandler.List)
-		r.Post("/rooms", h.roomsHandler.Create)
-		r.Get("/ws/{roomID}", h.wsHandler.Handle)
+		// Bots may only call routes that their API key's scopes allow. These
+		// first few, which say who the caller is and manage bots, need none.
+		r.Get("/users/me", h.usersHandler.Me)
+		r.Get("/bots", h.botsHandler.List)
+		r.Post("/bots", h.botsHandler.Create)
+		r.Delete("/bots/{botID}", h.botsHandler.Delete)
+
+		r.With(middleware.RequireScope(repository.ScopeRoomsRead)).Group(func(r chi.Router) {
+			r.Get("/rooms", h.roomsHandler.List)
+			r.Get("/rooms/{roomID}/members", h.membersHandler.List)
+			r.Get("/rooms/{roomID}/keys", h.membersHandler.Keys)
+			r.Get("/rooms/{roomID}/webhooks", h.webhooks.List)
+			r.Get("/rooms/{roomID}/webhooks/{webhookID}/deliveries", h.webhooks.Deliveries)
+			r.Get("/unread", h.unreadHandler.List)
+		})
+		r.With(middleware.RequireScope(repository.ScopeRoomsWrite)).Group(func(r chi.Router) {
+			r.Post("/rooms", h.roomsHandler.Create)
+			r.Post("/rooms/import", h.exports.Import)
+			r.Put("/rooms/{roomID}/topic", h.topicHandler.Set)
+			r.Put("/rooms/{roomID}/retention", h.retention.Set)
+			r.Post("/rooms/{roomID}/members", h.membersHandler.Invite)
+			r.Put("/rooms/{roomID}/members/{userID}", h.membersHandler.SetRole)
+			r.Delete("/rooms/{roomID}/members/{userID}", h.membersHandler.Remove)
+			r.Post("/dms", h.dmsHandler.Open)
+			r.Post("/rooms/{roomID}/webhooks", h.webhooks.Create)
+			r.Delete("/rooms/{roomID}/webhooks/{webhookID}", h.webhooks.Delete)
+		})
+		r.With(middleware.RequireScope(repository.ScopeMessagesRead)).Group(func(r chi.Router) {
+			r.Get("/rooms/{roomID}/messages", h.messagesHandler.List)
+			r.Put("/rooms/{roomID}/read", h.unreadHandler.MarkRead)
+			r.Get("/search", h.searchHandler.Search)
+			r.Get("/mentions", h.mentionsHandler.List)
+			r.Get("/attachments/{messageID}", h.attachments.Download)
+			r.Get("/rooms/{roomID}/export", h.exports.Export)
+			// Sending over the WebSocket also needs messages:write, which
+			// the connection checks for itself.
+			r.Get("/ws", h.wsHandler.HandleMultiplexed)
+			r.Get("/ws/{roomID}", h.wsHandler.Handle)
+		})
+		r.With(middleware.RequireScope(repository.ScopeMessagesWrite)).Group(func(r chi.Router) {
+			r.Post("/rooms/{roomID}/attachments", h.attachments.Upload)
+		})
+		r.With(middleware.RequireScope(repository.ScopeProfileWrite)).Group(func(r chi.Router) {
+			r.Put("/users/me", h.usersHandler.UpdateMe)
+			r.Put("/users/me/key", h.usersHandler.SetKey)
+		})
 	})
 
 	return h.Router
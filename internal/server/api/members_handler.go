@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserDirectory interface {
+	GetByID(id uuid.UUID) (*repository.User, error)
+	GetByName(name string) (*repository.User, error)
+}
+
+type MembersHandler struct {
+	hub   *hub.Hub
+	svc   ChatService
+	users UserDirectory
+}
+
+func NewMembersHandler(h *hub.Hub, svc ChatService, users UserDirectory) *MembersHandler {
+	return &MembersHandler{hub: h, svc: svc, users: users}
+}
+
+type memberResponse struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+}
+
+type memberKeyResponse struct {
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+type inviteRequest struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type setRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// List returns the room's members with their role and whether they are
+// online, away or offline.
+func (h *MembersHandler) List(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	members, err := h.svc.ListMembers(roomID, actor.ID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list members")
+		return
+	}
+
+	presence := h.hub.Presence(roomID)
+
+	resp := make([]memberResponse, len(members))
+	for i, m := range members {
+		status, ok := presence[m.UserID]
+		if !ok {
+			status = hub.StatusOffline
+		}
+		resp[i] = memberResponse{
+			UserID: m.UserID.String(),
+			Name:   m.Name,
+			Role:   m.Role,
+			Status: status,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Keys returns the public keys of the room's members, which clients encrypt
+// messages to in encrypted rooms. Members without a key are left out.
+func (h *MembersHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	keys, err := h.svc.RoomKeys(roomID, actor.ID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list keys")
+		return
+	}
+
+	resp := make([]memberKeyResponse, len(keys))
+	for i, k := range keys {
+		resp[i] = memberKeyResponse{UserID: k.UserID.String(), Name: k.Name, PublicKey: k.PublicKey}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Invite adds a user, identified by user_id or name, to the room.
+func (h *MembersHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	var req inviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	invitee, ok := h.resolveUser(w, req)
+	if !ok {
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.svc.InviteMember(roomID, actor.ID, invitee.ID); err != nil {
+		writeServiceError(w, err, "failed to invite member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Remove kicks a member from the room, or leaves it when the member is the
+// caller. Any live connections the member has to the room are closed.
+func (h *MembersHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := parseMemberPath(w, r)
+	if !ok {
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.svc.RemoveMember(roomID, actor.ID, userID); err != nil {
+		writeServiceError(w, err, "failed to remove member")
+		return
+	}
+
+	h.hub.DisconnectUser(roomID, userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MembersHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	roomID, userID, ok := parseMemberPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req setRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.svc.SetMemberRole(roomID, actor.ID, userID, req.Role); err != nil {
+		writeServiceError(w, err, "failed to change role")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MembersHandler) resolveUser(w http.ResponseWriter, req inviteRequest) (*repository.User, bool) {
+	var (
+		user *repository.User
+		err  error
+	)
+
+	switch {
+	case req.UserID != "":
+		id, parseErr := uuid.Parse(req.UserID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_USER_ID", "invalid user id")
+			return nil, false
+		}
+		user, err = h.users.GetByID(id)
+	case req.Name != "":
+		user, err = h.users.GetByName(req.Name)
+	default:
+		writeError(w, http.StatusBadRequest, "USER_REQUIRED", "user_id or name is required")
+		return nil, false
+	}
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeError(w, http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to look up user")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// parseMemberPath reads the room and user IDs from /rooms/{roomID}/members/{userID}.
+// The literal "me" stands for the caller.
+func parseMemberPath(w http.ResponseWriter, r *http.Request) (roomID, userID uuid.UUID, ok bool) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	rawUserID := chi.URLParam(r, "userID")
+	if rawUserID == "me" {
+		return roomID, middleware.UserFromContext(r.Context()).ID, true
+	}
+
+	userID, err = uuid.Parse(rawUserID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_USER_ID", "invalid user id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return roomID, userID, true
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	middlewaremocks "github.com/EwanGreer/chatatui/internal/middleware/_mocks"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testAPIKey = "test-key"
+
+// authenticatedAs wraps next in the API key middleware, authenticating every
+// request as user.
+func authenticatedAs(t *testing.T, user *repository.User, next http.Handler) http.Handler {
+	users := middlewaremocks.NewMockUserLookup(t)
+	users.EXPECT().GetByAPIKey(testAPIKey).Return(user, nil).Maybe()
+	return middleware.APIKeyAuth(users)(next)
+}
+
+func newMembersRouter(t *testing.T, actor *repository.User, svc ChatService, dir UserDirectory) http.Handler {
+	return newMembersRouterWithHub(t, hub.NewHub(hub.NewMemoryBroker()), actor, svc, dir)
+}
+
+func newMembersRouterWithHub(t *testing.T, h *hub.Hub, actor *repository.User, svc ChatService, dir UserDirectory) http.Handler {
+	mh := NewMembersHandler(h, svc, dir)
+	r := chi.NewRouter()
+	r.Get("/rooms/{roomID}/members", mh.List)
+	r.Get("/rooms/{roomID}/keys", mh.Keys)
+	r.Post("/rooms/{roomID}/members", mh.Invite)
+	r.Put("/rooms/{roomID}/members/{userID}", mh.SetRole)
+	r.Delete("/rooms/{roomID}/members/{userID}", mh.Remove)
+	return authenticatedAs(t, actor, r)
+}
+
+func authedRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAPIKey)
+	return req
+}
+
+func TestMembersHandler_InviteByName(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	invitee := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "bob"}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().InviteMember(roomID, actor.ID, invitee.ID).Return(nil)
+	dir := mocks.NewMockUserDirectory(t)
+	dir.EXPECT().GetByName("bob").Return(invitee, nil)
+
+	router := newMembersRouter(t, actor, svc, dir)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPost, "/rooms/"+roomID.String()+"/members", `{"name":"bob"}`))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestMembersHandler_InviteUnknownUser(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	dir := mocks.NewMockUserDirectory(t)
+	dir.EXPECT().GetByName("nobody").Return(nil, gorm.ErrRecordNotFound)
+
+	router := newMembersRouter(t, actor, svc, dir)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPost, "/rooms/"+roomID.String()+"/members", `{"name":"nobody"}`))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "USER_NOT_FOUND", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMembersHandler_RemoveForbidden(t *testing.T) {
+	roomID := uuid.New()
+	targetID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().RemoveMember(roomID, actor.ID, targetID).Return(service.ErrForbidden)
+
+	router := newMembersRouter(t, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodDelete, "/rooms/"+roomID.String()+"/members/"+targetID.String(), ""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "FORBIDDEN", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMembersHandler_LeaveAsMe(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().RemoveMember(roomID, actor.ID, actor.ID).Return(nil)
+
+	router := newMembersRouter(t, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodDelete, "/rooms/"+roomID.String()+"/members/me", ""))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestMembersHandler_SetRoleInvalid(t *testing.T) {
+	roomID := uuid.New()
+	targetID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().SetMemberRole(roomID, actor.ID, targetID, "owner").Return(service.ErrInvalidRole)
+
+	router := newMembersRouter(t, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPut, "/rooms/"+roomID.String()+"/members/"+targetID.String(), `{"role":"owner"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "INVALID_ROLE", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMembersHandler_ListWithPresence(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+	offlineID := uuid.New()
+
+	h := hub.NewHub(hub.NewMemoryBroker())
+	room, err := h.CreateRoom(roomID)
+	require.NoError(t, err)
+	room.Add(hub.NewClient(nil, actor.ID, roomID, actor.Name))
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().ListMembers(roomID, actor.ID).Return([]service.MemberInfo{
+		{UserID: actor.ID, Name: "alice", Role: repository.RoleOwner},
+		{UserID: offlineID, Name: "bob", Role: repository.RoleMember},
+	}, nil)
+
+	router := newMembersRouterWithHub(t, h, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/members", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []memberResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []memberResponse{
+		{UserID: actor.ID.String(), Name: "alice", Role: repository.RoleOwner, Status: hub.StatusOnline},
+		{UserID: offlineID.String(), Name: "bob", Role: repository.RoleMember, Status: hub.StatusOffline},
+	}, resp)
+}
+
+func TestMembersHandler_ListNotAMember(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().ListMembers(roomID, actor.ID).Return(nil, service.ErrNotRoomMember)
+
+	router := newMembersRouter(t, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/members", ""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "NOT_A_MEMBER", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMembersHandler_Keys(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().RoomKeys(roomID, actor.ID).Return([]service.MemberKey{
+		{UserID: actor.ID, Name: "alice", PublicKey: "YWxpY2U="},
+	}, nil)
+
+	router := newMembersRouter(t, actor, svc, mocks.NewMockUserDirectory(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/keys", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []memberKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []memberKeyResponse{{UserID: actor.ID.String(), Name: "alice", PublicKey: "YWxpY2U="}}, resp)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/google/uuid"
+)
+
+type MentionsHandler struct {
+	svc       ChatService
+	pageLimit int
+}
+
+func NewMentionsHandler(svc ChatService, pageLimit int) *MentionsHandler {
+	return &MentionsHandler{svc: svc, pageLimit: pageLimit}
+}
+
+type mentionsResponse struct {
+	Mentions []hub.WireMessage `json:"mentions"`
+}
+
+// List returns the messages that mention the caller, newest first, each with
+// its room_id. Older mentions are paged through by passing the ID of the last
+// one seen as ?before=, and ?limit= caps the page at fewer than the default.
+func (h *MentionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	before := uuid.Nil
+	if raw := params.Get("before"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_CURSOR", "before must be a message id")
+			return
+		}
+		before = id
+	}
+
+	limit := h.pageLimit
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = min(n, h.pageLimit)
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	mentions, err := h.svc.Mentions(user.ID, before, limit)
+	if err != nil {
+		writeServiceError(w, err, "failed to list mentions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(mentionsResponse{Mentions: wireMessages(mentions)})
+}
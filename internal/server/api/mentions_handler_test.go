@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMentionsRouter(t *testing.T, actor *repository.User, svc ChatService) http.Handler {
+	h := NewMentionsHandler(svc, 50)
+	r := chi.NewRouter()
+	r.Get("/mentions", h.List)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestMentionsHandler_List(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+	before := uuid.New()
+	mention := service.MessageInfo{ID: uuid.New(), RoomID: roomID, Author: "bob", Content: "@alice ship it", CreatedAt: time.Now()}
+
+	tests := []struct {
+		name       string
+		target     string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:   "newest page",
+			target: "/mentions",
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().Mentions(actor.ID, uuid.Nil, 50).Return([]service.MessageInfo{mention}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "older page",
+			target: "/mentions?before=" + before.String() + "&limit=500",
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().Mentions(actor.ID, before, 50).Return([]service.MessageInfo{mention}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects malformed cursor",
+			target:     "/mentions?before=latest",
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_CURSOR",
+		},
+		{
+			name:       "rejects malformed limit",
+			target:     "/mentions?limit=0",
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_LIMIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newMentionsRouter(t, actor, svc).ServeHTTP(w, authedRequest(http.MethodGet, tt.target, ""))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+				return
+			}
+
+			var resp mentionsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Mentions, 1)
+			assert.Equal(t, mention.ID.String(), resp.Mentions[0].ID)
+			assert.Equal(t, roomID.String(), resp.Mentions[0].RoomID)
+			assert.Equal(t, hub.MessageTypeChat, resp.Mentions[0].Type)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MessagesHandler struct {
+	svc       ChatService
+	pageLimit int
+}
+
+func NewMessagesHandler(svc ChatService, pageLimit int) *MessagesHandler {
+	return &MessagesHandler{svc: svc, pageLimit: pageLimit}
+}
+
+type messagePageResponse struct {
+	Messages   []hub.WireMessage `json:"messages"`
+	HasMore    bool              `json:"has_more"`
+	NextBefore string            `json:"next_before,omitempty"`
+}
+
+// List returns a page of a room's history, oldest first. Pass the
+// next_before value of a response as ?before= to fetch the page preceding it.
+func (h *MessagesHandler) List(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	before := uuid.Nil
+	if raw := r.URL.Query().Get("before"); raw != "" {
+		before, err = uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_CURSOR", "before must be a message id")
+			return
+		}
+	}
+
+	limit := h.pageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = min(n, h.pageLimit)
+	}
+
+	if _, err := h.svc.GetRoom(roomID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeError(w, http.StatusNotFound, "ROOM_NOT_FOUND", "room not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get room")
+		return
+	}
+
+	viewer := middleware.UserFromContext(r.Context())
+	page, err := h.svc.GetMessagesBefore(roomID, viewer.ID, before, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get messages")
+		return
+	}
+
+	resp := messagePageResponse{
+		Messages: wireMessages(page.Messages),
+		HasMore:  page.HasMore,
+	}
+	if page.HasMore && len(page.Messages) > 0 {
+		resp.NextBefore = page.Messages[0].ID.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
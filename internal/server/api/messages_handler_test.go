@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var messagesViewer = &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "viewer"}
+
+func newMessagesRouter(t *testing.T, svc ChatService) http.Handler {
+	h := NewMessagesHandler(svc, 50)
+	r := chi.NewRouter()
+	r.Get("/rooms/{roomID}/messages", h.List)
+	return authenticatedAs(t, messagesViewer, r)
+}
+
+func TestMessagesHandler_InvalidCursor(t *testing.T) {
+	svc := mocks.NewMockChatService(t)
+	router := newMessagesRouter(t, svc)
+
+	req := authedRequest(http.MethodGet, "/rooms/"+uuid.NewString()+"/messages?before=nope", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "INVALID_CURSOR", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMessagesHandler_RoomNotFound(t *testing.T) {
+	roomID := uuid.New()
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(nil, gorm.ErrRecordNotFound)
+
+	router := newMessagesRouter(t, svc)
+
+	req := authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/messages", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "ROOM_NOT_FOUND", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestMessagesHandler_ReturnsPageWithCursor(t *testing.T) {
+	roomID := uuid.New()
+	before := uuid.New()
+	oldest := uuid.New()
+	newest := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID}, nil)
+	svc.EXPECT().GetMessagesBefore(roomID, messagesViewer.ID, before, 10).Return(service.MessagePage{
+		Messages: []service.MessageInfo{
+			{ID: oldest, Author: "alice", Content: "first", CreatedAt: now},
+			{ID: newest, Author: "bob", Content: "second", CreatedAt: now},
+		},
+		HasMore: true,
+	}, nil)
+
+	router := newMessagesRouter(t, svc)
+
+	req := authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/messages?before="+before.String()+"&limit=10", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp messagePageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Messages, 2)
+	assert.Equal(t, "first", resp.Messages[0].Content)
+	assert.True(t, resp.HasMore)
+	assert.Equal(t, oldest.String(), resp.NextBefore)
+}
+
+func TestMessagesHandler_LimitCappedAtPageSize(t *testing.T) {
+	roomID := uuid.New()
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID}, nil)
+	svc.EXPECT().GetMessagesBefore(roomID, messagesViewer.ID, uuid.Nil, 50).Return(service.MessagePage{}, nil)
+
+	router := newMessagesRouter(t, svc)
+
+	req := authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/messages?limit=1000", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
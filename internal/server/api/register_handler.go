@@ -4,13 +4,21 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/EwanGreer/chatatui/internal/limits"
 	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type UserStore interface {
 	Create(user *repository.User) error
+	GetByName(name string) (*repository.User, error)
+	UpdateName(id uuid.UUID, name string) error
+	SetPublicKey(id uuid.UUID, publicKey string) error
 }
 
 type RegisterHandler struct {
@@ -41,6 +49,21 @@ func (h *RegisterHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(req.Name) > limits.MaxUserNameLength {
+		writeError(w, http.StatusBadRequest, "NAME_TOO_LONG", fmt.Sprintf("name must be %d characters or fewer", limits.MaxUserNameLength))
+		return
+	}
+
+	// Names identify users for invites and direct messages, so they must be
+	// unique.
+	if _, err := h.users.GetByName(req.Name); err == nil {
+		writeError(w, http.StatusConflict, "NAME_TAKEN", "name is already taken")
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to check name")
+		return
+	}
+
 	apiKey, err := generateAPIKey()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to generate api key")
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestRegisterHandler_CreatesUser(t *testing.T) {
+	users := mocks.NewMockUserStore(t)
+	users.EXPECT().GetByName("alice").Return(nil, gorm.ErrRecordNotFound)
+	users.EXPECT().Create(mock.AnythingOfType("*repository.User")).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"alice"}`))
+	w := httptest.NewRecorder()
+	NewRegisterHandler(users).Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "api_key")
+}
+
+func TestRegisterHandler_NameTaken(t *testing.T) {
+	users := mocks.NewMockUserStore(t)
+	users.EXPECT().GetByName("alice").Return(&repository.User{Name: "alice"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"alice"}`))
+	w := httptest.NewRecorder()
+	NewRegisterHandler(users).Handle(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "NAME_TAKEN", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestRegisterHandler_NameTooLong(t *testing.T) {
+	users := mocks.NewMockUserStore(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"`+strings.Repeat("a", 40)+`"}`))
+	w := httptest.NewRecorder()
+	NewRegisterHandler(users).Handle(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "NAME_TOO_LONG", parseErrorResponse(t, w.Body.Bytes()).Code)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type RetentionHandler struct {
+	svc    ChatService
+	server repository.Retention
+}
+
+// NewRetentionHandler returns a handler for setting room retention policies.
+// server is the server-wide policy, which rooms may tighten but not relax.
+func NewRetentionHandler(svc ChatService, server repository.Retention) *RetentionHandler {
+	return &RetentionHandler{svc: svc, server: server}
+}
+
+type setRetentionRequest struct {
+	MaxAgeDays  int `json:"max_age_days"`
+	MaxMessages int `json:"max_messages"`
+}
+
+// Set changes how long the room keeps its messages and responds with the
+// policy now in effect. Zero fields are unlimited, so an empty body keeps
+// messages for as long as the server does.
+func (h *RetentionHandler) Set(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	var req setRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	retention := repository.Retention{MaxAgeDays: req.MaxAgeDays, MaxMessages: req.MaxMessages}
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.svc.SetRetention(roomID, actor.ID, retention); err != nil {
+		if errors.Is(err, service.ErrInvalidRetention) {
+			writeError(w, http.StatusBadRequest, "INVALID_RETENTION", "max_age_days and max_messages cannot be negative")
+			return
+		}
+		writeServiceError(w, err, "failed to set retention")
+		return
+	}
+
+	resp := newRetentionResponse(retention, h.server)
+	if resp == nil {
+		resp = &retentionResponse{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
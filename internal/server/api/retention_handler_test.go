@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetentionRouter(t *testing.T, actor *repository.User, svc ChatService, server repository.Retention) http.Handler {
+	r := chi.NewRouter()
+	r.Put("/rooms/{roomID}/retention", NewRetentionHandler(svc, server).Set)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestRetentionHandler_Set(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	server := repository.Retention{MaxAgeDays: 90}
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+		want       retentionResponse
+	}{
+		{
+			name: "responds with the stricter of room and server",
+			body: `{"max_age_days":365,"max_messages":1000}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetRetention(roomID, actor.ID, repository.Retention{MaxAgeDays: 365, MaxMessages: 1000}).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			want:       retentionResponse{MaxAgeDays: 90, MaxMessages: 1000},
+		},
+		{
+			name: "clearing falls back to the server policy",
+			body: `{}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetRetention(roomID, actor.ID, repository.Retention{}).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			want:       retentionResponse{MaxAgeDays: 90},
+		},
+		{
+			name: "rejects negative values",
+			body: `{"max_age_days":-1}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetRetention(roomID, actor.ID, repository.Retention{MaxAgeDays: -1}).Return(service.ErrInvalidRetention)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_RETENTION",
+		},
+		{
+			name: "only the owner may set it",
+			body: `{"max_messages":10}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetRetention(roomID, actor.ID, repository.Retention{MaxMessages: 10}).Return(service.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "FORBIDDEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newRetentionRouter(t, actor, svc, server).
+				ServeHTTP(w, authedRequest(http.MethodPut, "/rooms/"+roomID.String()+"/retention", tt.body))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+				return
+			}
+			var got retentionResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
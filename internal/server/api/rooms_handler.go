@@ -4,32 +4,63 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
 	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
 )
 
 type RoomStore interface {
-	Create(room *repository.Room) error
-	List(limit, offset int) ([]repository.Room, error)
+	CreateWithOwner(room *repository.Room, ownerID uuid.UUID) error
+	ListVisible(userID uuid.UUID, limit, offset int) ([]repository.Room, error)
 }
 
 type RoomsHandler struct {
 	rooms     RoomStore
 	listLimit int
+	retention repository.Retention
 }
 
-func NewRoomsHandler(rooms RoomStore, listLimit int) *RoomsHandler {
-	return &RoomsHandler{rooms: rooms, listLimit: listLimit}
+// NewRoomsHandler returns a handler for listing and creating rooms. retention
+// is the server-wide policy, reported alongside each room's own.
+func NewRoomsHandler(rooms RoomStore, listLimit int, retention repository.Retention) *RoomsHandler {
+	return &RoomsHandler{rooms: rooms, listLimit: listLimit, retention: retention}
 }
 
 type roomResponse struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Topic      string `json:"topic"`
+	Visibility string `json:"visibility"`
+	Kind       string `json:"kind"`
+	Encrypted  bool   `json:"encrypted"`
+	// Retention is how long the room keeps its messages, omitted if forever.
+	Retention *retentionResponse `json:"retention,omitempty"`
+}
+
+// retentionResponse is the policy in effect for a room: the stricter of its
+// own and the server's. A zero field is unlimited.
+type retentionResponse struct {
+	MaxAgeDays  int `json:"max_age_days"`
+	MaxMessages int `json:"max_messages"`
+}
+
+func newRetentionResponse(room, server repository.Retention) *retentionResponse {
+	effective := room.Stricter(server)
+	if effective.IsZero() {
+		return nil
+	}
+	return &retentionResponse{MaxAgeDays: effective.MaxAgeDays, MaxMessages: effective.MaxMessages}
 }
 
 type createRoomRequest struct {
-	Name string `json:"name"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+	// Encrypted rooms only accept end-to-end encrypted messages.
+	Encrypted bool `json:"encrypted"`
 }
 
 func (h *RoomsHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -49,18 +80,35 @@ func (h *RoomsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch req.Visibility {
+	case "":
+		req.Visibility = repository.RoomVisibilityPublic
+	case repository.RoomVisibilityPublic, repository.RoomVisibilityInviteOnly, repository.RoomVisibilityPrivate:
+	default:
+		writeError(w, http.StatusBadRequest, "INVALID_VISIBILITY", "visibility must be public, invite_only or private")
+		return
+	}
+
 	room := &repository.Room{
-		Name: req.Name,
+		Name:       req.Name,
+		Visibility: req.Visibility,
+		Kind:       repository.RoomKindRoom,
+		Encrypted:  req.Encrypted,
 	}
 
-	if err := h.rooms.Create(room); err != nil {
+	user := middleware.UserFromContext(r.Context())
+	if err := h.rooms.CreateWithOwner(room, user.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create room")
 		return
 	}
 
 	resp := roomResponse{
-		ID:   room.ID.String(),
-		Name: room.Name,
+		ID:         room.ID.String(),
+		Name:       room.Name,
+		Visibility: room.Visibility,
+		Kind:       room.Kind,
+		Encrypted:  room.Encrypted,
+		Retention:  newRetentionResponse(room.Retention, h.retention),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -69,7 +117,8 @@ func (h *RoomsHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *RoomsHandler) List(w http.ResponseWriter, r *http.Request) {
-	rooms, err := h.rooms.List(h.listLimit, 0)
+	user := middleware.UserFromContext(r.Context())
+	rooms, err := h.rooms.ListVisible(user.ID, h.listLimit, 0)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list rooms")
 		return
@@ -78,15 +127,44 @@ func (h *RoomsHandler) List(w http.ResponseWriter, r *http.Request) {
 	resp := make([]roomResponse, len(rooms))
 	for i, room := range rooms {
 		name := room.Name
+		if room.Kind == repository.RoomKindDM {
+			name = dmName(room.Members, user.ID)
+		}
 		if name == "" {
 			name = room.ID.String()[:8]
 		}
+		visibility := room.Visibility
+		if visibility == "" {
+			visibility = repository.RoomVisibilityPublic
+		}
+		kind := room.Kind
+		if kind == "" {
+			kind = repository.RoomKindRoom
+		}
 		resp[i] = roomResponse{
-			ID:   room.ID.String(),
-			Name: name,
+			ID:         room.ID.String(),
+			Name:       name,
+			Topic:      room.Topic,
+			Visibility: visibility,
+			Kind:       kind,
+			Encrypted:  room.Encrypted,
+			Retention:  newRetentionResponse(room.Retention, h.retention),
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+// dmName names a direct message room after the participants other than the
+// viewer.
+func dmName(members []repository.User, viewerID uuid.UUID) string {
+	var names []string
+	for _, m := range members {
+		if m.ID != viewerID {
+			names = append(names, m.Name)
+		}
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
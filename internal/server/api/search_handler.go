@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/google/uuid"
+)
+
+type SearchHandler struct {
+	svc       ChatService
+	pageLimit int
+}
+
+func NewSearchHandler(svc ChatService, pageLimit int) *SearchHandler {
+	return &SearchHandler{svc: svc, pageLimit: pageLimit}
+}
+
+type searchResponse struct {
+	Messages []hub.WireMessage `json:"messages"`
+}
+
+// Search finds messages matching ?q= in the caller's rooms, newest first. It
+// can be narrowed with ?room=, ?author= and a ?before= / ?after= time given as
+// RFC 3339 or a YYYY-MM-DD date. Each hit carries its room_id.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	query := service.SearchQuery{
+		Text:   strings.TrimSpace(params.Get("q")),
+		Author: params.Get("author"),
+		Limit:  h.pageLimit,
+	}
+	if query.Text == "" {
+		writeError(w, http.StatusBadRequest, "QUERY_REQUIRED", "q is required")
+		return
+	}
+
+	if raw := params.Get("room"); raw != "" {
+		roomID, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+			return
+		}
+		query.RoomID = roomID
+	}
+
+	var err error
+	if query.Before, err = parseSearchTime(params.Get("before")); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TIME", "before must be an RFC 3339 time or YYYY-MM-DD date")
+		return
+	}
+	if query.After, err = parseSearchTime(params.Get("after")); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TIME", "after must be an RFC 3339 time or YYYY-MM-DD date")
+		return
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		query.Limit = min(n, h.pageLimit)
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	hits, err := h.svc.SearchMessages(user.ID, query)
+	if err != nil {
+		writeServiceError(w, err, "failed to search messages")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(searchResponse{Messages: wireMessages(hits)})
+}
+
+func parseSearchTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
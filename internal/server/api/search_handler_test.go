@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchRouter(t *testing.T, actor *repository.User, svc ChatService) http.Handler {
+	h := NewSearchHandler(svc, 50)
+	r := chi.NewRouter()
+	r.Get("/search", h.Search)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+	hit := service.MessageInfo{ID: uuid.New(), RoomID: roomID, Author: "bob", Content: "release tonight", CreatedAt: time.Now()}
+
+	tests := []struct {
+		name       string
+		target     string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:   "passes filters through",
+			target: "/search?q=release&room=" + roomID.String() + "&author=bob&after=2026-01-02&limit=10",
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SearchMessages(actor.ID, service.SearchQuery{
+					Text:   "release",
+					RoomID: roomID,
+					Author: "bob",
+					After:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+					Limit:  10,
+				}).Return([]service.MessageInfo{hit}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "requires a query",
+			target:     "/search?q=%20",
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "QUERY_REQUIRED",
+		},
+		{
+			name:       "rejects malformed time",
+			target:     "/search?q=release&before=yesterday",
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_TIME",
+		},
+		{
+			name:   "room the caller is not in",
+			target: "/search?q=release&room=" + roomID.String(),
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SearchMessages(actor.ID, service.SearchQuery{Text: "release", RoomID: roomID, Limit: 50}).
+					Return(nil, service.ErrNotRoomMember)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "NOT_A_MEMBER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newSearchRouter(t, actor, svc).ServeHTTP(w, authedRequest(http.MethodGet, tt.target, ""))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+				return
+			}
+
+			var resp searchResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Messages, 1)
+			assert.Equal(t, hit.ID.String(), resp.Messages[0].ID)
+			assert.Equal(t, roomID.String(), resp.Messages[0].RoomID)
+		})
+	}
+}
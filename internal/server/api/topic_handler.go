@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type TopicHandler struct {
+	hub *hub.Hub
+	svc ChatService
+}
+
+func NewTopicHandler(h *hub.Hub, svc ChatService) *TopicHandler {
+	return &TopicHandler{hub: h, svc: svc}
+}
+
+type setTopicRequest struct {
+	Topic string `json:"topic"`
+}
+
+// Set changes the room's topic and announces it to everyone connected to the
+// room. An empty topic clears it.
+func (h *TopicHandler) Set(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	var req setTopicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	topic := strings.TrimSpace(req.Topic)
+	if len(topic) > limits.MaxTopicLength {
+		writeError(w, http.StatusBadRequest, "TOPIC_TOO_LONG", fmt.Sprintf("topic must be %d characters or fewer", limits.MaxTopicLength))
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.svc.SetTopic(roomID, actor.ID, topic); err != nil {
+		writeServiceError(w, err, "failed to set topic")
+		return
+	}
+
+	wire := &hub.WireMessage{
+		Type:      hub.MessageTypeTopic,
+		RoomID:    roomID.String(),
+		Author:    actor.Name,
+		Content:   topic,
+		Timestamp: time.Now(),
+	}
+	if wireBytes, err := wire.Marshal(); err != nil {
+		slog.Error("failed to marshal topic change", "error", err, "room_id", roomID)
+	} else {
+		h.hub.Publish(roomID, wireBytes)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTopicRouter(t *testing.T, h *hub.Hub, actor *repository.User, svc ChatService) http.Handler {
+	r := chi.NewRouter()
+	r.Put("/rooms/{roomID}/topic", NewTopicHandler(h, svc).Set)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestTopicHandler_Set(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "sets trimmed topic",
+			body: `{"topic":"  release day "}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetTopic(roomID, actor.ID, "release day").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "rejects long topic",
+			body:       `{"topic":"` + strings.Repeat("x", 200) + `"}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "TOPIC_TOO_LONG",
+		},
+		{
+			name: "member without permission",
+			body: `{"topic":"hi"}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().SetTopic(roomID, actor.ID, "hi").Return(service.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "FORBIDDEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newTopicRouter(t, hub.NewHub(hub.NewMemoryBroker()), actor, svc).
+				ServeHTTP(w, authedRequest(http.MethodPut, "/rooms/"+roomID.String()+"/topic", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type UnreadHandler struct {
+	svc ChatService
+}
+
+func NewUnreadHandler(svc ChatService) *UnreadHandler {
+	return &UnreadHandler{svc: svc}
+}
+
+type unreadResponse struct {
+	RoomID string `json:"room_id"`
+	Unread int    `json:"unread"`
+}
+
+type markReadRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// List returns the unread message count for each of the caller's rooms.
+func (h *UnreadHandler) List(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	counts, err := h.svc.UnreadCounts(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to count unread messages")
+		return
+	}
+
+	resp := make([]unreadResponse, 0, len(counts))
+	for roomID, n := range counts {
+		resp = append(resp, unreadResponse{RoomID: roomID.String(), Unread: n})
+	}
+	slices.SortFunc(resp, func(a, b unreadResponse) int {
+		return strings.Compare(a.RoomID, b.RoomID)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// MarkRead moves the caller's read marker for the room to message_id. It is
+// the HTTP equivalent of sending a read event over the WebSocket.
+func (h *UnreadHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	var req markReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	messageID, err := uuid.Parse(req.MessageID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MESSAGE_ID", "invalid message id")
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	if err := h.svc.MarkRead(roomID, user.ID, messageID); err != nil {
+		writeServiceError(w, err, "failed to mark read")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newUnreadRouter(t *testing.T, actor *repository.User, svc ChatService) http.Handler {
+	h := NewUnreadHandler(svc)
+	r := chi.NewRouter()
+	r.Get("/unread", h.List)
+	r.Put("/rooms/{roomID}/read", h.MarkRead)
+	return authenticatedAs(t, actor, r)
+}
+
+func TestUnreadHandler_List(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().UnreadCounts(actor.ID).Return(map[uuid.UUID]int{roomID: 4}, nil)
+
+	w := httptest.NewRecorder()
+	newUnreadRouter(t, actor, svc).ServeHTTP(w, authedRequest(http.MethodGet, "/unread", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []unreadResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []unreadResponse{{RoomID: roomID.String(), Unread: 4}}, resp)
+}
+
+func TestUnreadHandler_MarkRead(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+	roomID := uuid.New()
+	msgID := uuid.New()
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+	}{
+		{
+			name: "moves marker",
+			body: `{"message_id":"` + msgID.String() + `"}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().MarkRead(roomID, actor.ID, msgID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "rejects invalid message id",
+			body:       `{"message_id":"nope"}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "message from another room",
+			body: `{"message_id":"` + msgID.String() + `"}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().MarkRead(roomID, actor.ID, msgID).Return(gorm.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+
+			w := httptest.NewRecorder()
+			newUnreadRouter(t, actor, svc).ServeHTTP(w, authedRequest(http.MethodPut, "/rooms/"+roomID.String()+"/read", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
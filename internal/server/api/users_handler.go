@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"gorm.io/gorm"
+)
+
+type UsersHandler struct {
+	users UserStore
+}
+
+func NewUsersHandler(users UserStore) *UsersHandler {
+	return &UsersHandler{users: users}
+}
+
+type updateUserRequest struct {
+	Name string `json:"name"`
+}
+
+type userResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type meResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Scopes are only set for bots.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Me returns who the caller's API key belongs to, and for a bot what it may
+// do.
+func (h *UsersHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(meResponse{
+		ID:     user.ID.String(),
+		Name:   user.Name,
+		Kind:   user.Kind,
+		Scopes: strings.Fields(user.Scopes),
+	})
+}
+
+// UpdateMe renames the caller. Open WebSocket connections keep the old name
+// until they reconnect.
+func (h *UsersHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var req updateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "NAME_REQUIRED", "name is required")
+		return
+	}
+	if len(name) > limits.MaxUserNameLength {
+		writeError(w, http.StatusBadRequest, "NAME_TOO_LONG", fmt.Sprintf("name must be %d characters or fewer", limits.MaxUserNameLength))
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	if existing, err := h.users.GetByName(name); err == nil {
+		if existing.ID != user.ID {
+			writeError(w, http.StatusConflict, "NAME_TAKEN", "name is already taken")
+			return
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to check name")
+		return
+	}
+
+	if err := h.users.UpdateName(user.ID, name); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to update user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(userResponse{ID: user.ID.String(), Name: name})
+}
+
+type setKeyRequest struct {
+	PublicKey string `json:"public_key"`
+}
+
+// SetKey publishes the caller's public key for end-to-end encrypted rooms,
+// replacing any earlier one. Messages encrypted to the old key can no longer
+// be read with the new one.
+func (h *UsersHandler) SetKey(w http.ResponseWriter, r *http.Request) {
+	var req setKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+
+	if _, err := e2e.ParsePublicKey(req.PublicKey); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_KEY", "public_key must be a base64 X25519 public key")
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	if err := h.users.SetPublicKey(user.ID, req.PublicKey); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to save key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
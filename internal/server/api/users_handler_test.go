@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUsersHandler_UpdateMe(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockUserStore)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "renames caller",
+			body: `{"name":"alicia"}`,
+			setup: func(m *mocks.MockUserStore) {
+				m.EXPECT().GetByName("alicia").Return(nil, gorm.ErrRecordNotFound)
+				m.EXPECT().UpdateName(actor.ID, "alicia").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "keeping own name is allowed",
+			body: `{"name":"alice"}`,
+			setup: func(m *mocks.MockUserStore) {
+				m.EXPECT().GetByName("alice").Return(actor, nil)
+				m.EXPECT().UpdateName(actor.ID, "alice").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "name taken by someone else",
+			body: `{"name":"bob"}`,
+			setup: func(m *mocks.MockUserStore) {
+				m.EXPECT().GetByName("bob").Return(&repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}, nil)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "NAME_TAKEN",
+		},
+		{
+			name:       "name required",
+			body:       `{"name":"  "}`,
+			setup:      func(*mocks.MockUserStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "NAME_REQUIRED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := mocks.NewMockUserStore(t)
+			tt.setup(users)
+
+			r := chi.NewRouter()
+			r.Put("/users/me", NewUsersHandler(users).UpdateMe)
+
+			w := httptest.NewRecorder()
+			authenticatedAs(t, actor, r).ServeHTTP(w, authedRequest(http.MethodPut, "/users/me", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+			}
+		})
+	}
+}
+
+func TestUsersHandler_SetKey(t *testing.T) {
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}, Name: "alice"}
+	key, err := e2e.GenerateKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockUserStore)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "publishes key",
+			body: `{"public_key":"` + key.PublicKey() + `"}`,
+			setup: func(m *mocks.MockUserStore) {
+				m.EXPECT().SetPublicKey(actor.ID, key.PublicKey()).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "rejects keys of the wrong size",
+			body:       `{"public_key":"c2hvcnQ="}`,
+			setup:      func(*mocks.MockUserStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_KEY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := mocks.NewMockUserStore(t)
+			tt.setup(users)
+
+			r := chi.NewRouter()
+			r.Put("/users/me/key", NewUsersHandler(users).SetKey)
+
+			w := httptest.NewRecorder()
+			authenticatedAs(t, actor, r).ServeHTTP(w, authedRequest(http.MethodPut, "/users/me/key", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+			}
+		})
+	}
+}
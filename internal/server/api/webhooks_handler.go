@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/EwanGreer/chatatui/internal/limits"
+	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WebhookService interface {
+	CreateWebhook(roomID, actorID uuid.UUID, kind, name, target string) (*service.WebhookInfo, string, error)
+	ListWebhooks(roomID, actorID uuid.UUID) ([]service.WebhookInfo, error)
+	DeleteWebhook(roomID, actorID, webhookID uuid.UUID) error
+	Deliveries(roomID, actorID, webhookID uuid.UUID, limit int) ([]service.DeliveryInfo, error)
+	IncomingWebhook(token string) (*service.WebhookInfo, error)
+}
+
+type WebhooksHandler struct {
+	hub       *hub.Hub
+	svc       ChatService
+	hooks     WebhookService
+	pageLimit int
+}
+
+func NewWebhooksHandler(h *hub.Hub, svc ChatService, hooks WebhookService, pageLimit int) *WebhooksHandler {
+	return &WebhooksHandler{hub: h, svc: svc, hooks: hooks, pageLimit: pageLimit}
+}
+
+type createWebhookRequest struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type webhookResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Token is only returned when an incoming webhook is created; messages
+	// are posted to /hooks/{token}. Secret is only returned when an outgoing
+	// webhook is created, and signs its deliveries.
+	Token  string `json:"token,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type deliveryResponse struct {
+	ID         string    `json:"id"`
+	MessageID  string    `json:"message_id"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Succeeded  bool      `json:"succeeded"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type incomingWebhookRequest struct {
+	Content string `json:"content"`
+	// Type is "chat", the default, for a message kept in the room's history,
+	// or "system" for a notice shown to whoever is connected.
+	Type string `json:"type"`
+}
+
+// Create adds an incoming or outgoing webhook to the room. The response
+// carries its token or secret, which is not shown again.
+func (h *WebhooksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > limits.MaxUserNameLength {
+		writeError(w, http.StatusBadRequest, "INVALID_NAME", fmt.Sprintf("name must be 1 to %d characters", limits.MaxUserNameLength))
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	hook, secret, err := h.hooks.CreateWebhook(roomID, actor.ID, req.Kind, name, req.URL)
+	if err != nil {
+		writeServiceError(w, err, "failed to create webhook")
+		return
+	}
+
+	resp := toWebhookResponse(*hook)
+	if hook.Kind == repository.WebhookKindIncoming {
+		resp.Token = secret
+	} else {
+		resp.Secret = secret
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// List returns the room's webhooks, for its owners and moderators.
+func (h *WebhooksHandler) List(w http.ResponseWriter, r *http.Request) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	hooks, err := h.hooks.ListWebhooks(roomID, actor.ID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list webhooks")
+		return
+	}
+
+	resp := make([]webhookResponse, len(hooks))
+	for i, hook := range hooks {
+		resp[i] = toWebhookResponse(hook)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *WebhooksHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	roomID, webhookID, ok := parseWebhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	if err := h.hooks.DeleteWebhook(roomID, actor.ID, webhookID); err != nil {
+		writeServiceError(w, err, "failed to delete webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries returns the newest attempts to send messages to an outgoing
+// webhook, newest first. ?limit= caps the page at fewer than the default.
+func (h *WebhooksHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	roomID, webhookID, ok := parseWebhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	limit := h.pageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = min(n, h.pageLimit)
+	}
+
+	actor := middleware.UserFromContext(r.Context())
+	deliveries, err := h.hooks.Deliveries(roomID, actor.ID, webhookID, limit)
+	if err != nil {
+		writeServiceError(w, err, "failed to list deliveries")
+		return
+	}
+
+	resp := make([]deliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = deliveryResponse{
+			ID:         d.ID.String(),
+			MessageID:  d.MessageID.String(),
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			DurationMS: d.Duration.Milliseconds(),
+			Succeeded:  d.Succeeded,
+			CreatedAt:  d.CreatedAt,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type webhookContextKey struct{}
+
+// Authenticate resolves the token in an incoming webhook's URL, so that the
+// request can be rate limited as the webhook's user. The token is the only
+// credential: anyone holding the URL can post to the room.
+func (h *WebhooksHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hook, err := h.hooks.IncomingWebhook(chi.URLParam(r, "token"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+			return
+		}
+		ctx := middleware.WithUser(r.Context(), &repository.User{BaseModel: repository.BaseModel{ID: hook.UserID}, Name: hook.Name})
+		ctx = context.WithValue(ctx, webhookContextKey{}, hook)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Incoming posts a message from an incoming webhook to its room. A chat
+// message is stored, sent as the webhook's user, and announced like one sent
+// over a WebSocket; a system message is only shown to those connected.
+func (h *WebhooksHandler) Incoming(w http.ResponseWriter, r *http.Request) {
+	hook, _ := r.Context().Value(webhookContextKey{}).(*service.WebhookInfo)
+	if hook == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+		return
+	}
+
+	var req incomingWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" || utf8.RuneCountInString(content) > limits.MaxMessageLength {
+		writeError(w, http.StatusBadRequest, "INVALID_CONTENT", fmt.Sprintf("content must be 1 to %d characters", limits.MaxMessageLength))
+		return
+	}
+
+	wire := &hub.WireMessage{
+		RoomID:    hook.RoomID.String(),
+		Author:    hook.Name,
+		Content:   content,
+		Timestamp: time.Now(),
+	}
+	switch req.Type {
+	case "", hub.MessageTypeChat.String():
+		id, createdAt, mentioned, err := h.svc.PersistMessage([]byte(content), repository.MessageKindChat, hook.UserID, hook.RoomID)
+		if err != nil {
+			writeServiceError(w, err, "failed to post message")
+			return
+		}
+		wire.Type, wire.ID, wire.Timestamp = hub.MessageTypeChat, id.String(), createdAt
+		notifyMentioned(h.hub, mentioned, *wire)
+	case hub.MessageTypeSystem.String():
+		wire.Type = hub.MessageTypeSystem
+	default:
+		writeError(w, http.StatusBadRequest, "INVALID_TYPE", "type must be chat or system")
+		return
+	}
+
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal webhook message", "error", err, "webhook_id", hook.ID)
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to post message")
+		return
+	}
+	h.hub.Publish(hook.RoomID, wireBytes)
+
+	if wire.ID == "" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"id": wire.ID})
+}
+
+func parseWebhookPath(w http.ResponseWriter, r *http.Request) (roomID, webhookID uuid.UUID, ok bool) {
+	roomID, err := uuid.Parse(chi.URLParam(r, "roomID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROOM_ID", "invalid room id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	webhookID, err = uuid.Parse(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WEBHOOK_ID", "invalid webhook id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return roomID, webhookID, true
+}
+
+func toWebhookResponse(hook service.WebhookInfo) webhookResponse {
+	return webhookResponse{
+		ID:        hook.ID.String(),
+		Kind:      hook.Kind,
+		Name:      hook.Name,
+		URL:       hook.URL,
+		CreatedAt: hook.CreatedAt,
+	}
+}
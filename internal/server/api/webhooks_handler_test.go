@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newWebhooksRouter(t *testing.T, actor *repository.User, svc ChatService, hooks WebhookService) http.Handler {
+	wh := NewWebhooksHandler(hub.NewHub(hub.NewMemoryBroker()), svc, hooks, 50)
+	r := chi.NewRouter()
+	r.With(wh.Authenticate).Post("/hooks/{token}", wh.Incoming)
+	r.Group(func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler { return authenticatedAs(t, actor, next) })
+		r.Post("/rooms/{roomID}/webhooks", wh.Create)
+		r.Get("/rooms/{roomID}/webhooks/{webhookID}/deliveries", wh.Deliveries)
+	})
+	return r
+}
+
+func TestWebhooksHandler_Create(t *testing.T) {
+	roomID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mocks.MockWebhookService)
+		wantStatus int
+		wantCode   string
+		wantToken  string
+		wantSecret string
+	}{
+		{
+			name: "incoming webhook returns its token",
+			body: `{"kind":"incoming","name":" ci "}`,
+			setup: func(m *mocks.MockWebhookService) {
+				m.EXPECT().CreateWebhook(roomID, actor.ID, "incoming", "ci", "").
+					Return(&service.WebhookInfo{ID: uuid.New(), Kind: repository.WebhookKindIncoming, Name: "ci"}, "tok", nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantToken:  "tok",
+		},
+		{
+			name: "outgoing webhook returns its secret",
+			body: `{"kind":"outgoing","name":"archive","url":"https://example.com"}`,
+			setup: func(m *mocks.MockWebhookService) {
+				m.EXPECT().CreateWebhook(roomID, actor.ID, "outgoing", "archive", "https://example.com").
+					Return(&service.WebhookInfo{ID: uuid.New(), Kind: repository.WebhookKindOutgoing, Name: "archive"}, "shh", nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantSecret: "shh",
+		},
+		{
+			name:       "rejects a missing name",
+			body:       `{"kind":"incoming"}`,
+			setup:      func(*mocks.MockWebhookService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_NAME",
+		},
+		{
+			name: "rejects an invalid webhook",
+			body: `{"kind":"outgoing","name":"x","url":"ftp://example.com"}`,
+			setup: func(m *mocks.MockWebhookService) {
+				m.EXPECT().CreateWebhook(roomID, actor.ID, "outgoing", "x", "ftp://example.com").Return(nil, "", service.ErrInvalidWebhook)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK",
+		},
+		{
+			name: "rejects a name that is taken",
+			body: `{"kind":"incoming","name":"alice"}`,
+			setup: func(m *mocks.MockWebhookService) {
+				m.EXPECT().CreateWebhook(roomID, actor.ID, "incoming", "alice", "").Return(nil, "", service.ErrNameTaken)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "NAME_TAKEN",
+		},
+		{
+			name: "member without permission",
+			body: `{"kind":"incoming","name":"ci"}`,
+			setup: func(m *mocks.MockWebhookService) {
+				m.EXPECT().CreateWebhook(roomID, actor.ID, "incoming", "ci", "").Return(nil, "", service.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "FORBIDDEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := mocks.NewMockWebhookService(t)
+			tt.setup(hooks)
+
+			w := httptest.NewRecorder()
+			newWebhooksRouter(t, actor, mocks.NewMockChatService(t), hooks).
+				ServeHTTP(w, authedRequest(http.MethodPost, "/rooms/"+roomID.String()+"/webhooks", tt.body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+				return
+			}
+			var resp webhookResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantToken, resp.Token)
+			assert.Equal(t, tt.wantSecret, resp.Secret)
+		})
+	}
+}
+
+func TestWebhooksHandler_Incoming(t *testing.T) {
+	hook := &service.WebhookInfo{ID: uuid.New(), RoomID: uuid.New(), Kind: repository.WebhookKindIncoming, Name: "ci", UserID: uuid.New()}
+	messageID := uuid.New()
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		setup      func(*mocks.MockChatService)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:  "chat message is persisted as the webhook's user",
+			token: "good",
+			body:  `{"content":"build #42 failed"}`,
+			setup: func(m *mocks.MockChatService) {
+				m.EXPECT().PersistMessage([]byte("build #42 failed"), repository.MessageKindChat, hook.UserID, hook.RoomID).
+					Return(messageID, time.Now(), nil, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "system message is only broadcast",
+			token:      "good",
+			body:       `{"content":"deploy started","type":"system"}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "unknown token",
+			token:      "bad",
+			body:       `{"content":"hi"}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "NOT_FOUND",
+		},
+		{
+			name:       "rejects empty content",
+			token:      "good",
+			body:       `{"content":"  "}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_CONTENT",
+		},
+		{
+			name:       "rejects other types",
+			token:      "good",
+			body:       `{"content":"hi","type":"topic"}`,
+			setup:      func(*mocks.MockChatService) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_TYPE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewMockChatService(t)
+			tt.setup(svc)
+			hooks := mocks.NewMockWebhookService(t)
+			hooks.EXPECT().IncomingWebhook("good").Return(hook, nil).Maybe()
+			hooks.EXPECT().IncomingWebhook("bad").Return(nil, gorm.ErrRecordNotFound).Maybe()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/hooks/"+tt.token, strings.NewReader(tt.body))
+			newWebhooksRouter(t, nil, svc, hooks).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, parseErrorResponse(t, w.Body.Bytes()).Code)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				assert.JSONEq(t, `{"id":"`+messageID.String()+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksHandler_Deliveries(t *testing.T) {
+	roomID := uuid.New()
+	webhookID := uuid.New()
+	actor := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	hooks := mocks.NewMockWebhookService(t)
+	hooks.EXPECT().Deliveries(roomID, actor.ID, webhookID, 10).Return([]service.DeliveryInfo{
+		{ID: uuid.New(), Attempt: 2, StatusCode: 200, Succeeded: true, Duration: 120 * time.Millisecond},
+		{ID: uuid.New(), Attempt: 1, Error: "connection refused"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newWebhooksRouter(t, actor, mocks.NewMockChatService(t), hooks).
+		ServeHTTP(w, authedRequest(http.MethodGet, "/rooms/"+roomID.String()+"/webhooks/"+webhookID.String()+"/deliveries?limit=10", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp []deliveryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp, 2)
+	assert.Equal(t, int64(120), resp[0].DurationMS)
+	assert.True(t, resp[0].Succeeded)
+	assert.Equal(t, "connection refused", resp[1].Error)
+}
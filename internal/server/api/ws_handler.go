@@ -2,12 +2,15 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/EwanGreer/chatatui/internal/middleware"
+	"github.com/EwanGreer/chatatui/internal/repository"
 	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
 	"github.com/coder/websocket"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -18,9 +21,11 @@ type WSHandler struct {
 	hub                 *hub.Hub
 	svc                 ChatService
 	messageHistoryLimit int
+	readReceipts        bool
+	flood               *hub.FloodControl
 }
 
-func NewWSHandler(h *hub.Hub, svc ChatService, messageHistoryLimit int) *WSHandler {
+func NewWSHandler(h *hub.Hub, svc ChatService, messageHistoryLimit int, readReceipts bool, flood *hub.FloodControl) *WSHandler {
 	go func() {
 		for {
 			time.Sleep(time.Second * 5)
@@ -32,6 +37,8 @@ func NewWSHandler(h *hub.Hub, svc ChatService, messageHistoryLimit int) *WSHandl
 		hub:                 h,
 		svc:                 svc,
 		messageHistoryLimit: messageHistoryLimit,
+		readReceipts:        readReceipts,
+		flood:               flood,
 	}
 }
 
@@ -58,6 +65,16 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user := middleware.UserFromContext(r.Context())
+	if err := h.svc.JoinRoom(roomInfo.ID, user.ID); err != nil {
+		if errors.Is(err, service.ErrNotRoomMember) {
+			writeError(w, http.StatusForbidden, "NOT_A_MEMBER", "you are not a member of this room")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to join room")
+		return
+	}
+
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{})
 	if err != nil {
 		slog.Error("failed to accept websocket", "error", err)
@@ -65,55 +82,205 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() { _ = conn.CloseNow() }()
 
-	room, err := h.hub.GetRoom(roomUUID)
-	if err != nil {
-		if !errors.Is(err, hub.ErrRoomNotFound) {
-			_ = conn.Close(websocket.StatusInternalError, "failed to join room")
-			return
-		}
+	client := hub.NewClient(conn, user.ID, roomUUID, user.Name)
+	client.ReadReceipts = h.readReceipts
+	client.Flood = h.flood
+	if err := h.hub.AddClient(client); err != nil {
+		slog.Error("failed to register client", "error", err, "user_id", user.ID)
+		_ = conn.Close(websocket.StatusInternalError, "failed to join room")
+		return
+	}
+	defer h.hub.RemoveClient(client)
 
-		room, err = h.hub.CreateRoom(roomUUID)
-		if err != nil {
-			_ = conn.Close(websocket.StatusInternalError, "failed to join room")
-			return
-		}
+	room, err := h.hub.Join(roomUUID, client)
+	if err != nil {
+		slog.Error("failed to join hub room", "error", err, "room_id", roomUUID)
+		_ = conn.Close(websocket.StatusInternalError, "failed to join room")
+		return
 	}
+	client.Run(room, h.backend(client, user))
+}
 
+// HandleMultiplexed serves a single connection on which the client subscribes
+// to any number of rooms. Membership is checked as each room is subscribed to
+// rather than up front.
+func (h *WSHandler) HandleMultiplexed(w http.ResponseWriter, r *http.Request) {
 	user := middleware.UserFromContext(r.Context())
-	if err := h.svc.AddRoomMember(roomInfo.ID, user.ID); err != nil {
-		slog.Error("failed to add room member", "error", err, "room_id", roomInfo.ID, "user_id", user.ID)
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{})
+	if err != nil {
+		slog.Error("failed to accept websocket", "error", err)
+		return
 	}
+	defer func() { _ = conn.CloseNow() }()
 
-	client := hub.NewClient(conn, user.ID, roomUUID, user.Name)
-	room.Add(client)
-	defer room.Remove(client)
+	client := hub.NewClient(conn, user.ID, uuid.Nil, user.Name)
+	client.ReadReceipts = h.readReceipts
+	client.Flood = h.flood
+	if err := h.hub.AddClient(client); err != nil {
+		slog.Error("failed to register client", "error", err, "user_id", user.ID)
+		_ = conn.Close(websocket.StatusInternalError, "failed to connect")
+		return
+	}
+	defer h.hub.RemoveClient(client)
+	client.RunMultiplexed(h.hub, h.backend(client, user))
+}
 
-	h.sendHistory(client, roomInfo.ID)
+func (h *WSHandler) backend(client *hub.Client, user *repository.User) wsBackend {
+	return wsBackend{
+		ChatService: h.svc,
+		pageSize:    h.messageHistoryLimit,
+		hub:         h.hub,
+		author:      client.Username,
+		readOnly:    !user.HasScope(repository.ScopeMessagesWrite),
+	}
+}
+
+// errReadOnly refuses messages from bots without the messages:write scope.
+var errReadOnly = fmt.Errorf("%w: this bot may not send messages", hub.ErrRefused)
 
-	client.Run(room, h.svc)
+// wsBackend adapts ChatService to the hub's Backend interface for one
+// client, whose user is the author of everything persisted through it.
+// Everything a read-only client tries to change is refused.
+type wsBackend struct {
+	ChatService
+	pageSize int
+	hub      *hub.Hub
+	author   string
+	readOnly bool
 }
 
-func (h *WSHandler) sendHistory(client *hub.Client, roomID uuid.UUID) {
-	messages, err := h.svc.GetMessageHistory(roomID, h.messageHistoryLimit, 0)
+func (b wsBackend) PersistMessage(content []byte, kind string, senderID, roomID uuid.UUID) (uuid.UUID, time.Time, error) {
+	if b.readOnly {
+		return uuid.Nil, time.Time{}, errReadOnly
+	}
+	id, createdAt, mentioned, err := b.ChatService.PersistMessage(content, kind, senderID, roomID)
+	if err == nil {
+		b.notifyMentioned(mentioned, hub.WireMessage{RoomID: roomID.String(), ID: id.String(), Content: string(content), Timestamp: createdAt})
+	}
+	return id, createdAt, refused(err)
+}
+
+func (b wsBackend) PersistReply(content []byte, senderID, roomID, parentID uuid.UUID) (uuid.UUID, time.Time, error) {
+	if b.readOnly {
+		return uuid.Nil, time.Time{}, errReadOnly
+	}
+	id, createdAt, mentioned, err := b.ChatService.PersistReply(content, senderID, roomID, parentID)
+	if err == nil {
+		b.notifyMentioned(mentioned, hub.WireMessage{RoomID: roomID.String(), ID: id.String(), ParentID: parentID.String(), Content: string(content), Timestamp: createdAt})
+	}
+	return id, createdAt, refused(err)
+}
+
+// notifyMentioned sends a mention event for msg to each of userIDs.
+func (b wsBackend) notifyMentioned(userIDs []uuid.UUID, msg hub.WireMessage) {
+	msg.Author = b.author
+	notifyMentioned(b.hub, userIDs, msg)
+}
+
+// notifyMentioned sends a mention event for msg, which names its author, to
+// each of userIDs.
+func notifyMentioned(h *hub.Hub, userIDs []uuid.UUID, msg hub.WireMessage) {
+	if len(userIDs) == 0 {
+		return
+	}
+	msg.Type = hub.MessageTypeMention
+	wireBytes, err := msg.Marshal()
 	if err != nil {
-		slog.Error("failed to get message history", "error", err, "room_id", roomID)
+		slog.Error("failed to marshal mention", "error", err, "message_id", msg.ID)
 		return
 	}
+	for _, userID := range userIDs {
+		h.NotifyUser(userID, wireBytes)
+	}
+}
+
+func (b wsBackend) EditMessage(id, senderID, roomID uuid.UUID, content []byte) (time.Time, error) {
+	if b.readOnly {
+		return time.Time{}, errReadOnly
+	}
+	editedAt, err := b.ChatService.EditMessage(id, senderID, roomID, content)
+	return editedAt, refused(err)
+}
+
+func (b wsBackend) DeleteMessage(id, senderID, roomID uuid.UUID) error {
+	if b.readOnly {
+		return errReadOnly
+	}
+	return b.ChatService.DeleteMessage(id, senderID, roomID)
+}
 
-	// Send messages in chronological order (oldest first)
-	for i := len(messages) - 1; i >= 0; i-- {
-		wire := &hub.WireMessage{
-			Type:      hub.MessageTypeChat,
-			ID:        messages[i].ID.String(),
-			Author:    messages[i].Author,
-			Content:   messages[i].Content,
-			Timestamp: messages[i].CreatedAt,
+func (b wsBackend) React(messageID, userID, roomID uuid.UUID, emoji string) (bool, error) {
+	if b.readOnly {
+		return false, errReadOnly
+	}
+	return b.ChatService.React(messageID, userID, roomID, emoji)
+}
+
+func (b wsBackend) Unreact(messageID, userID, roomID uuid.UUID, emoji string) (bool, error) {
+	if b.readOnly {
+		return false, errReadOnly
+	}
+	return b.ChatService.Unreact(messageID, userID, roomID, emoji)
+}
+
+// refused marks service errors that mean the message must not be delivered
+// at all, rather than that storing it failed.
+func refused(err error) error {
+	if errors.Is(err, service.ErrNotEncrypted) {
+		return fmt.Errorf("%w: %w", hub.ErrRefused, err)
+	}
+	return err
+}
+
+func (b wsBackend) LoadHistory(roomID, userID, before uuid.UUID) ([]hub.WireMessage, bool, error) {
+	page, err := b.GetMessagesBefore(roomID, userID, before, b.pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+	return wireMessages(page.Messages), page.HasMore, nil
+}
+
+func (b wsBackend) LoadThread(roomID, userID, parentID uuid.UUID) ([]hub.WireMessage, error) {
+	replies, err := b.GetThread(roomID, userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return wireMessages(replies), nil
+}
+
+func wireMessages(messages []service.MessageInfo) []hub.WireMessage {
+	wires := make([]hub.WireMessage, len(messages))
+	for i, m := range messages {
+		wires[i] = hub.WireMessage{
+			Type:        hub.MessageTypeChat,
+			ID:          m.ID.String(),
+			Author:      m.Author,
+			Content:     m.Content,
+			Timestamp:   m.CreatedAt,
+			EditedAt:    m.EditedAt,
+			ReplyCount:  m.ReplyCount,
+			LastReplyAt: m.LastReplyAt,
+		}
+		switch m.Kind {
+		case repository.MessageKindAction:
+			wires[i].Type = hub.MessageTypeAction
+		case repository.MessageKindFile:
+			wires[i].Type = hub.MessageTypeFile
+		}
+		if a := m.Attachment; a != nil {
+			wires[i].Attachment = &hub.Attachment{Name: a.Name, ContentType: a.ContentType, Size: a.Size}
+		}
+		if m.ParentID != nil {
+			wires[i].Type = hub.MessageTypeThreadReply
+			wires[i].ParentID = m.ParentID.String()
+		}
+		for _, r := range m.Reactions {
+			wires[i].Reactions = append(wires[i].Reactions, hub.Reaction{Emoji: r.Emoji, Count: r.Count, Mine: r.Mine})
 		}
-		wireBytes, err := wire.Marshal()
-		if err != nil {
-			slog.Error("failed to marshal history message", "error", err, "room_id", roomID)
-			continue
+		if m.RoomID != uuid.Nil {
+			wires[i].RoomID = m.RoomID.String()
 		}
-		client.SendRaw(wireBytes)
 	}
+	return wires
 }
@@ -7,8 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/EwanGreer/chatatui/internal/repository"
 	mocks "github.com/EwanGreer/chatatui/internal/server/api/_mocks"
 	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/EwanGreer/chatatui/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +22,7 @@ import (
 // shape, without starting the hub status goroutine.
 func newWSHandlerRouter(svc ChatService) http.Handler {
 	h := &WSHandler{
-		hub:                 hub.NewHub(),
+		hub:                 hub.NewHub(hub.NewMemoryBroker()),
 		svc:                 svc,
 		messageHistoryLimit: 50,
 	}
@@ -40,7 +42,7 @@ func TestWSHandler_MissingRoomID(t *testing.T) {
 	svc := mocks.NewMockChatService(t)
 
 	// Register a route without the {roomID} segment so chi sets it to "".
-	h := &WSHandler{hub: hub.NewHub(), svc: svc, messageHistoryLimit: 50}
+	h := &WSHandler{hub: hub.NewHub(hub.NewMemoryBroker()), svc: svc, messageHistoryLimit: 50}
 	r := chi.NewRouter()
 	r.Get("/ws/", h.Handle)
 
@@ -97,3 +99,33 @@ func TestWSHandler_RoomLookupInternalError(t *testing.T) {
 	resp := parseErrorResponse(t, w.Body.Bytes())
 	assert.Equal(t, "INTERNAL_ERROR", resp.Code)
 }
+
+func TestWSHandler_NotAMember(t *testing.T) {
+	roomID := uuid.New()
+	user := &repository.User{BaseModel: repository.BaseModel{ID: uuid.New()}}
+
+	svc := mocks.NewMockChatService(t)
+	svc.EXPECT().GetRoom(roomID).Return(&service.RoomInfo{ID: roomID, Name: "secret"}, nil)
+	svc.EXPECT().JoinRoom(roomID, user.ID).Return(service.ErrNotRoomMember)
+
+	router := authenticatedAs(t, user, newWSHandlerRouter(svc))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/ws/"+roomID.String(), ""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	resp := parseErrorResponse(t, w.Body.Bytes())
+	assert.Equal(t, "NOT_A_MEMBER", resp.Code)
+}
+
+func TestWSBackend_ReadOnlyBotIsRefused(t *testing.T) {
+	svc := mocks.NewMockChatService(t)
+	bot := &repository.User{Kind: repository.UserKindBot, Scopes: repository.ScopeMessagesRead}
+	h := &WSHandler{hub: hub.NewHub(hub.NewMemoryBroker()), svc: svc}
+	backend := h.backend(hub.NewClient(nil, uuid.New(), uuid.Nil, "echo"), bot)
+
+	_, _, err := backend.PersistMessage([]byte("hi"), repository.MessageKindChat, uuid.New(), uuid.New())
+	require.ErrorIs(t, err, hub.ErrRefused)
+	_, err = backend.React(uuid.New(), uuid.New(), uuid.New(), "👍")
+	require.ErrorIs(t, err, hub.ErrRefused)
+}
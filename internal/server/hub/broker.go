@@ -0,0 +1,43 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Broker fans room traffic out across server instances so that clients of the
+// same room connected to different nodes see each other's messages.
+type Broker interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe registers handler for every payload published on topic until
+	// the returned unsubscribe function is called.
+	Subscribe(ctx context.Context, topic string, handler func(payload []byte)) (unsubscribe func(), err error)
+	Close() error
+}
+
+// envelope wraps a wire message with the node it originated from so that the
+// publishing node can skip its own messages, which it has already delivered
+// locally. Room envelopes are published on the room's topic, and messages for
+// a single user on theirs. Envelopes with Disconnect set carry no payload and
+// instead ask every node to drop that user's connections to the room.
+//
+// A node that starts serving a room publishes SyncPresence so that the nodes
+// already serving it reply with their local Presence, since it missed the join
+// events sent before it subscribed.
+type envelope struct {
+	Origin       string               `json:"origin"`
+	Payload      json.RawMessage      `json:"payload,omitempty"`
+	Disconnect   string               `json:"disconnect,omitempty"`
+	SyncPresence bool                 `json:"sync_presence,omitempty"`
+	Presence     map[uuid.UUID]string `json:"presence,omitempty"`
+}
+
+func roomTopic(roomID uuid.UUID) string {
+	return "chatatui:room:" + roomID.String()
+}
+
+func userTopic(userID uuid.UUID) string {
+	return "chatatui:user:" + userID.String()
+}
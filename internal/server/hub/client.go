@@ -3,27 +3,100 @@ package hub
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/e2e"
 	"github.com/EwanGreer/chatatui/internal/limits"
 	"github.com/coder/websocket"
 	"github.com/google/uuid"
 )
 
+// ErrRefused is wrapped by MessagePersister errors for messages the room does
+// not accept, such as plaintext in an end-to-end encrypted room. Refused
+// messages are never broadcast, and the sender is shown the error.
+var ErrRefused = errors.New("message refused")
+
 // MessagePersister abstracts message persistence so the hub package
 // does not depend on the repository layer.
 type MessagePersister interface {
-	PersistMessage(content []byte, senderID, roomID uuid.UUID) (id uuid.UUID, createdAt time.Time, err error)
+	// PersistMessage stores a chat message or action; kind is the message
+	// type's string.
+	PersistMessage(content []byte, kind string, senderID, roomID uuid.UUID) (id uuid.UUID, createdAt time.Time, err error)
+	// PersistReply stores a reply to a top-level message in the same room.
+	PersistReply(content []byte, senderID, roomID, parentID uuid.UUID) (id uuid.UUID, createdAt time.Time, err error)
+	// EditMessage replaces the content of a message the sender authored.
+	EditMessage(id, senderID, roomID uuid.UUID, content []byte) (editedAt time.Time, err error)
+	// DeleteMessage removes a message the sender authored.
+	DeleteMessage(id, senderID, roomID uuid.UUID) error
+}
+
+// HistoryLoader pages backwards through a room's persisted messages for
+// clients scrolling up past what they were sent on join.
+type HistoryLoader interface {
+	// LoadHistory returns the messages older than before in chronological
+	// order, and whether even older messages exist. A nil before loads the
+	// newest page. Reactions are marked with whether userID made them.
+	LoadHistory(roomID, userID, before uuid.UUID) (messages []WireMessage, hasMore bool, err error)
+}
+
+// ThreadLoader fetches the replies to a message.
+type ThreadLoader interface {
+	// LoadThread returns the replies to parentID in chronological order.
+	LoadThread(roomID, userID, parentID uuid.UUID) ([]WireMessage, error)
+}
+
+// Reactor adds and withdraws emoji reactions. Both report whether anything
+// changed, so that repeated requests are not broadcast.
+type Reactor interface {
+	React(messageID, userID, roomID uuid.UUID, emoji string) (bool, error)
+	Unreact(messageID, userID, roomID uuid.UUID, emoji string) (bool, error)
+}
+
+// ReadMarker records how far through a room a member has read.
+type ReadMarker interface {
+	MarkRead(roomID, userID, messageID uuid.UUID) error
+}
+
+// RoomJoiner checks that a user may enter a room before a multiplexed client
+// subscribes to it.
+type RoomJoiner interface {
+	JoinRoom(roomID, userID uuid.UUID) error
+}
+
+// Backend is everything a client needs from outside the hub. It is
+// implemented by the API layer.
+type Backend interface {
+	MessagePersister
+	HistoryLoader
+	ThreadLoader
+	ReadMarker
+	Reactor
+	RoomJoiner
 }
 
+// Client is a single WebSocket connection. Clients connected through
+// /ws/{roomID} are bound to that room, which RoomID records; multiplexed
+// clients have a nil RoomID and subscribe to rooms as they go, naming the room
+// in every frame they send.
 type Client struct {
 	conn     *websocket.Conn
 	send     chan []byte
 	UserID   uuid.UUID
 	RoomID   uuid.UUID
 	Username string
+	// ReadReceipts relays the client's read events to the rest of the room.
+	ReadReceipts bool
+	// Flood limits how fast the client may post to each room. Nil allows
+	// everything.
+	Flood *FloodControl
+
+	mu     sync.Mutex
+	rooms  map[uuid.UUID]*Room
+	closed bool
 }
 
 func NewClient(conn *websocket.Conn, userID, roomID uuid.UUID, username string) *Client {
@@ -33,18 +106,36 @@ func NewClient(conn *websocket.Conn, userID, roomID uuid.UUID, username string)
 		UserID:   userID,
 		RoomID:   roomID,
 		Username: username,
+		rooms:    make(map[uuid.UUID]*Room),
 	}
 }
 
-func (c *Client) Run(room *Room, persister MessagePersister) {
+// Run serves a client bound to room: it joins the room, unless Hub.Join already
+// has, sends the most recent history as individual chat messages and then
+// handles frames until the connection closes.
+func (c *Client) Run(room *Room, backend Backend) {
+	c.join(room)
+	c.sendLegacyHistory(room, backend)
+	c.serve(nil, backend)
+}
+
+// RunMultiplexed serves a client that subscribes to rooms on h as it asks for
+// them, until the connection closes.
+func (c *Client) RunMultiplexed(h *Hub, backend Backend) {
+	c.serve(h, backend)
+}
+
+func (c *Client) serve(h *Hub, backend Backend) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	defer c.close()
+	defer c.leaveAll()
 
 	go c.writePump(ctx)
-	c.readPump(ctx, room, persister) // blocking
+	c.readPump(ctx, h, backend) // blocking
 }
 
-func (c *Client) readPump(ctx context.Context, room *Room, persister MessagePersister) {
+func (c *Client) readPump(ctx context.Context, h *Hub, backend Backend) {
 	defer func() { _ = c.conn.CloseNow() }()
 
 	for {
@@ -52,62 +143,611 @@ func (c *Client) readPump(ctx context.Context, room *Room, persister MessagePers
 		if err != nil {
 			return
 		}
+		c.handleFrame(h, backend, data)
+	}
+}
 
-		if len(data) > limits.MaxMessageLength {
-			errWire := &WireMessage{
-				Type:      MessageTypeError,
-				Content:   fmt.Sprintf("message too long (max %d characters)", limits.MaxMessageLength),
-				Timestamp: time.Now(),
-			}
-			if errBytes, err := errWire.Marshal(); err == nil {
-				c.Send(errBytes)
-			}
-			continue
+// handleFrame acts on one frame read from the client. Bound clients may send
+// chat as plain text, or as JSON the server does not otherwise understand;
+// multiplexed clients must say what each frame is.
+func (c *Client) handleFrame(h *Hub, backend Backend, data []byte) {
+	var peek WireMessage
+	if json.Unmarshal(data, &peek) != nil {
+		// Bound clients may send plain text chat messages.
+		if room, ok := c.targetRoom(""); ok && c.throttle(room) {
+			c.handleChat(room, backend, MessageTypeChat, data)
 		}
+		return
+	}
 
-		var peek WireMessage
-		if json.Unmarshal(data, &peek) == nil && peek.Type == MessageTypeTyping {
-			typingWire := &WireMessage{
-				Type:      MessageTypeTyping,
-				Author:    c.Username,
-				Timestamp: time.Now(),
-			}
-			typingBytes, err := typingWire.Marshal()
-			if err != nil {
-				slog.Error("failed to marshal typing event", "error", err, "user_id", c.UserID)
-				continue
+	switch peek.Type {
+	case MessageTypeSubscribe:
+		c.handleSubscribe(h, backend, peek)
+		return
+	case MessageTypeUnsubscribe:
+		c.handleUnsubscribe(peek)
+		return
+	case MessageTypeSystem:
+		if peek.Event == PresenceAway || peek.Event == PresenceBack {
+			for _, room := range c.joined() {
+				room.SetAway(c, peek.Event == PresenceAway)
 			}
-			room.Broadcast(typingBytes, c)
-			continue
 		}
+		return
+	}
 
-		msgID, createdAt, err := persister.PersistMessage(data, c.UserID, c.RoomID)
-		if err != nil {
-			slog.Error("failed to persist message", "error", err, "room_id", c.RoomID, "user_id", c.UserID)
+	room, ok := c.targetRoom(peek.RoomID)
+	if !ok {
+		return
+	}
+	if !floodExempt(peek.Type) && !c.throttle(room) {
+		return
+	}
+
+	switch peek.Type {
+	case MessageTypeTyping:
+		c.handleTyping(room)
+	case MessageTypeEdit:
+		c.handleEdit(room, backend, peek)
+	case MessageTypeDelete:
+		c.handleDelete(room, backend, peek)
+	case MessageTypeHistory:
+		c.handleHistory(room, backend, peek)
+	case MessageTypeRead:
+		c.handleRead(room, backend, peek)
+	case MessageTypeThreadReply:
+		c.handleReply(room, backend, peek)
+	case MessageTypeThread:
+		c.handleThread(room, backend, peek)
+	case MessageTypeReact, MessageTypeUnreact:
+		c.handleReaction(room, backend, peek)
+	case MessageTypeChat, MessageTypeAction:
+		c.handleChat(room, backend, peek.Type, []byte(peek.Content))
+	case MessageTypeFile:
+		c.sendError(room.ID, "files must be uploaded over HTTP")
+	default:
+		if c.RoomID == uuid.Nil {
+			c.sendError(room.ID, "unknown message type")
+			return
 		}
+		// Anything else from a bound client is chat text that happens
+		// to be valid JSON.
+		c.handleChat(room, backend, MessageTypeChat, data)
+	}
+}
+
+// floodExempt reports whether a frame type posts nothing to the room, and so is
+// not counted by flood control.
+func floodExempt(t MessageType) bool {
+	switch t {
+	case MessageTypeTyping, MessageTypeHistory, MessageTypeRead, MessageTypeThread:
+		return true
+	}
+	return false
+}
 
-		wire := &WireMessage{
-			Type:    MessageTypeChat,
-			ID:      msgID.String(),
-			Author:  c.Username,
-			Content: string(data),
+// throttle applies flood control to a frame for room. It reports whether the
+// frame may be handled, telling the client why not otherwise.
+func (c *Client) throttle(room *Room) bool {
+	verdict, wait := c.Flood.Allow(c.UserID, room.ID)
+	switch verdict {
+	case FloodLimited:
+		c.sendError(room.ID, fmt.Sprintf("slow down: you can send again in %s", ceilSeconds(wait)))
+	case FloodMuted:
+		c.sendError(room.ID, fmt.Sprintf("you are muted in this room for %s for flooding", ceilSeconds(wait)))
+	case FloodDisconnect:
+		slog.Warn("disconnecting client for flooding", "room_id", room.ID, "user_id", c.UserID)
+		c.sendError(room.ID, "disconnected for flooding")
+		c.Disconnect("flooding")
+	default:
+		return true
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) time.Duration {
+	return (d + time.Second - 1).Truncate(time.Second)
+}
+
+// targetRoom resolves the room a frame is addressed to. Bound clients may
+// omit the room ID. It reports an error to the client if the room is not one
+// it has joined.
+func (c *Client) targetRoom(rawID string) (*Room, bool) {
+	roomID := c.RoomID
+	if rawID != "" {
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			c.sendError(uuid.Nil, "invalid room id")
+			return nil, false
 		}
-		if createdAt.IsZero() {
-			wire.Timestamp = time.Now()
+		roomID = id
+	}
+
+	c.mu.Lock()
+	room, ok := c.rooms[roomID]
+	c.mu.Unlock()
+
+	if !ok {
+		if roomID == uuid.Nil {
+			c.sendError(uuid.Nil, "room_id is required")
 		} else {
-			wire.Timestamp = createdAt
+			c.sendError(roomID, "not subscribed to room")
 		}
+		return nil, false
+	}
+	return room, true
+}
+
+func (c *Client) handleSubscribe(h *Hub, backend Backend, req WireMessage) {
+	if h == nil {
+		c.sendError(c.RoomID, "this connection is bound to a single room")
+		return
+	}
+
+	roomID, err := uuid.Parse(req.RoomID)
+	if err != nil {
+		c.sendError(uuid.Nil, "invalid room id")
+		return
+	}
+
+	if err := backend.JoinRoom(roomID, c.UserID); err != nil {
+		slog.Warn("subscribe refused", "error", err, "room_id", roomID, "user_id", c.UserID)
+		c.sendError(roomID, "could not join room")
+		return
+	}
+
+	room, err := h.Join(roomID, c)
+	if err != nil {
+		slog.Error("failed to create hub room", "error", err, "room_id", roomID)
+		c.sendError(roomID, "could not join room")
+		return
+	}
+
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeSubscribe,
+		RoomID:    roomID.String(),
+		Timestamp: time.Now(),
+	})
+	c.handleHistory(room, backend, WireMessage{})
+}
+
+func (c *Client) handleUnsubscribe(req WireMessage) {
+	roomID, err := uuid.Parse(req.RoomID)
+	if err != nil {
+		c.sendError(uuid.Nil, "invalid room id")
+		return
+	}
+
+	c.leave(roomID)
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeUnsubscribe,
+		RoomID:    roomID.String(),
+		Timestamp: time.Now(),
+	})
+}
+
+func (c *Client) handleChat(room *Room, persister MessagePersister, kind MessageType, content []byte) {
+	if c.tooLong(room.ID, content) {
+		return
+	}
+
+	msgID, createdAt, persistErr := persister.PersistMessage(content, kind.String(), c.UserID, room.ID)
+	if errors.Is(persistErr, ErrRefused) {
+		c.sendError(room.ID, persistErr.Error())
+		return
+	}
+	if persistErr != nil {
+		slog.Error("failed to persist message", "error", persistErr, "room_id", room.ID, "user_id", c.UserID)
+	}
+
+	wire := &WireMessage{
+		Type:    kind,
+		RoomID:  room.ID.String(),
+		ID:      msgID.String(),
+		Author:  c.Username,
+		Content: string(content),
+	}
+	if createdAt.IsZero() {
+		wire.Timestamp = time.Now()
+	} else {
+		wire.Timestamp = createdAt
+	}
+
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal message", "error", err, "room_id", room.ID, "user_id", c.UserID)
+		return
+	}
+
+	room.Broadcast(wireBytes, c)
+
+	if persistErr == nil {
+		c.sendWire(&WireMessage{
+			Type:      MessageTypeAck,
+			RoomID:    room.ID.String(),
+			ID:        msgID.String(),
+			Content:   wire.Content,
+			Timestamp: wire.Timestamp,
+		})
+	}
+}
+
+// tooLong reports whether content is over the length limit, telling the
+// client if so. End-to-end encrypted messages carry a copy of their key for
+// every recipient, so they may be much longer.
+func (c *Client) tooLong(roomID uuid.UUID, content []byte) bool {
+	limit := limits.MaxMessageLength
+	if e2e.IsEnvelope(content) {
+		limit = limits.MaxEncryptedMessageLength
+	}
+	if len(content) <= limit {
+		return false
+	}
+	c.sendError(roomID, fmt.Sprintf("message too long (max %d characters)", limit))
+	return true
+}
+
+// refusal is what to tell a client whose message could not be stored: the
+// reason if the message was refused, and fallback otherwise.
+func refusal(err error, fallback string) string {
+	if errors.Is(err, ErrRefused) {
+		return err.Error()
+	}
+	return fallback
+}
+
+// handleReply persists a reply to req.ParentID and broadcasts it to the room.
+// Unlike chat messages, replies that cannot be stored are refused, since the
+// parent may not exist.
+func (c *Client) handleReply(room *Room, persister MessagePersister, req WireMessage) {
+	parentID, err := uuid.Parse(req.ParentID)
+	if err != nil {
+		c.sendError(room.ID, "invalid parent message id")
+		return
+	}
+
+	if req.Content == "" {
+		c.sendError(room.ID, "reply cannot be empty")
+		return
+	}
+
+	if c.tooLong(room.ID, []byte(req.Content)) {
+		return
+	}
+
+	msgID, createdAt, err := persister.PersistReply([]byte(req.Content), c.UserID, room.ID, parentID)
+	if err != nil {
+		slog.Warn("failed to persist reply", "error", err, "parent_id", parentID, "user_id", c.UserID)
+		c.sendError(room.ID, refusal(err, "could not reply to message"))
+		return
+	}
+
+	wire := &WireMessage{
+		Type:      MessageTypeThreadReply,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		ParentID:  parentID.String(),
+		Author:    c.Username,
+		Content:   req.Content,
+		Timestamp: createdAt,
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal reply", "error", err, "room_id", room.ID, "user_id", c.UserID)
+		return
+	}
+	room.Broadcast(wireBytes, c)
+
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeAck,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		ParentID:  parentID.String(),
+		Content:   req.Content,
+		Timestamp: createdAt,
+	})
+}
 
-		wireBytes, err := wire.Marshal()
+// handleThread answers with every reply to req.ParentID.
+func (c *Client) handleThread(room *Room, loader ThreadLoader, req WireMessage) {
+	parentID, err := uuid.Parse(req.ParentID)
+	if err != nil {
+		c.sendError(room.ID, "invalid parent message id")
+		return
+	}
+
+	replies, err := loader.LoadThread(room.ID, c.UserID, parentID)
+	if err != nil {
+		slog.Warn("failed to load thread", "error", err, "room_id", room.ID, "parent_id", parentID)
+		c.sendError(room.ID, "could not load thread")
+		return
+	}
+
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeThread,
+		RoomID:    room.ID.String(),
+		ParentID:  parentID.String(),
+		Messages:  replies,
+		Timestamp: time.Now(),
+	})
+}
+
+// handleReaction adds or withdraws a reaction and tells the rest of the room.
+func (c *Client) handleReaction(room *Room, reactor Reactor, req WireMessage) {
+	msgID, err := uuid.Parse(req.ID)
+	if err != nil {
+		c.sendError(room.ID, "invalid message id")
+		return
+	}
+
+	react := reactor.React
+	if req.Type == MessageTypeUnreact {
+		react = reactor.Unreact
+	}
+	changed, err := react(msgID, c.UserID, room.ID, req.Content)
+	if err != nil {
+		slog.Warn("failed to update reaction", "error", err, "message_id", msgID, "user_id", c.UserID)
+		c.sendError(room.ID, "could not react to message")
+		return
+	}
+	if !changed {
+		return
+	}
+
+	wire := &WireMessage{
+		Type:      req.Type,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		Author:    c.Username,
+		UserID:    c.UserID.String(),
+		Content:   req.Content,
+		Timestamp: time.Now(),
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal reaction", "error", err, "message_id", msgID)
+		return
+	}
+	room.Broadcast(wireBytes, c)
+}
+
+func (c *Client) handleTyping(room *Room) {
+	typingWire := &WireMessage{
+		Type:      MessageTypeTyping,
+		RoomID:    room.ID.String(),
+		Author:    c.Username,
+		Timestamp: time.Now(),
+	}
+	typingBytes, err := typingWire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal typing event", "error", err, "user_id", c.UserID)
+		return
+	}
+	room.Broadcast(typingBytes, c)
+}
+
+func (c *Client) handleEdit(room *Room, persister MessagePersister, req WireMessage) {
+	msgID, err := uuid.Parse(req.ID)
+	if err != nil {
+		c.sendError(room.ID, "invalid message id")
+		return
+	}
+
+	if req.Content == "" {
+		c.sendError(room.ID, "edited message cannot be empty")
+		return
+	}
+
+	if c.tooLong(room.ID, []byte(req.Content)) {
+		return
+	}
+
+	editedAt, err := persister.EditMessage(msgID, c.UserID, room.ID, []byte(req.Content))
+	if err != nil {
+		slog.Warn("failed to edit message", "error", err, "message_id", msgID, "user_id", c.UserID)
+		c.sendError(room.ID, refusal(err, "could not edit message"))
+		return
+	}
+
+	wire := &WireMessage{
+		Type:      MessageTypeEdit,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		Author:    c.Username,
+		Content:   req.Content,
+		Timestamp: editedAt,
+		EditedAt:  &editedAt,
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal edit", "error", err, "message_id", msgID)
+		return
+	}
+	room.Broadcast(wireBytes, c)
+}
+
+func (c *Client) handleDelete(room *Room, persister MessagePersister, req WireMessage) {
+	msgID, err := uuid.Parse(req.ID)
+	if err != nil {
+		c.sendError(room.ID, "invalid message id")
+		return
+	}
+
+	if err := persister.DeleteMessage(msgID, c.UserID, room.ID); err != nil {
+		slog.Warn("failed to delete message", "error", err, "message_id", msgID, "user_id", c.UserID)
+		c.sendError(room.ID, "could not delete message")
+		return
+	}
+
+	wire := &WireMessage{
+		Type:      MessageTypeDelete,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		Author:    c.Username,
+		Timestamp: time.Now(),
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal delete", "error", err, "message_id", msgID)
+		return
+	}
+	room.Broadcast(wireBytes, c)
+}
+
+func (c *Client) handleRead(room *Room, marker ReadMarker, req WireMessage) {
+	msgID, err := uuid.Parse(req.ID)
+	if err != nil {
+		c.sendError(room.ID, "invalid message id")
+		return
+	}
+
+	if err := marker.MarkRead(room.ID, c.UserID, msgID); err != nil {
+		slog.Warn("failed to mark message read", "error", err, "message_id", msgID, "user_id", c.UserID)
+		return
+	}
+
+	if !c.ReadReceipts {
+		return
+	}
+
+	wire := &WireMessage{
+		Type:      MessageTypeRead,
+		RoomID:    room.ID.String(),
+		ID:        msgID.String(),
+		Author:    c.Username,
+		UserID:    c.UserID.String(),
+		Timestamp: time.Now(),
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal read receipt", "error", err, "message_id", msgID)
+		return
+	}
+	room.Broadcast(wireBytes, c)
+}
+
+// handleHistory answers with the page of messages before req.ID, or the newest
+// page if req.ID is empty.
+func (c *Client) handleHistory(room *Room, loader HistoryLoader, req WireMessage) {
+	before := uuid.Nil
+	if req.ID != "" {
+		id, err := uuid.Parse(req.ID)
 		if err != nil {
-			slog.Error("failed to marshal message", "error", err, "room_id", c.RoomID, "user_id", c.UserID)
-			continue
+			c.sendError(room.ID, "invalid history cursor")
+			return
 		}
+		before = id
+	}
+
+	messages, hasMore, err := loader.LoadHistory(room.ID, c.UserID, before)
+	if err != nil {
+		slog.Error("failed to load history page", "error", err, "room_id", room.ID, "before", before)
+		c.sendError(room.ID, "could not load older messages")
+		return
+	}
+
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeHistory,
+		RoomID:    room.ID.String(),
+		Messages:  messages,
+		HasMore:   hasMore,
+		Timestamp: time.Now(),
+	})
+}
+
+// sendLegacyHistory sends the newest page of history to a bound client as
+// individual chat messages, which is what /ws/{roomID} clients expect on join.
+func (c *Client) sendLegacyHistory(room *Room, loader HistoryLoader) {
+	messages, _, err := loader.LoadHistory(room.ID, c.UserID, uuid.Nil)
+	if err != nil {
+		slog.Error("failed to get message history", "error", err, "room_id", room.ID)
+		return
+	}
+
+	for i := range messages {
+		messages[i].RoomID = room.ID.String()
+		c.sendWire(&messages[i])
+	}
+}
+
+func (c *Client) join(room *Room) {
+	c.mu.Lock()
+	_, already := c.rooms[room.ID]
+	c.rooms[room.ID] = room
+	c.mu.Unlock()
+
+	if !already {
+		room.Add(c)
+	}
+}
+
+func (c *Client) leave(roomID uuid.UUID) {
+	c.mu.Lock()
+	room, ok := c.rooms[roomID]
+	delete(c.rooms, roomID)
+	c.mu.Unlock()
 
-		room.Broadcast(wireBytes, c)
+	if ok {
+		room.Remove(c)
 	}
 }
 
+func (c *Client) leaveAll() {
+	for _, room := range c.joined() {
+		c.leave(room.ID)
+	}
+}
+
+func (c *Client) joined() []*Room {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rooms := make([]*Room, 0, len(c.rooms))
+	for _, room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// kick removes the client from room after its user was removed from it. A
+// bound client has nothing left to do and is disconnected; a multiplexed client
+// is unsubscribed and told why.
+func (c *Client) kick(room *Room, reason string) {
+	if c.RoomID != uuid.Nil {
+		c.Disconnect(reason)
+		return
+	}
+
+	c.leave(room.ID)
+	c.sendWire(&WireMessage{
+		Type:      MessageTypeUnsubscribe,
+		RoomID:    room.ID.String(),
+		Content:   reason,
+		Timestamp: time.Now(),
+	})
+}
+
+func (c *Client) sendError(roomID uuid.UUID, text string) {
+	wire := &WireMessage{
+		Type:      MessageTypeError,
+		Content:   text,
+		Timestamp: time.Now(),
+	}
+	if roomID != uuid.Nil {
+		wire.RoomID = roomID.String()
+	}
+	c.sendWire(wire)
+}
+
+func (c *Client) sendWire(wire *WireMessage) {
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal message", "error", err, "type", wire.Type, "user_id", c.UserID)
+		return
+	}
+	c.Send(wireBytes)
+}
+
 func (c *Client) writePump(ctx context.Context) {
 	for {
 		select {
@@ -125,7 +765,34 @@ func (c *Client) writePump(ctx context.Context) {
 	}
 }
 
+// Disconnect closes the client's connection, which ends its read pump and
+// removes it from its rooms.
+func (c *Client) Disconnect(reason string) {
+	if c.conn == nil {
+		return
+	}
+	// Close waits for the peer's close frame, so don't block the caller.
+	go func() { _ = c.conn.Close(websocket.StatusPolicyViolation, reason) }()
+}
+
+// close stops further sends to the client once it has left every room.
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
 func (c *Client) Send(msg []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
 	select {
 	case c.send <- msg:
 		slog.Debug("message sent to client", "user_id", c.UserID, "room_id", c.RoomID)
@@ -134,6 +801,12 @@ func (c *Client) Send(msg []byte) {
 }
 
 func (c *Client) SendRaw(msg []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
 	select {
 	case c.send <- msg:
 	default:
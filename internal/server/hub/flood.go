@@ -0,0 +1,144 @@
+package hub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FloodConfig configures per-user, per-room flood control. A zero Rate
+// disables it.
+type FloodConfig struct {
+	// Rate is how many messages per second a user may send to a room once
+	// their Burst is spent.
+	Rate  float64
+	Burst int
+	// MuteAfter refused messages in a row mute the user in the room for
+	// MuteFor. Zero never mutes.
+	MuteAfter int
+	MuteFor   time.Duration
+	// DisconnectAfter mutes in the same room disconnect the user instead.
+	// Zero never disconnects.
+	DisconnectAfter int
+}
+
+// FloodVerdict is FloodControl's decision about a single message.
+type FloodVerdict int
+
+const (
+	FloodAllowed FloodVerdict = iota
+	// FloodLimited refuses the message; the user is sending too fast.
+	FloodLimited
+	// FloodMuted refuses the message; the user is muted in the room.
+	FloodMuted
+	// FloodDisconnect refuses the message and asks for the client to be
+	// disconnected after repeated mutes.
+	FloodDisconnect
+)
+
+const (
+	// sweepEvery is how often idle buckets are dropped.
+	sweepEvery = time.Minute
+	// muteMemory is how long after a mute ends it still counts towards
+	// DisconnectAfter.
+	muteMemory = 10 * time.Minute
+)
+
+// FloodControl keeps a token bucket per user per room, shared by all of the
+// user's connections to this node. It is safe for concurrent use.
+type FloodControl struct {
+	cfg       FloodConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[floodKey]*bucket
+	lastSweep time.Time
+}
+
+type floodKey struct {
+	userID uuid.UUID
+	roomID uuid.UUID
+}
+
+type bucket struct {
+	tokens     float64
+	last       time.Time
+	strikes    int
+	mutes      int
+	mutedUntil time.Time
+}
+
+func NewFloodControl(cfg FloodConfig) *FloodControl {
+	return &FloodControl{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[floodKey]*bucket),
+	}
+}
+
+// Allow takes a token for a message from userID to roomID. When the message is
+// refused it also returns how long until the user may send again.
+func (f *FloodControl) Allow(userID, roomID uuid.UUID) (FloodVerdict, time.Duration) {
+	if f == nil || f.cfg.Rate <= 0 {
+		return FloodAllowed, 0
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.sweep(now)
+
+	key := floodKey{userID: userID, roomID: roomID}
+	b, ok := f.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(f.burst()), last: now}
+		f.buckets[key] = b
+	}
+
+	if now.Before(b.mutedUntil) {
+		return FloodMuted, b.mutedUntil.Sub(now)
+	}
+
+	b.tokens = min(float64(f.burst()), b.tokens+now.Sub(b.last).Seconds()*f.cfg.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.strikes = 0
+		return FloodAllowed, 0
+	}
+
+	b.strikes++
+	if f.cfg.MuteAfter > 0 && b.strikes >= f.cfg.MuteAfter {
+		b.strikes = 0
+		b.mutes++
+		b.mutedUntil = now.Add(f.cfg.MuteFor)
+		if f.cfg.DisconnectAfter > 0 && b.mutes >= f.cfg.DisconnectAfter {
+			return FloodDisconnect, f.cfg.MuteFor
+		}
+		return FloodMuted, f.cfg.MuteFor
+	}
+
+	wait := time.Duration((1 - b.tokens) / f.cfg.Rate * float64(time.Second))
+	return FloodLimited, wait
+}
+
+func (f *FloodControl) burst() int {
+	return max(1, f.cfg.Burst)
+}
+
+// sweep drops the buckets of users who have gone quiet and have not been
+// muted recently, so that they do not hold memory.
+func (f *FloodControl) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < sweepEvery {
+		return
+	}
+	f.lastSweep = now
+
+	for key, b := range f.buckets {
+		refilled := b.tokens+now.Sub(b.last).Seconds()*f.cfg.Rate >= float64(f.burst())
+		if refilled && now.Sub(b.mutedUntil) >= muteMemory {
+			delete(f.buckets, key)
+		}
+	}
+}
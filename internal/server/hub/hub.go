@@ -1,37 +1,121 @@
 package hub
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 var ErrRoomNotFound = errors.New("room not found")
 
+// subscribeTimeout bounds how long serving a room or a user waits for the
+// broker to start delivering their topic.
+const subscribeTimeout = 5 * time.Second
+
+// Hub tracks the rooms that have clients connected to this node, and the
+// clients themselves by user. Messages are fanned out to other nodes through
+// the broker so that a room, or a user's connections, can span several server
+// instances.
 type Hub struct {
-	Rooms map[uuid.UUID]*Room // TODO: this should be redis
-	mu    sync.RWMutex
+	Rooms  map[uuid.UUID]*Room
+	users  map[uuid.UUID]*userClients
+	mu     sync.RWMutex
+	nodeID string
+	broker Broker
+}
+
+// userClients is a user's connections to this node and the subscription to
+// their broker topic, held while they have any.
+type userClients struct {
+	clients     map[*Client]bool
+	unsubscribe func()
 }
 
-func NewHub() *Hub {
+func NewHub(broker Broker) *Hub {
 	return &Hub{
-		Rooms: make(map[uuid.UUID]*Room),
+		Rooms:  make(map[uuid.UUID]*Room),
+		users:  make(map[uuid.UUID]*userClients),
+		nodeID: uuid.NewString(),
+		broker: broker,
 	}
 }
 
 func (h *Hub) CreateRoom(roomUUID uuid.UUID) (*Room, error) {
+	return h.serve(roomUUID, func(*Room) {})
+}
+
+// Join adds c to the room, creating it if this node is not serving it yet.
+// c is added with the hub's lock held so that the room cannot be torn down
+// between being looked up and c being added.
+func (h *Hub) Join(roomID uuid.UUID, c *Client) (*Room, error) {
+	return h.serve(roomID, c.join)
+}
+
+// serve returns the room this node serves with roomID, creating and attaching
+// it if there is none, and calls fn with it while the hub's lock is held.
+//
+// The broker is subscribed to without the lock: it may only confirm the
+// subscription once it has handed on the messages that arrived before, and
+// their handlers take the lock too. If another caller attaches the room in the
+// meantime, theirs is kept and this one is shut down.
+func (h *Hub) serve(roomID uuid.UUID, fn func(*Room)) (*Room, error) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	if room, ok := h.Rooms[roomID]; ok {
+		fn(room)
+		h.mu.Unlock()
+		return room, nil
+	}
+	h.mu.Unlock()
 
-	room := NewRoom()
-	room.ID = roomUUID
+	fresh := NewRoom()
+	fresh.ID = roomID
+	if err := h.attach(fresh); err != nil {
+		return nil, err
+	}
 
-	h.Rooms[roomUUID] = room
+	h.mu.Lock()
+	room, ok := h.Rooms[roomID]
+	if !ok {
+		room = fresh
+		h.Rooms[roomID] = room
+	}
+	fn(room)
+	h.mu.Unlock()
 
+	if room != fresh {
+		fresh.Shutdown()
+		return room, nil
+	}
+	room.requestPresence()
 	return room, nil
 }
 
+// attach subscribes the room to its broker topic so that messages published
+// by other nodes are delivered to the room's local clients. It must be called
+// without the hub's lock held.
+func (h *Hub) attach(room *Room) error {
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+
+	unsubscribe, err := h.broker.Subscribe(ctx, roomTopic(room.ID), room.handleRemote)
+	if err != nil {
+		return fmt.Errorf("subscribing room %s: %w", room.ID, err)
+	}
+
+	room.nodeID = h.nodeID
+	room.broker = h.broker
+	room.unsubscribe = unsubscribe
+	room.hub = h
+
+	return nil
+}
+
 func (h *Hub) GetRoom(roomUUID uuid.UUID) (*Room, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -44,10 +128,17 @@ func (h *Hub) GetRoom(roomUUID uuid.UUID) (*Room, error) {
 	return room, nil
 }
 
-func (h *Hub) Add(room *Room) {
+func (h *Hub) Add(room *Room) error {
+	if err := h.attach(room); err != nil {
+		return err
+	}
+
 	h.mu.Lock()
 	h.Rooms[room.ID] = room
 	h.mu.Unlock()
+
+	room.requestPresence()
+	return nil
 }
 
 func (h *Hub) Remove(roomID uuid.UUID) {
@@ -62,6 +153,181 @@ func (h *Hub) Remove(roomID uuid.UUID) {
 	}
 }
 
+// release tears room down once its last local client has left, so that the
+// node stops listening to a room nobody here is in.
+func (h *Hub) release(room *Room) {
+	h.mu.Lock()
+	if h.Rooms[room.ID] != room || !room.empty() {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.Rooms, room.ID)
+	h.mu.Unlock()
+
+	room.Shutdown()
+}
+
+// Presence returns who is online in the room as seen from this node, or nil if
+// this node is not serving the room.
+func (h *Hub) Presence(roomID uuid.UUID) map[uuid.UUID]string {
+	h.mu.RLock()
+	room := h.Rooms[roomID]
+	h.mu.RUnlock()
+
+	if room == nil {
+		return nil
+	}
+	return room.Presence()
+}
+
+// DisconnectUser closes every connection userID has to the room, on this node
+// and on any other node sharing the broker.
+func (h *Hub) DisconnectUser(roomID, userID uuid.UUID) {
+	h.mu.RLock()
+	room := h.Rooms[roomID]
+	h.mu.RUnlock()
+
+	if room != nil {
+		room.disconnectLocal(userID)
+	}
+
+	data, err := json.Marshal(envelope{Origin: h.nodeID, Disconnect: userID.String()})
+	if err != nil {
+		slog.Error("failed to marshal disconnect envelope", "error", err, "room_id", roomID)
+		return
+	}
+	if err := h.broker.Publish(context.Background(), roomTopic(roomID), data); err != nil {
+		slog.Error("failed to publish disconnect", "error", err, "room_id", roomID, "user_id", userID)
+	}
+}
+
+// Publish sends msg to everyone connected to the room, on this node and on any
+// other node sharing the broker.
+func (h *Hub) Publish(roomID uuid.UUID, msg []byte) {
+	h.mu.RLock()
+	room := h.Rooms[roomID]
+	h.mu.RUnlock()
+
+	if room != nil {
+		room.Broadcast(msg, nil)
+		return
+	}
+
+	data, err := json.Marshal(envelope{Origin: h.nodeID, Payload: msg})
+	if err != nil {
+		slog.Error("failed to marshal room envelope", "error", err, "room_id", roomID)
+		return
+	}
+	if err := h.broker.Publish(context.Background(), roomTopic(roomID), data); err != nil {
+		slog.Error("failed to publish to room", "error", err, "room_id", roomID)
+	}
+}
+
+// AddClient registers c so that messages sent to its user with NotifyUser
+// reach it, whichever rooms it has open. The hub listens to the user's topic
+// while they have any connection here, and RemoveClient stops once they have
+// none; brokers share one connection between topics, so this costs no more
+// than a channel subscription. As in serve, the broker is subscribed to
+// without the hub's lock held.
+func (h *Hub) AddClient(c *Client) error {
+	h.mu.Lock()
+	if user := h.users[c.UserID]; user != nil {
+		user.clients[c] = true
+		h.mu.Unlock()
+		return nil
+	}
+	h.mu.Unlock()
+
+	userID := c.UserID
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+	unsubscribe, err := h.broker.Subscribe(ctx, userTopic(userID), func(data []byte) {
+		h.handleUserRemote(userID, data)
+	})
+	if err != nil {
+		return fmt.Errorf("subscribing user %s: %w", userID, err)
+	}
+
+	h.mu.Lock()
+	user := h.users[userID]
+	won := user == nil
+	if won {
+		user = &userClients{clients: make(map[*Client]bool), unsubscribe: unsubscribe}
+		h.users[userID] = user
+	}
+	user.clients[c] = true
+	h.mu.Unlock()
+
+	if !won {
+		unsubscribe()
+	}
+	return nil
+}
+
+// RemoveClient unregisters c once its connection has closed.
+func (h *Hub) RemoveClient(c *Client) {
+	h.mu.Lock()
+	user := h.users[c.UserID]
+	if user == nil {
+		h.mu.Unlock()
+		return
+	}
+	delete(user.clients, c)
+	last := len(user.clients) == 0
+	if last {
+		delete(h.users, c.UserID)
+	}
+	h.mu.Unlock()
+
+	if last {
+		user.unsubscribe()
+	}
+}
+
+// NotifyUser sends msg to every connection userID has, on this node and on any
+// other node sharing the broker.
+func (h *Hub) NotifyUser(userID uuid.UUID, msg []byte) {
+	h.deliverToUser(userID, msg)
+
+	data, err := json.Marshal(envelope{Origin: h.nodeID, Payload: msg})
+	if err != nil {
+		slog.Error("failed to marshal user envelope", "error", err, "user_id", userID)
+		return
+	}
+	if err := h.broker.Publish(context.Background(), userTopic(userID), data); err != nil {
+		slog.Error("failed to publish to user", "error", err, "user_id", userID)
+	}
+}
+
+// handleUserRemote delivers a message another node sent to one of this node's
+// users.
+func (h *Hub) handleUserRemote(userID uuid.UUID, data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		slog.Warn("dropping malformed broker envelope", "error", err, "user_id", userID)
+		return
+	}
+	if env.Origin == h.nodeID {
+		return
+	}
+	h.deliverToUser(userID, env.Payload)
+}
+
+func (h *Hub) deliverToUser(userID uuid.UUID, msg []byte) {
+	h.mu.RLock()
+	var clients []*Client
+	if user := h.users[userID]; user != nil {
+		for client := range user.clients {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.SendRaw(msg)
+	}
+}
+
 func (h *Hub) Broadcast(msg []byte, sender *Client) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -0,0 +1,686 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(roomID uuid.UUID, name string) *Client {
+	return NewClient(nil, uuid.New(), roomID, name)
+}
+
+func receive(t *testing.T, c *Client) []byte {
+	t.Helper()
+	select {
+	case msg := <-c.send:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("client %s received nothing", c.Username)
+		return nil
+	}
+}
+
+// drain discards everything queued for the clients, such as the presence
+// events sent while a test sets up a room.
+func drain(clients ...*Client) {
+	for _, c := range clients {
+		for len(c.send) > 0 {
+			<-c.send
+		}
+	}
+}
+
+func receiveWire(t *testing.T, c *Client) WireMessage {
+	t.Helper()
+	var wire WireMessage
+	require.NoError(t, json.Unmarshal(receive(t, c), &wire))
+	return wire
+}
+
+func assertNothingReceived(t *testing.T, c *Client) {
+	t.Helper()
+	select {
+	case msg := <-c.send:
+		t.Fatalf("client %s unexpectedly received %s", c.Username, msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHub_CreateRoom_ReturnsExistingRoom(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	roomID := uuid.New()
+
+	first, err := h.CreateRoom(roomID)
+	require.NoError(t, err)
+	second, err := h.CreateRoom(roomID)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+}
+
+func TestRoom_Broadcast_FansOutAcrossHubs(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+	roomID := uuid.New()
+
+	roomA, err := nodeA.CreateRoom(roomID)
+	require.NoError(t, err)
+	roomB, err := nodeB.CreateRoom(roomID)
+	require.NoError(t, err)
+
+	sender := newTestClient(roomID, "alice")
+	localPeer := newTestClient(roomID, "bob")
+	remotePeer := newTestClient(roomID, "carol")
+	roomA.Add(sender)
+	roomA.Add(localPeer)
+	roomB.Add(remotePeer)
+	drain(sender, localPeer, remotePeer)
+
+	msg := []byte(`{"type":"chat","content":"hello"}`)
+	roomA.Broadcast(msg, sender)
+
+	assert.JSONEq(t, string(msg), string(receive(t, localPeer)))
+	assert.JSONEq(t, string(msg), string(receive(t, remotePeer)))
+	assertNothingReceived(t, sender)
+	// The origin node must not deliver its own message a second time.
+	assertNothingReceived(t, localPeer)
+}
+
+func TestHub_Publish_ReachesRoomOnAnotherHub(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+	roomID := uuid.New()
+
+	roomB, err := nodeB.CreateRoom(roomID)
+	require.NoError(t, err)
+	client := newTestClient(roomID, "alice")
+	roomB.Add(client)
+	drain(client)
+
+	// nodeA has no clients in the room, so it only publishes to the broker.
+	msg := []byte(`{"type":"topic","content":"release day"}`)
+	nodeA.Publish(roomID, msg)
+
+	assert.JSONEq(t, string(msg), string(receive(t, client)))
+}
+
+func TestHub_NotifyUser_ReachesEveryConnection(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+
+	local := newTestClient(uuid.New(), "alice")
+	remote := NewClient(nil, local.UserID, uuid.Nil, "alice")
+	other := newTestClient(uuid.New(), "bob")
+	require.NoError(t, nodeA.AddClient(local))
+	require.NoError(t, nodeB.AddClient(remote))
+	require.NoError(t, nodeA.AddClient(other))
+
+	msg := []byte(`{"type":"mention","content":"@alice look"}`)
+	nodeA.NotifyUser(local.UserID, msg)
+
+	assert.JSONEq(t, string(msg), string(receive(t, local)))
+	assert.JSONEq(t, string(msg), string(receive(t, remote)))
+	assertNothingReceived(t, local)
+	assertNothingReceived(t, other)
+
+	nodeB.RemoveClient(remote)
+	nodeA.NotifyUser(local.UserID, msg)
+	receive(t, local)
+	assertNothingReceived(t, remote)
+}
+
+func TestHub_RemoveClient_UnsubscribesUserWhenLastClientLeaves(t *testing.T) {
+	broker := NewMemoryBroker()
+	h := NewHub(broker)
+
+	first := NewClient(nil, uuid.New(), uuid.Nil, "alice")
+	second := NewClient(nil, first.UserID, uuid.Nil, "alice")
+	require.NoError(t, h.AddClient(first))
+	require.NoError(t, h.AddClient(second))
+	assert.Len(t, broker.subs[userTopic(first.UserID)], 1, "a user's connections should share one subscription")
+
+	h.RemoveClient(first)
+	assert.Contains(t, broker.subs, userTopic(first.UserID))
+
+	h.RemoveClient(second)
+	assert.NotContains(t, broker.subs, userTopic(first.UserID))
+}
+
+func TestRoom_Broadcast_IsolatedByRoom(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+
+	roomA, err := nodeA.CreateRoom(uuid.New())
+	require.NoError(t, err)
+	otherRoom, err := nodeB.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	outsider := newTestClient(otherRoom.ID, "dave")
+	otherRoom.Add(outsider)
+
+	roomA.Broadcast([]byte(`{"type":"chat"}`), nil)
+
+	assertNothingReceived(t, outsider)
+}
+
+func TestRoom_Shutdown_Unsubscribes(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+	roomID := uuid.New()
+
+	roomA, err := nodeA.CreateRoom(roomID)
+	require.NoError(t, err)
+	_, err = nodeB.CreateRoom(roomID)
+	require.NoError(t, err)
+
+	remotePeer := newTestClient(roomID, "carol")
+	roomB, err := nodeB.GetRoom(roomID)
+	require.NoError(t, err)
+	roomB.Add(remotePeer)
+
+	nodeB.Remove(roomID)
+	roomA.Broadcast([]byte(`{"type":"chat"}`), nil)
+
+	assertNothingReceived(t, remotePeer)
+}
+
+func TestHub_Join_TearsRoomDownWhenEmpty(t *testing.T) {
+	broker := NewMemoryBroker()
+	h := NewHub(broker)
+	roomID := uuid.New()
+
+	first := NewClient(nil, uuid.New(), uuid.Nil, "alice")
+	second := NewClient(nil, uuid.New(), uuid.Nil, "bob")
+	room, err := h.Join(roomID, first)
+	require.NoError(t, err)
+	again, err := h.Join(roomID, second)
+	require.NoError(t, err)
+	assert.Same(t, room, again)
+
+	first.leave(roomID)
+	_, err = h.GetRoom(roomID)
+	require.NoError(t, err, "the room still has a client")
+
+	second.leave(roomID)
+	_, err = h.GetRoom(roomID)
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+	assert.NotContains(t, broker.subs, roomTopic(roomID), "an empty room should stop listening to the broker")
+
+	rejoined, err := h.Join(roomID, first)
+	require.NoError(t, err)
+	assert.NotSame(t, room, rejoined)
+	assert.Contains(t, broker.subs, roomTopic(roomID))
+}
+
+func TestRoom_Presence_AnnouncesJoinAndLeaveOncePerUser(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	watcher := newTestClient(room.ID, "bob")
+	room.Add(watcher)
+
+	first := newTestClient(room.ID, "alice")
+	second := NewClient(nil, first.UserID, room.ID, "alice")
+
+	room.Add(first)
+	join := receiveWire(t, watcher)
+	assert.Equal(t, MessageTypeSystem, join.Type)
+	assert.Equal(t, PresenceJoin, join.Event)
+	assert.Equal(t, first.UserID.String(), join.UserID)
+
+	room.Add(second)
+	assertNothingReceived(t, watcher)
+
+	room.Remove(first)
+	assertNothingReceived(t, watcher)
+	assert.Equal(t, StatusOnline, room.Presence()[first.UserID])
+
+	room.Remove(second)
+	assert.Equal(t, PresenceLeave, receiveWire(t, watcher).Event)
+	assert.NotContains(t, room.Presence(), first.UserID)
+}
+
+func TestRoom_SetAway(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	watcher := newTestClient(room.ID, "bob")
+	idler := newTestClient(room.ID, "alice")
+	room.Add(watcher)
+	room.Add(idler)
+	drain(watcher)
+
+	room.SetAway(idler, true)
+	assert.Equal(t, PresenceAway, receiveWire(t, watcher).Event)
+	assert.Equal(t, StatusAway, room.Presence()[idler.UserID])
+
+	room.SetAway(idler, true)
+	assertNothingReceived(t, watcher)
+
+	room.SetAway(idler, false)
+	assert.Equal(t, PresenceBack, receiveWire(t, watcher).Event)
+	assert.Equal(t, StatusOnline, room.Presence()[idler.UserID])
+}
+
+func TestRoom_Presence_SpansHubs(t *testing.T) {
+	broker := NewMemoryBroker()
+	nodeA := NewHub(broker)
+	nodeB := NewHub(broker)
+	roomID := uuid.New()
+
+	roomA, err := nodeA.CreateRoom(roomID)
+	require.NoError(t, err)
+	early := newTestClient(roomID, "alice")
+	roomA.Add(early)
+
+	// nodeB starts serving the room after alice joined, so it learns about
+	// her from the sync reply rather than the join event.
+	roomB, err := nodeB.CreateRoom(roomID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOnline, roomB.Presence()[early.UserID])
+
+	late := newTestClient(roomID, "bob")
+	roomB.Add(late)
+	assert.Equal(t, StatusOnline, roomA.Presence()[late.UserID])
+
+	roomB.Remove(late)
+	assert.NotContains(t, roomA.Presence(), late.UserID)
+}
+
+type markerFunc func(roomID, userID, messageID uuid.UUID) error
+
+func (f markerFunc) MarkRead(roomID, userID, messageID uuid.UUID) error {
+	return f(roomID, userID, messageID)
+}
+
+func TestClient_HandleRead_RelaysReceiptsWhenEnabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		readReceipts bool
+	}{
+		{name: "relays receipt", readReceipts: true},
+		{name: "keeps receipt private", readReceipts: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(NewMemoryBroker())
+			room, err := h.CreateRoom(uuid.New())
+			require.NoError(t, err)
+
+			reader := newTestClient(room.ID, "alice")
+			reader.ReadReceipts = tt.readReceipts
+			peer := newTestClient(room.ID, "bob")
+			room.Add(reader)
+			room.Add(peer)
+			drain(reader, peer)
+
+			msgID := uuid.New()
+			var marked uuid.UUID
+			reader.handleRead(room, markerFunc(func(_, _, id uuid.UUID) error {
+				marked = id
+				return nil
+			}), WireMessage{Type: MessageTypeRead, ID: msgID.String()})
+
+			assert.Equal(t, msgID, marked)
+			if !tt.readReceipts {
+				assertNothingReceived(t, peer)
+				return
+			}
+			receipt := receiveWire(t, peer)
+			assert.Equal(t, MessageTypeRead, receipt.Type)
+			assert.Equal(t, msgID.String(), receipt.ID)
+			assert.Equal(t, "alice", receipt.Author)
+		})
+	}
+}
+
+// stubBackend persists nothing and lets every user join every room unless
+// joinErr is set.
+type stubBackend struct {
+	joinErr error
+}
+
+func (stubBackend) PersistMessage([]byte, string, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, error) {
+	return uuid.New(), time.Now(), nil
+}
+
+func (stubBackend) PersistReply([]byte, uuid.UUID, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, error) {
+	return uuid.New(), time.Now(), nil
+}
+
+func (stubBackend) LoadThread(uuid.UUID, uuid.UUID, uuid.UUID) ([]WireMessage, error) {
+	return nil, nil
+}
+
+func (stubBackend) EditMessage(uuid.UUID, uuid.UUID, uuid.UUID, []byte) (time.Time, error) {
+	return time.Now(), nil
+}
+
+func (stubBackend) DeleteMessage(uuid.UUID, uuid.UUID, uuid.UUID) error { return nil }
+
+func (stubBackend) LoadHistory(uuid.UUID, uuid.UUID, uuid.UUID) ([]WireMessage, bool, error) {
+	return nil, false, nil
+}
+
+func (stubBackend) React(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error) { return true, nil }
+
+func (stubBackend) Unreact(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error) { return true, nil }
+
+func (stubBackend) MarkRead(uuid.UUID, uuid.UUID, uuid.UUID) error { return nil }
+
+func (b stubBackend) JoinRoom(uuid.UUID, uuid.UUID) error { return b.joinErr }
+
+func TestClient_Subscribe_RoutesMessagesByRoom(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	first, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+	second, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	client := newTestClient(uuid.Nil, "alice")
+	peer := newTestClient(second.ID, "bob")
+	second.Add(peer)
+
+	for _, room := range []*Room{first, second} {
+		client.handleSubscribe(h, stubBackend{}, WireMessage{Type: MessageTypeSubscribe, RoomID: room.ID.String()})
+
+		ack := receiveWire(t, client)
+		assert.Equal(t, MessageTypeSubscribe, ack.Type)
+		assert.Equal(t, room.ID.String(), ack.RoomID)
+
+		page := receiveWire(t, client)
+		assert.Equal(t, MessageTypeHistory, page.Type)
+		assert.Equal(t, room.ID.String(), page.RoomID)
+	}
+	drain(client, peer)
+
+	room, ok := client.targetRoom(second.ID.String())
+	require.True(t, ok)
+	client.handleChat(room, stubBackend{}, MessageTypeChat, []byte("hello"))
+
+	msg := receiveWire(t, peer)
+	assert.Equal(t, MessageTypeChat, msg.Type)
+	assert.Equal(t, second.ID.String(), msg.RoomID)
+	assert.Equal(t, "hello", msg.Content)
+	assert.Equal(t, MessageTypeAck, receiveWire(t, client).Type)
+
+	first.Broadcast([]byte(`{"type":"chat","content":"only in first"}`), nil)
+	assert.Equal(t, "only in first", receiveWire(t, client).Content)
+	assertNothingReceived(t, peer)
+}
+
+// persistCounter counts the chat messages a client stores.
+type persistCounter struct {
+	stubBackend
+	persisted int
+}
+
+func (b *persistCounter) PersistMessage([]byte, string, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, error) {
+	b.persisted++
+	return uuid.New(), time.Now(), nil
+}
+
+func TestClient_HandleFrame_UnknownType(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+	frame := []byte(`{"type":"ack","room_id":"` + room.ID.String() + `"}`)
+
+	t.Run("multiplexed client is told and nothing is stored", func(t *testing.T) {
+		backend := &persistCounter{}
+		client := newTestClient(uuid.Nil, "alice")
+		client.join(room)
+		drain(client)
+
+		client.handleFrame(h, backend, frame)
+
+		reply := receiveWire(t, client)
+		assert.Equal(t, MessageTypeError, reply.Type)
+		assert.Equal(t, room.ID.String(), reply.RoomID)
+		assert.Equal(t, "unknown message type", reply.Content)
+		assert.Zero(t, backend.persisted)
+	})
+
+	t.Run("bound client sends it as chat", func(t *testing.T) {
+		backend := &persistCounter{}
+		client := newTestClient(room.ID, "bob")
+		client.join(room)
+		drain(client)
+
+		client.handleFrame(nil, backend, frame)
+
+		assert.Equal(t, 1, backend.persisted)
+	})
+}
+
+func TestClient_Subscribe_Refused(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	roomID := uuid.New()
+	client := newTestClient(uuid.Nil, "alice")
+
+	client.handleSubscribe(h, stubBackend{joinErr: assert.AnError}, WireMessage{Type: MessageTypeSubscribe, RoomID: roomID.String()})
+
+	reply := receiveWire(t, client)
+	assert.Equal(t, MessageTypeError, reply.Type)
+	assert.Equal(t, roomID.String(), reply.RoomID)
+
+	_, ok := client.targetRoom(roomID.String())
+	assert.False(t, ok)
+}
+
+func TestClient_TargetRoom_RequiresSubscription(t *testing.T) {
+	client := newTestClient(uuid.Nil, "alice")
+
+	_, ok := client.targetRoom("")
+	assert.False(t, ok)
+	assert.Equal(t, "room_id is required", receiveWire(t, client).Content)
+
+	roomID := uuid.New()
+	_, ok = client.targetRoom(roomID.String())
+	assert.False(t, ok)
+	reply := receiveWire(t, client)
+	assert.Equal(t, MessageTypeError, reply.Type)
+	assert.Equal(t, roomID.String(), reply.RoomID)
+}
+
+func TestClient_Unsubscribe_LeavesRoom(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	client := newTestClient(uuid.Nil, "alice")
+	peer := newTestClient(room.ID, "bob")
+	room.Add(peer)
+	client.join(room)
+	drain(client, peer)
+
+	client.handleUnsubscribe(WireMessage{Type: MessageTypeUnsubscribe, RoomID: room.ID.String()})
+
+	reply := receiveWire(t, client)
+	assert.Equal(t, MessageTypeUnsubscribe, reply.Type)
+	assert.Equal(t, room.ID.String(), reply.RoomID)
+
+	leave := receiveWire(t, peer)
+	assert.Equal(t, PresenceLeave, leave.Event)
+
+	room.Broadcast([]byte(`{"type":"chat","content":"after"}`), nil)
+	receive(t, peer)
+	assertNothingReceived(t, client)
+}
+
+func TestRoom_DisconnectLocal_UnsubscribesMultiplexedClient(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	client := newTestClient(uuid.Nil, "alice")
+	client.join(room)
+	drain(client)
+
+	room.disconnectLocal(client.UserID)
+
+	reply := receiveWire(t, client)
+	assert.Equal(t, MessageTypeUnsubscribe, reply.Type)
+	assert.Equal(t, room.ID.String(), reply.RoomID)
+	assert.Equal(t, "removed from room", reply.Content)
+	assert.Empty(t, client.joined())
+}
+
+func TestClient_HandleReply_BroadcastsThreadReply(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	author := newTestClient(room.ID, "alice")
+	peer := newTestClient(room.ID, "bob")
+	author.join(room)
+	peer.join(room)
+	drain(author, peer)
+
+	parentID := uuid.New()
+	author.handleReply(room, stubBackend{}, WireMessage{Type: MessageTypeThreadReply, ParentID: parentID.String(), Content: "agreed"})
+
+	reply := receiveWire(t, peer)
+	assert.Equal(t, MessageTypeThreadReply, reply.Type)
+	assert.Equal(t, parentID.String(), reply.ParentID)
+	assert.Equal(t, room.ID.String(), reply.RoomID)
+	assert.Equal(t, "agreed", reply.Content)
+
+	ack := receiveWire(t, author)
+	assert.Equal(t, MessageTypeAck, ack.Type)
+	assert.Equal(t, parentID.String(), ack.ParentID)
+
+	author.handleReply(room, stubBackend{}, WireMessage{Type: MessageTypeThreadReply, ParentID: "nope", Content: "agreed"})
+	assert.Equal(t, MessageTypeError, receiveWire(t, author).Type)
+	assertNothingReceived(t, peer)
+}
+
+func TestClient_HandleReaction_BroadcastsChanges(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	reactor := newTestClient(room.ID, "alice")
+	peer := newTestClient(room.ID, "bob")
+	reactor.join(room)
+	peer.join(room)
+	drain(reactor, peer)
+
+	msgID := uuid.New()
+	reactor.handleReaction(room, stubBackend{}, WireMessage{Type: MessageTypeReact, ID: msgID.String(), Content: "👍"})
+
+	wire := receiveWire(t, peer)
+	assert.Equal(t, MessageTypeReact, wire.Type)
+	assert.Equal(t, msgID.String(), wire.ID)
+	assert.Equal(t, "👍", wire.Content)
+	assert.Equal(t, reactor.UserID.String(), wire.UserID)
+	assertNothingReceived(t, reactor)
+
+	reactor.handleReaction(room, unchangedReactor{}, WireMessage{Type: MessageTypeUnreact, ID: msgID.String(), Content: "👍"})
+	assertNothingReceived(t, peer)
+}
+
+func TestClient_HandleChat_RefusedIsNotBroadcast(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	author := newTestClient(room.ID, "alice")
+	peer := newTestClient(room.ID, "bob")
+	author.join(room)
+	peer.join(room)
+	drain(author, peer)
+
+	author.handleChat(room, refusingPersister{}, MessageTypeChat, []byte("plaintext"))
+
+	reply := receiveWire(t, author)
+	assert.Equal(t, MessageTypeError, reply.Type)
+	assert.Equal(t, "message refused: room is encrypted", reply.Content)
+	assertNothingReceived(t, peer)
+}
+
+// refusingPersister refuses every message.
+type refusingPersister struct{ stubBackend }
+
+func (refusingPersister) PersistMessage([]byte, string, uuid.UUID, uuid.UUID) (uuid.UUID, time.Time, error) {
+	return uuid.Nil, time.Time{}, fmt.Errorf("%w: room is encrypted", ErrRefused)
+}
+
+// unchangedReactor reports every reaction as already being in place.
+type unchangedReactor struct{}
+
+func (unchangedReactor) React(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error) {
+	return false, nil
+}
+
+func (unchangedReactor) Unreact(uuid.UUID, uuid.UUID, uuid.UUID, string) (bool, error) {
+	return false, nil
+}
+
+func TestFloodControl_Escalates(t *testing.T) {
+	now := time.Now()
+	flood := NewFloodControl(FloodConfig{Rate: 1, Burst: 2, MuteAfter: 2, MuteFor: 10 * time.Second, DisconnectAfter: 2})
+	flood.now = func() time.Time { return now }
+	userID, roomID := uuid.New(), uuid.New()
+
+	allow := func() FloodVerdict {
+		verdict, _ := flood.Allow(userID, roomID)
+		return verdict
+	}
+
+	assert.Equal(t, FloodAllowed, allow())
+	assert.Equal(t, FloodAllowed, allow())
+	verdict, wait := flood.Allow(userID, roomID)
+	assert.Equal(t, FloodLimited, verdict)
+	assert.Equal(t, time.Second, wait)
+
+	// Another room has its own bucket.
+	other, _ := flood.Allow(userID, uuid.New())
+	assert.Equal(t, FloodAllowed, other)
+
+	assert.Equal(t, FloodMuted, allow(), "second refusal in a row mutes")
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, FloodMuted, allow(), "still muted")
+
+	now = now.Add(6 * time.Second)
+	assert.Equal(t, FloodAllowed, allow(), "bucket refilled once the mute ends")
+	assert.Equal(t, FloodAllowed, allow())
+	assert.Equal(t, FloodLimited, allow())
+	assert.Equal(t, FloodDisconnect, allow(), "second mute disconnects")
+}
+
+func TestFloodControl_NilAllowsEverything(t *testing.T) {
+	var flood *FloodControl
+	verdict, _ := flood.Allow(uuid.New(), uuid.New())
+	assert.Equal(t, FloodAllowed, verdict)
+}
+
+func TestClient_Throttle_ExplainsSlowdown(t *testing.T) {
+	h := NewHub(NewMemoryBroker())
+	room, err := h.CreateRoom(uuid.New())
+	require.NoError(t, err)
+
+	client := newTestClient(room.ID, "alice")
+	client.Flood = NewFloodControl(FloodConfig{Rate: 0.5, Burst: 1})
+
+	assert.True(t, client.throttle(room))
+	assert.False(t, client.throttle(room))
+
+	reply := receiveWire(t, client)
+	assert.Equal(t, MessageTypeError, reply.Type)
+	assert.Equal(t, "slow down: you can send again in 2s", reply.Content)
+}
@@ -0,0 +1,65 @@
+package hub
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker is an in-process Broker. It only fans out between hubs that
+// share the same instance, which makes it suitable for single-node deployments
+// and tests.
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	subs   map[string]map[int]func([]byte)
+	nextID int
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs: make(map[string]map[int]func([]byte)),
+	}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic string, payload []byte) error {
+	b.mu.RLock()
+	handlers := make([]func([]byte), 0, len(b.subs[topic]))
+	for _, handler := range b.subs[topic] {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(_ context.Context, topic string, handler func([]byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[int]func([]byte))
+	}
+	b.subs[topic][id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subs[topic], id)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+	}, nil
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	b.subs = make(map[string]map[int]func([]byte))
+	b.mu.Unlock()
+	return nil
+}
@@ -8,10 +8,51 @@ import (
 type MessageType string
 
 const (
-	MessageTypeChat   MessageType = "chat"
+	MessageTypeChat MessageType = "chat"
+	// MessageTypeAction is a chat message sent with /me, shown in the third
+	// person.
+	MessageTypeAction MessageType = "action"
 	MessageTypeSystem MessageType = "system"
 	MessageTypeTyping MessageType = "typing"
 	MessageTypeError  MessageType = "error"
+	MessageTypeEdit   MessageType = "edit"
+	MessageTypeDelete MessageType = "delete"
+	// MessageTypeAck is sent back to the author of a chat message once it has
+	// been persisted, carrying the ID assigned by the server.
+	MessageTypeAck MessageType = "ack"
+	// MessageTypeHistory is sent by a client with the ID of the oldest message
+	// it holds, and answered with the page of messages before it.
+	MessageTypeHistory MessageType = "history"
+	// MessageTypeRead is sent by a client with the ID of the newest message it
+	// has seen. When read receipts are enabled it is relayed to the room with
+	// the reader's name.
+	MessageTypeRead MessageType = "read"
+	// MessageTypeSubscribe and MessageTypeUnsubscribe are sent by multiplexed
+	// clients to join and leave rooms, and echoed back once done. The server
+	// also sends an unsubscribe, with a reason, when a user is removed from a
+	// room.
+	MessageTypeSubscribe   MessageType = "subscribe"
+	MessageTypeUnsubscribe MessageType = "unsubscribe"
+	// MessageTypeTopic announces a room's new topic, carried in Content.
+	MessageTypeTopic MessageType = "topic"
+	// MessageTypeThreadReply is a reply to the message named by ParentID. It
+	// is broadcast to the whole room so clients can update reply counts.
+	MessageTypeThreadReply MessageType = "thread_reply"
+	// MessageTypeThread is sent by a client with a ParentID, and answered with
+	// every reply in that thread.
+	MessageTypeThread MessageType = "thread"
+	// MessageTypeReact and MessageTypeUnreact add and withdraw the sender's
+	// reaction, carried in Content, to the message named by ID.
+	MessageTypeReact   MessageType = "react"
+	MessageTypeUnreact MessageType = "unreact"
+	// MessageTypeFile is a message carrying an Attachment, with any caption
+	// in Content. Files are uploaded over HTTP, which announces them; clients
+	// cannot send this type.
+	MessageTypeFile MessageType = "file"
+	// MessageTypeMention is sent to a user named with @name in a message,
+	// on every connection they have, whichever rooms it is subscribed to. It
+	// carries the message, with the room it was sent in.
+	MessageTypeMention MessageType = "mention"
 )
 
 func (m MessageType) String() string {
@@ -19,11 +60,46 @@ func (m MessageType) String() string {
 }
 
 type WireMessage struct {
-	Type      MessageType `json:"type"`
-	ID        string      `json:"id"`
-	Author    string      `json:"author"`
-	Content   string      `json:"content"`
-	Timestamp time.Time   `json:"timestamp"`
+	Type MessageType `json:"type"`
+	// RoomID names the room a message belongs to. Multiplexed clients must set
+	// it on everything they send; the server sets it on everything it sends.
+	RoomID    string     `json:"room_id,omitempty"`
+	ID        string     `json:"id"`
+	Author    string     `json:"author"`
+	Content   string     `json:"content"`
+	Timestamp time.Time  `json:"timestamp"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	// ParentID is set on thread replies and thread requests.
+	ParentID string `json:"parent_id,omitempty"`
+	// ReplyCount and LastReplyAt summarise a message's thread in history.
+	ReplyCount  int        `json:"reply_count,omitempty"`
+	LastReplyAt *time.Time `json:"last_reply_at,omitempty"`
+	// Reactions are set on messages in history and thread pages.
+	Reactions []Reaction `json:"reactions,omitempty"`
+	// Attachment is only set on file messages.
+	Attachment *Attachment `json:"attachment,omitempty"`
+	// Messages and HasMore are only set on history pages.
+	Messages []WireMessage `json:"messages,omitempty"`
+	HasMore  bool          `json:"has_more,omitempty"`
+	// Event and UserID are only set on system messages, e.g. presence changes.
+	Event  string `json:"event,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Reaction is how many users reacted to a message with an emoji, and whether
+// the receiving user is one of them.
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	Mine  bool   `json:"mine,omitempty"`
+}
+
+// Attachment describes the file in a file message. Its contents are
+// downloaded from /attachments/{id}, with the message's ID.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
 }
 
 func (m *WireMessage) Marshal() ([]byte, error) {
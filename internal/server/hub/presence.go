@@ -0,0 +1,169 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Presence events carried in the Event field of MessageTypeSystem messages.
+// Clients may send PresenceAway and PresenceBack; the server emits all four.
+const (
+	PresenceJoin  = "join"
+	PresenceLeave = "leave"
+	PresenceAway  = "away"
+	PresenceBack  = "back"
+)
+
+// Statuses reported for room members.
+const (
+	StatusOnline  = "online"
+	StatusAway    = "away"
+	StatusOffline = "offline"
+)
+
+type localPresence struct {
+	conns  int
+	status string
+}
+
+// presenceTracker records which users are connected to a room. Users
+// connected to this node are counted per connection so that a second TUI
+// closing does not mark them offline; users on other nodes are tracked per
+// origin node from the presence events those nodes publish.
+type presenceTracker struct {
+	mu     sync.Mutex
+	local  map[uuid.UUID]*localPresence
+	remote map[uuid.UUID]map[string]string // user -> node -> status
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		local:  make(map[uuid.UUID]*localPresence),
+		remote: make(map[uuid.UUID]map[string]string),
+	}
+}
+
+// connect records a new local connection and reports whether it is the user's
+// first on this node.
+func (p *presenceTracker) connect(userID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lp, ok := p.local[userID]
+	if !ok {
+		p.local[userID] = &localPresence{conns: 1, status: StatusOnline}
+		return true
+	}
+	lp.conns++
+	return false
+}
+
+// disconnect drops a local connection and reports whether it was the user's
+// last on this node.
+func (p *presenceTracker) disconnect(userID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lp, ok := p.local[userID]
+	if !ok {
+		return false
+	}
+	lp.conns--
+	if lp.conns > 0 {
+		return false
+	}
+	delete(p.local, userID)
+	return true
+}
+
+// setStatus changes a locally connected user's status and reports whether it
+// changed.
+func (p *presenceTracker) setStatus(userID uuid.UUID, status string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lp, ok := p.local[userID]
+	if !ok || lp.status == status {
+		return false
+	}
+	lp.status = status
+	return true
+}
+
+// applyRemote folds a presence event published by another node into the
+// tracker.
+func (p *presenceTracker) applyRemote(origin string, userID uuid.UUID, event string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch event {
+	case PresenceLeave:
+		delete(p.remote[userID], origin)
+		if len(p.remote[userID]) == 0 {
+			delete(p.remote, userID)
+		}
+	case PresenceJoin, PresenceBack:
+		p.setRemote(origin, userID, StatusOnline)
+	case PresenceAway:
+		p.setRemote(origin, userID, StatusAway)
+	}
+}
+
+// replaceRemote swaps everything known about origin for a snapshot it
+// published in reply to a sync request.
+func (p *presenceTracker) replaceRemote(origin string, snapshot map[uuid.UUID]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for userID, nodes := range p.remote {
+		delete(nodes, origin)
+		if len(nodes) == 0 {
+			delete(p.remote, userID)
+		}
+	}
+	for userID, status := range snapshot {
+		p.setRemote(origin, userID, status)
+	}
+}
+
+func (p *presenceTracker) setRemote(origin string, userID uuid.UUID, status string) {
+	if p.remote[userID] == nil {
+		p.remote[userID] = make(map[string]string)
+	}
+	p.remote[userID][origin] = status
+}
+
+// localSnapshot returns the status of every user connected to this node.
+func (p *presenceTracker) localSnapshot() map[uuid.UUID]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[uuid.UUID]string, len(p.local))
+	for userID, lp := range p.local {
+		snapshot[userID] = lp.status
+	}
+	return snapshot
+}
+
+// snapshot returns the status of every user online anywhere. A user counts as
+// online if any of their connections, local or remote, is online.
+func (p *presenceTracker) snapshot() map[uuid.UUID]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[uuid.UUID]string, len(p.local)+len(p.remote))
+	for userID, nodes := range p.remote {
+		for _, status := range nodes {
+			if snapshot[userID] != StatusOnline {
+				snapshot[userID] = status
+			}
+		}
+	}
+	for userID, lp := range p.local {
+		if snapshot[userID] != StatusOnline {
+			snapshot[userID] = lp.status
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,150 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBroker fans room traffic out across server instances using Redis
+// pub/sub. Every topic shares a single subscription connection, which is told
+// to subscribe to a channel when the topic gets its first handler and to
+// unsubscribe when it loses its last.
+type RedisBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+
+	mu       sync.Mutex
+	handlers map[string]map[int]func([]byte)
+	// pending holds a channel per topic that is closed once Redis confirms
+	// the subscription.
+	pending map[string]chan struct{}
+	nextID  int
+}
+
+func NewRedisBroker(redisURL string) (*RedisBroker, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	b := &RedisBroker{
+		client:   client,
+		pubsub:   client.Subscribe(context.Background()),
+		handlers: make(map[string]map[int]func([]byte)),
+		pending:  make(map[string]chan struct{}),
+	}
+	go b.dispatch(b.pubsub.ChannelWithSubscriptions())
+
+	return b, nil
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	return b.client.Publish(ctx, topic, payload).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, topic string, handler func([]byte)) (func(), error) {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+
+	confirmed, waiting := b.pending[topic]
+	if b.handlers[topic] == nil {
+		// Commands are sent with the lock held so that they reach Redis in
+		// the order the topic's handlers come and go.
+		if err := b.pubsub.Subscribe(ctx, topic); err != nil {
+			b.mu.Unlock()
+			return nil, fmt.Errorf("subscribing to %s: %w", topic, err)
+		}
+		b.handlers[topic] = make(map[int]func([]byte))
+		confirmed, waiting = make(chan struct{}), true
+		b.pending[topic] = confirmed
+	}
+	b.handlers[topic][id] = handler
+	b.mu.Unlock()
+
+	unsubscribe := func() { b.unsubscribe(topic, id) }
+
+	// Wait for the subscription to be confirmed so that messages published
+	// immediately after Subscribe returns are not missed.
+	if waiting {
+		select {
+		case <-confirmed:
+		case <-ctx.Done():
+			unsubscribe()
+			return nil, fmt.Errorf("subscribing to %s: %w", topic, ctx.Err())
+		}
+	}
+
+	return unsubscribe, nil
+}
+
+func (b *RedisBroker) unsubscribe(topic string, id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	handlers := b.handlers[topic]
+	if _, ok := handlers[id]; !ok {
+		return
+	}
+	delete(handlers, id)
+	if len(handlers) > 0 {
+		return
+	}
+
+	delete(b.handlers, topic)
+	delete(b.pending, topic)
+	_ = b.pubsub.Unsubscribe(context.Background(), topic)
+}
+
+// dispatch hands each message to the handlers of its topic, and marks
+// subscriptions confirmed as Redis acknowledges them, until the broker is
+// closed. Handlers run one at a time on this goroutine, so a subscription is
+// only confirmed once the handlers of the messages before it return. Handlers
+// must therefore not subscribe, nor wait on a lock that may be held by a caller
+// of Subscribe.
+func (b *RedisBroker) dispatch(ch <-chan any) {
+	for received := range ch {
+		switch msg := received.(type) {
+		case *redis.Subscription:
+			if msg.Kind != "subscribe" {
+				continue
+			}
+			b.mu.Lock()
+			if confirmed, ok := b.pending[msg.Channel]; ok {
+				close(confirmed)
+				delete(b.pending, msg.Channel)
+			}
+			b.mu.Unlock()
+		case *redis.Message:
+			b.mu.Lock()
+			handlers := make([]func([]byte), 0, len(b.handlers[msg.Channel]))
+			for _, handler := range b.handlers[msg.Channel] {
+				handlers = append(handlers, handler)
+			}
+			b.mu.Unlock()
+
+			for _, handler := range handlers {
+				handler([]byte(msg.Payload))
+			}
+		}
+	}
+}
+
+func (b *RedisBroker) Close() error {
+	_ = b.pubsub.Close()
+	return b.client.Close()
+}
@@ -0,0 +1,244 @@
+package hub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis speaks enough of the Redis protocol for RedisBroker: PING,
+// PUBLISH, SUBSCRIBE and UNSUBSCRIBE. Confirming a subscription to a held
+// channel waits until the test releases it, as a busy server might.
+type fakeRedis struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	subs map[string]map[*fakeConn]bool
+	// held maps channels whose subscriptions are not confirmed yet to a
+	// channel closed to confirm them, and requested to one closed once a
+	// subscription has been asked for.
+	held      map[string]chan struct{}
+	requested map[string]chan struct{}
+}
+
+type fakeConn struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (c *fakeConn) write(reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.w.WriteString(reply)
+	_ = c.w.Flush()
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &fakeRedis{
+		ln:        ln,
+		subs:      make(map[string]map[*fakeConn]bool),
+		held:      make(map[string]chan struct{}),
+		requested: make(map[string]chan struct{}),
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *fakeRedis) url() string {
+	return "redis://" + s.ln.Addr().String()
+}
+
+// hold delays confirming subscriptions to channel until the returned function
+// is called, and returns a channel closed once one has been asked for.
+func (s *fakeRedis) hold(channel string) (requested <-chan struct{}, release func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	confirm := make(chan struct{})
+	s.held[channel] = confirm
+	s.requested[channel] = make(chan struct{})
+	return s.requested[channel], sync.OnceFunc(func() { close(confirm) })
+}
+
+func (s *fakeRedis) publish(channel, payload string) int {
+	s.mu.Lock()
+	var targets []*fakeConn
+	for conn := range s.subs[channel] {
+		targets = append(targets, conn)
+	}
+	s.mu.Unlock()
+
+	for _, conn := range targets {
+		conn.write(array("message", channel, payload))
+	}
+	return len(targets)
+}
+
+func (s *fakeRedis) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	conn := &fakeConn{w: bufio.NewWriter(nc)}
+	defer func() {
+		s.mu.Lock()
+		for _, conns := range s.subs {
+			delete(conns, conn)
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			conn.write("+PONG\r\n")
+		case "PUBLISH":
+			conn.write(fmt.Sprintf(":%d\r\n", s.publish(args[1], args[2])))
+		case "SUBSCRIBE":
+			for _, channel := range args[1:] {
+				s.subscribe(conn, channel)
+			}
+		case "UNSUBSCRIBE":
+			for _, channel := range args[1:] {
+				s.mu.Lock()
+				delete(s.subs[channel], conn)
+				s.mu.Unlock()
+				conn.write(array("unsubscribe", channel) + ":0\r\n")
+			}
+		default:
+			conn.write("-ERR unknown command '" + args[0] + "'\r\n")
+		}
+	}
+}
+
+func (s *fakeRedis) subscribe(conn *fakeConn, channel string) {
+	s.mu.Lock()
+	if s.subs[channel] == nil {
+		s.subs[channel] = make(map[*fakeConn]bool)
+	}
+	s.subs[channel][conn] = true
+	confirm, held := s.held[channel]
+	if requested, ok := s.requested[channel]; ok {
+		close(requested)
+		delete(s.requested, channel)
+	}
+	s.mu.Unlock()
+
+	reply := "*3\r\n" + bulk("subscribe") + bulk(channel) + ":1\r\n"
+	if !held {
+		conn.write(reply)
+		return
+	}
+	go func() {
+		<-confirm
+		conn.write(reply)
+	}()
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected argument %q", header)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func array(items ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(items))
+	for _, item := range items {
+		b.WriteString(bulk(item))
+	}
+	return b.String()
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func TestRedisBroker_DeliversToUsersWhileJoinWaitsForSubscription(t *testing.T) {
+	srv := newFakeRedis(t)
+	broker, err := NewRedisBroker(srv.url())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = broker.Close() })
+
+	h := NewHub(broker)
+	alice := NewClient(nil, uuid.New(), uuid.Nil, "alice")
+	require.NoError(t, h.AddClient(alice))
+
+	roomID := uuid.New()
+	requested, release := srv.hold(roomTopic(roomID))
+	defer release()
+
+	joined := make(chan error, 1)
+	go func() {
+		_, err := h.Join(roomID, NewClient(nil, uuid.New(), uuid.Nil, "bob"))
+		joined <- err
+	}()
+	select {
+	case <-requested:
+	case <-time.After(time.Second):
+		t.Fatal("the room was never subscribed to")
+	}
+
+	// A mention from another node arrives before the room's subscription is
+	// confirmed. Delivering it must not wait for the join.
+	payload := `{"type":"mention"}`
+	data, err := json.Marshal(envelope{Origin: "another-node", Payload: json.RawMessage(payload)})
+	require.NoError(t, err)
+	srv.publish(userTopic(alice.UserID), string(data))
+	assert.JSONEq(t, payload, string(receive(t, alice)))
+
+	release()
+	select {
+	case err := <-joined:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("join did not finish once the subscription was confirmed")
+	}
+}
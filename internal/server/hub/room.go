@@ -1,8 +1,11 @@
 package hub
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,6 +17,12 @@ type Room struct {
 	broadcastPool *BroadcastPool
 	poolThreshold int
 	workerCount   int
+	nodeID        string
+	broker        Broker
+	unsubscribe   func()
+	presence      *presenceTracker
+	// hub is the hub serving the room, which is told when the room empties.
+	hub *Hub
 }
 
 func NewRoom() *Room {
@@ -22,9 +31,12 @@ func NewRoom() *Room {
 		clients:       make(map[*Client]bool),
 		poolThreshold: 10,
 		workerCount:   10,
+		presence:      newPresenceTracker(),
 	}
 }
 
+// Add registers c with the room, announcing the user as joined if this is
+// their first connection to it.
 func (r *Room) Add(c *Client) {
 	r.mu.Lock()
 	r.clients[c] = true
@@ -34,6 +46,10 @@ func (r *Room) Add(c *Client) {
 	if clientCount >= r.poolThreshold && r.broadcastPool == nil {
 		r.activatePool()
 	}
+
+	if r.presence.connect(c.UserID) {
+		r.announce(c, PresenceJoin)
+	}
 }
 
 func (r *Room) activatePool() {
@@ -49,14 +65,156 @@ func (r *Room) activatePool() {
 	slog.Info("activated broadcast pool", "room_id", r.ID, "workers", r.workerCount)
 }
 
+// Remove unregisters c, announcing the user as gone once their last
+// connection to the room closes.
 func (r *Room) Remove(c *Client) {
 	r.mu.Lock()
 	delete(r.clients, c)
-	close(c.send)
 	r.mu.Unlock()
+
+	if r.presence.disconnect(c.UserID) {
+		r.announce(c, PresenceLeave)
+	}
+	if r.hub != nil {
+		r.hub.release(r)
+	}
+}
+
+func (r *Room) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.clients) == 0
+}
+
+// SetAway marks c's user as away or back and tells the room if that changed
+// their status.
+func (r *Room) SetAway(c *Client, away bool) {
+	status, event := StatusOnline, PresenceBack
+	if away {
+		status, event = StatusAway, PresenceAway
+	}
+	if r.presence.setStatus(c.UserID, status) {
+		r.announce(c, event)
+	}
+}
+
+// Presence returns the status of every user currently connected to the room
+// on any node. Users missing from the map are offline.
+func (r *Room) Presence() map[uuid.UUID]string {
+	return r.presence.snapshot()
+}
+
+func (r *Room) announce(c *Client, event string) {
+	wire := &WireMessage{
+		Type:      MessageTypeSystem,
+		RoomID:    r.ID.String(),
+		Event:     event,
+		UserID:    c.UserID.String(),
+		Author:    c.Username,
+		Timestamp: time.Now(),
+	}
+	wireBytes, err := wire.Marshal()
+	if err != nil {
+		slog.Error("failed to marshal presence event", "error", err, "room_id", r.ID)
+		return
+	}
+	r.Broadcast(wireBytes, c)
 }
 
+// Broadcast delivers msg to every local client except sender and publishes it
+// to the broker so that clients on other nodes receive it too.
 func (r *Room) Broadcast(msg []byte, sender *Client) {
+	r.deliver(msg, sender)
+	r.publish(msg)
+}
+
+func (r *Room) publish(msg []byte) {
+	if r.broker == nil {
+		return
+	}
+
+	r.publishEnvelope(envelope{Origin: r.nodeID, Payload: msg})
+}
+
+// handleRemote delivers a message published by another node to local clients.
+func (r *Room) handleRemote(data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		slog.Warn("dropping malformed broker envelope", "error", err, "room_id", r.ID)
+		return
+	}
+
+	if env.Origin == r.nodeID {
+		return
+	}
+
+	switch {
+	case env.Disconnect != "":
+		if userID, err := uuid.Parse(env.Disconnect); err == nil {
+			r.disconnectLocal(userID)
+		}
+		return
+	case env.SyncPresence:
+		r.publishPresence()
+		return
+	case env.Presence != nil:
+		r.presence.replaceRemote(env.Origin, env.Presence)
+		return
+	}
+
+	var wire WireMessage
+	if json.Unmarshal(env.Payload, &wire) == nil && wire.Type == MessageTypeSystem {
+		if userID, err := uuid.Parse(wire.UserID); err == nil {
+			r.presence.applyRemote(env.Origin, userID, wire.Event)
+		}
+	}
+
+	r.deliver(env.Payload, nil)
+}
+
+// requestPresence asks the other nodes serving the room for their local
+// presence.
+func (r *Room) requestPresence() {
+	r.publishEnvelope(envelope{Origin: r.nodeID, SyncPresence: true})
+}
+
+// publishPresence answers a sync request with the users connected locally.
+func (r *Room) publishPresence() {
+	snapshot := r.presence.localSnapshot()
+	if len(snapshot) == 0 {
+		return
+	}
+	r.publishEnvelope(envelope{Origin: r.nodeID, Presence: snapshot})
+}
+
+func (r *Room) publishEnvelope(env envelope) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		slog.Error("failed to marshal broker envelope", "error", err, "room_id", r.ID)
+		return
+	}
+	if err := r.broker.Publish(context.Background(), roomTopic(r.ID), data); err != nil {
+		slog.Error("failed to publish room envelope", "error", err, "room_id", r.ID)
+	}
+}
+
+// disconnectLocal drops every connection userID has to the room on this node.
+func (r *Room) disconnectLocal(userID uuid.UUID) {
+	r.mu.RLock()
+	var targets []*Client
+	for client := range r.clients {
+		if client.UserID == userID {
+			targets = append(targets, client)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, client := range targets {
+		client.kick(r, "removed from room")
+	}
+}
+
+func (r *Room) deliver(msg []byte, sender *Client) {
 	r.mu.RLock()
 	poolEnabled := r.broadcastPool != nil
 
@@ -92,6 +250,9 @@ func (r *Room) Broadcast(msg []byte, sender *Client) {
 
 // Shutdown gracefully shuts down the room and its worker pool
 func (r *Room) Shutdown() {
+	if r.unsubscribe != nil {
+		r.unsubscribe()
+	}
 	if r.broadcastPool != nil {
 		r.broadcastPool.Shutdown()
 	}
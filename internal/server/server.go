@@ -12,10 +12,10 @@ type ChatServer struct {
 	handler *api.Handler
 	srv     *http.Server
 	addr    string
-	db      *repository.PostgresDB
+	db      *repository.Store
 }
 
-func NewChatServer(h *api.Handler, addr string, db *repository.PostgresDB) *ChatServer {
+func NewChatServer(h *api.Handler, addr string, db *repository.Store) *ChatServer {
 	return &ChatServer{
 		handler: h,
 		addr:    addr,
@@ -5,6 +5,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/EwanGreer/chatatui/internal/repository"
 	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
@@ -37,6 +39,78 @@ func (_m *MockMessageStore) EXPECT() *MockMessageStore_Expecter {
 	return &MockMessageStore_Expecter{mock: &_m.Mock}
 }
 
+// AddReaction provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) AddReaction(messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(messageID, userID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddReaction")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(messageID, userID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(messageID, userID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(messageID, userID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_AddReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddReaction'
+type MockMessageStore_AddReaction_Call struct {
+	*mock.Call
+}
+
+// AddReaction is a helper method to define mock.On call
+//   - messageID uuid.UUID
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockMessageStore_Expecter) AddReaction(messageID interface{}, userID interface{}, emoji interface{}) *MockMessageStore_AddReaction_Call {
+	return &MockMessageStore_AddReaction_Call{Call: _e.mock.On("AddReaction", messageID, userID, emoji)}
+}
+
+func (_c *MockMessageStore_AddReaction_Call) Run(run func(messageID uuid.UUID, userID uuid.UUID, emoji string)) *MockMessageStore_AddReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_AddReaction_Call) Return(b bool, err error) *MockMessageStore_AddReaction_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMessageStore_AddReaction_Call) RunAndReturn(run func(messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockMessageStore_AddReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function for the type MockMessageStore
 func (_mock *MockMessageStore) Create(msg *repository.Message) error {
 	ret := _mock.Called(msg)
@@ -88,6 +162,119 @@ func (_c *MockMessageStore_Create_Call) RunAndReturn(run func(msg *repository.Me
 	return _c
 }
 
+// Delete provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) Delete(id uuid.UUID) error {
+	ret := _mock.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = returnFunc(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMessageStore_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockMessageStore_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *MockMessageStore_Expecter) Delete(id interface{}) *MockMessageStore_Delete_Call {
+	return &MockMessageStore_Delete_Call{Call: _e.mock.On("Delete", id)}
+}
+
+func (_c *MockMessageStore_Delete_Call) Run(run func(id uuid.UUID)) *MockMessageStore_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_Delete_Call) Return(err error) *MockMessageStore_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMessageStore_Delete_Call) RunAndReturn(run func(id uuid.UUID) error) *MockMessageStore_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) GetByID(id uuid.UUID) (*repository.Message, error) {
+	ret := _mock.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) (*repository.Message, error)); ok {
+		return returnFunc(id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) *repository.Message); ok {
+		r0 = returnFunc(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = returnFunc(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockMessageStore_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - id uuid.UUID
+func (_e *MockMessageStore_Expecter) GetByID(id interface{}) *MockMessageStore_GetByID_Call {
+	return &MockMessageStore_GetByID_Call{Call: _e.mock.On("GetByID", id)}
+}
+
+func (_c *MockMessageStore_GetByID_Call) Run(run func(id uuid.UUID)) *MockMessageStore_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_GetByID_Call) Return(message *repository.Message, err error) *MockMessageStore_GetByID_Call {
+	_c.Call.Return(message, err)
+	return _c
+}
+
+func (_c *MockMessageStore_GetByID_Call) RunAndReturn(run func(id uuid.UUID) (*repository.Message, error)) *MockMessageStore_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByRoom provides a mock function for the type MockMessageStore
 func (_mock *MockMessageStore) GetByRoom(roomID uuid.UUID, limit int, offset int) ([]repository.Message, error) {
 	ret := _mock.Called(roomID, limit, offset)
@@ -161,3 +348,614 @@ func (_c *MockMessageStore_GetByRoom_Call) RunAndReturn(run func(roomID uuid.UUI
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetByRoomAfter provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) GetByRoomAfter(roomID uuid.UUID, after uuid.UUID, limit int) ([]repository.Message, error) {
+	ret := _mock.Called(roomID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByRoomAfter")
+	}
+
+	var r0 []repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) ([]repository.Message, error)); ok {
+		return returnFunc(roomID, after, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) []repository.Message); ok {
+		r0 = returnFunc(roomID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(roomID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_GetByRoomAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByRoomAfter'
+type MockMessageStore_GetByRoomAfter_Call struct {
+	*mock.Call
+}
+
+// GetByRoomAfter is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - after uuid.UUID
+//   - limit int
+func (_e *MockMessageStore_Expecter) GetByRoomAfter(roomID interface{}, after interface{}, limit interface{}) *MockMessageStore_GetByRoomAfter_Call {
+	return &MockMessageStore_GetByRoomAfter_Call{Call: _e.mock.On("GetByRoomAfter", roomID, after, limit)}
+}
+
+func (_c *MockMessageStore_GetByRoomAfter_Call) Run(run func(roomID uuid.UUID, after uuid.UUID, limit int)) *MockMessageStore_GetByRoomAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_GetByRoomAfter_Call) Return(messages []repository.Message, err error) *MockMessageStore_GetByRoomAfter_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockMessageStore_GetByRoomAfter_Call) RunAndReturn(run func(roomID uuid.UUID, after uuid.UUID, limit int) ([]repository.Message, error)) *MockMessageStore_GetByRoomAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByRoomBefore provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) GetByRoomBefore(roomID uuid.UUID, before uuid.UUID, limit int) ([]repository.Message, error) {
+	ret := _mock.Called(roomID, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByRoomBefore")
+	}
+
+	var r0 []repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) ([]repository.Message, error)); ok {
+		return returnFunc(roomID, before, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) []repository.Message); ok {
+		r0 = returnFunc(roomID, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(roomID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_GetByRoomBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByRoomBefore'
+type MockMessageStore_GetByRoomBefore_Call struct {
+	*mock.Call
+}
+
+// GetByRoomBefore is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - before uuid.UUID
+//   - limit int
+func (_e *MockMessageStore_Expecter) GetByRoomBefore(roomID interface{}, before interface{}, limit interface{}) *MockMessageStore_GetByRoomBefore_Call {
+	return &MockMessageStore_GetByRoomBefore_Call{Call: _e.mock.On("GetByRoomBefore", roomID, before, limit)}
+}
+
+func (_c *MockMessageStore_GetByRoomBefore_Call) Run(run func(roomID uuid.UUID, before uuid.UUID, limit int)) *MockMessageStore_GetByRoomBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_GetByRoomBefore_Call) Return(messages []repository.Message, err error) *MockMessageStore_GetByRoomBefore_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockMessageStore_GetByRoomBefore_Call) RunAndReturn(run func(roomID uuid.UUID, before uuid.UUID, limit int) ([]repository.Message, error)) *MockMessageStore_GetByRoomBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetReplies provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) GetReplies(parentID uuid.UUID) ([]repository.Message, error) {
+	ret := _mock.Called(parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReplies")
+	}
+
+	var r0 []repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) ([]repository.Message, error)); ok {
+		return returnFunc(parentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) []repository.Message); ok {
+		r0 = returnFunc(parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = returnFunc(parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_GetReplies_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReplies'
+type MockMessageStore_GetReplies_Call struct {
+	*mock.Call
+}
+
+// GetReplies is a helper method to define mock.On call
+//   - parentID uuid.UUID
+func (_e *MockMessageStore_Expecter) GetReplies(parentID interface{}) *MockMessageStore_GetReplies_Call {
+	return &MockMessageStore_GetReplies_Call{Call: _e.mock.On("GetReplies", parentID)}
+}
+
+func (_c *MockMessageStore_GetReplies_Call) Run(run func(parentID uuid.UUID)) *MockMessageStore_GetReplies_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_GetReplies_Call) Return(messages []repository.Message, err error) *MockMessageStore_GetReplies_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockMessageStore_GetReplies_Call) RunAndReturn(run func(parentID uuid.UUID) ([]repository.Message, error)) *MockMessageStore_GetReplies_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MentionsOf provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) MentionsOf(userID uuid.UUID, before uuid.UUID, limit int) ([]repository.Message, error) {
+	ret := _mock.Called(userID, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MentionsOf")
+	}
+
+	var r0 []repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) ([]repository.Message, error)); ok {
+		return returnFunc(userID, before, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, int) []repository.Message); ok {
+		r0 = returnFunc(userID, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = returnFunc(userID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_MentionsOf_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MentionsOf'
+type MockMessageStore_MentionsOf_Call struct {
+	*mock.Call
+}
+
+// MentionsOf is a helper method to define mock.On call
+//   - userID uuid.UUID
+//   - before uuid.UUID
+//   - limit int
+func (_e *MockMessageStore_Expecter) MentionsOf(userID interface{}, before interface{}, limit interface{}) *MockMessageStore_MentionsOf_Call {
+	return &MockMessageStore_MentionsOf_Call{Call: _e.mock.On("MentionsOf", userID, before, limit)}
+}
+
+func (_c *MockMessageStore_MentionsOf_Call) Run(run func(userID uuid.UUID, before uuid.UUID, limit int)) *MockMessageStore_MentionsOf_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_MentionsOf_Call) Return(messages []repository.Message, err error) *MockMessageStore_MentionsOf_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockMessageStore_MentionsOf_Call) RunAndReturn(run func(userID uuid.UUID, before uuid.UUID, limit int) ([]repository.Message, error)) *MockMessageStore_MentionsOf_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReactionCounts provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) ReactionCounts(messageIDs []uuid.UUID, viewerID uuid.UUID) ([]repository.ReactionCount, error) {
+	ret := _mock.Called(messageIDs, viewerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReactionCounts")
+	}
+
+	var r0 []repository.ReactionCount
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID, uuid.UUID) ([]repository.ReactionCount, error)); ok {
+		return returnFunc(messageIDs, viewerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID, uuid.UUID) []repository.ReactionCount); ok {
+		r0 = returnFunc(messageIDs, viewerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.ReactionCount)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func([]uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(messageIDs, viewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_ReactionCounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReactionCounts'
+type MockMessageStore_ReactionCounts_Call struct {
+	*mock.Call
+}
+
+// ReactionCounts is a helper method to define mock.On call
+//   - messageIDs []uuid.UUID
+//   - viewerID uuid.UUID
+func (_e *MockMessageStore_Expecter) ReactionCounts(messageIDs interface{}, viewerID interface{}) *MockMessageStore_ReactionCounts_Call {
+	return &MockMessageStore_ReactionCounts_Call{Call: _e.mock.On("ReactionCounts", messageIDs, viewerID)}
+}
+
+func (_c *MockMessageStore_ReactionCounts_Call) Run(run func(messageIDs []uuid.UUID, viewerID uuid.UUID)) *MockMessageStore_ReactionCounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].([]uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_ReactionCounts_Call) Return(reactionCounts []repository.ReactionCount, err error) *MockMessageStore_ReactionCounts_Call {
+	_c.Call.Return(reactionCounts, err)
+	return _c
+}
+
+func (_c *MockMessageStore_ReactionCounts_Call) RunAndReturn(run func(messageIDs []uuid.UUID, viewerID uuid.UUID) ([]repository.ReactionCount, error)) *MockMessageStore_ReactionCounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveReaction provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) RemoveReaction(messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(messageID, userID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveReaction")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(messageID, userID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(messageID, userID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(messageID, userID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_RemoveReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveReaction'
+type MockMessageStore_RemoveReaction_Call struct {
+	*mock.Call
+}
+
+// RemoveReaction is a helper method to define mock.On call
+//   - messageID uuid.UUID
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockMessageStore_Expecter) RemoveReaction(messageID interface{}, userID interface{}, emoji interface{}) *MockMessageStore_RemoveReaction_Call {
+	return &MockMessageStore_RemoveReaction_Call{Call: _e.mock.On("RemoveReaction", messageID, userID, emoji)}
+}
+
+func (_c *MockMessageStore_RemoveReaction_Call) Run(run func(messageID uuid.UUID, userID uuid.UUID, emoji string)) *MockMessageStore_RemoveReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_RemoveReaction_Call) Return(b bool, err error) *MockMessageStore_RemoveReaction_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMessageStore_RemoveReaction_Call) RunAndReturn(run func(messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockMessageStore_RemoveReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) Search(params repository.MessageSearch) ([]repository.Message, error) {
+	ret := _mock.Called(params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []repository.Message
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(repository.MessageSearch) ([]repository.Message, error)); ok {
+		return returnFunc(params)
+	}
+	if returnFunc, ok := ret.Get(0).(func(repository.MessageSearch) []repository.Message); ok {
+		r0 = returnFunc(params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Message)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(repository.MessageSearch) error); ok {
+		r1 = returnFunc(params)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockMessageStore_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - params repository.MessageSearch
+func (_e *MockMessageStore_Expecter) Search(params interface{}) *MockMessageStore_Search_Call {
+	return &MockMessageStore_Search_Call{Call: _e.mock.On("Search", params)}
+}
+
+func (_c *MockMessageStore_Search_Call) Run(run func(params repository.MessageSearch)) *MockMessageStore_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 repository.MessageSearch
+		if args[0] != nil {
+			arg0 = args[0].(repository.MessageSearch)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_Search_Call) Return(messages []repository.Message, err error) *MockMessageStore_Search_Call {
+	_c.Call.Return(messages, err)
+	return _c
+}
+
+func (_c *MockMessageStore_Search_Call) RunAndReturn(run func(params repository.MessageSearch) ([]repository.Message, error)) *MockMessageStore_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ThreadSummaries provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) ThreadSummaries(parentIDs []uuid.UUID) ([]repository.ThreadSummary, error) {
+	ret := _mock.Called(parentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ThreadSummaries")
+	}
+
+	var r0 []repository.ThreadSummary
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID) ([]repository.ThreadSummary, error)); ok {
+		return returnFunc(parentIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID) []repository.ThreadSummary); ok {
+		r0 = returnFunc(parentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.ThreadSummary)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = returnFunc(parentIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMessageStore_ThreadSummaries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ThreadSummaries'
+type MockMessageStore_ThreadSummaries_Call struct {
+	*mock.Call
+}
+
+// ThreadSummaries is a helper method to define mock.On call
+//   - parentIDs []uuid.UUID
+func (_e *MockMessageStore_Expecter) ThreadSummaries(parentIDs interface{}) *MockMessageStore_ThreadSummaries_Call {
+	return &MockMessageStore_ThreadSummaries_Call{Call: _e.mock.On("ThreadSummaries", parentIDs)}
+}
+
+func (_c *MockMessageStore_ThreadSummaries_Call) Run(run func(parentIDs []uuid.UUID)) *MockMessageStore_ThreadSummaries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].([]uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_ThreadSummaries_Call) Return(threadSummarys []repository.ThreadSummary, err error) *MockMessageStore_ThreadSummaries_Call {
+	_c.Call.Return(threadSummarys, err)
+	return _c
+}
+
+func (_c *MockMessageStore_ThreadSummaries_Call) RunAndReturn(run func(parentIDs []uuid.UUID) ([]repository.ThreadSummary, error)) *MockMessageStore_ThreadSummaries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateContent provides a mock function for the type MockMessageStore
+func (_mock *MockMessageStore) UpdateContent(id uuid.UUID, content []byte, editedAt time.Time) error {
+	ret := _mock.Called(id, content, editedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateContent")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, []byte, time.Time) error); ok {
+		r0 = returnFunc(id, content, editedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMessageStore_UpdateContent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateContent'
+type MockMessageStore_UpdateContent_Call struct {
+	*mock.Call
+}
+
+// UpdateContent is a helper method to define mock.On call
+//   - id uuid.UUID
+//   - content []byte
+//   - editedAt time.Time
+func (_e *MockMessageStore_Expecter) UpdateContent(id interface{}, content interface{}, editedAt interface{}) *MockMessageStore_UpdateContent_Call {
+	return &MockMessageStore_UpdateContent_Call{Call: _e.mock.On("UpdateContent", id, content, editedAt)}
+}
+
+func (_c *MockMessageStore_UpdateContent_Call) Run(run func(id uuid.UUID, content []byte, editedAt time.Time)) *MockMessageStore_UpdateContent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 []byte
+		if args[1] != nil {
+			arg1 = args[1].([]byte)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMessageStore_UpdateContent_Call) Return(err error) *MockMessageStore_UpdateContent_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMessageStore_UpdateContent_Call) RunAndReturn(run func(id uuid.UUID, content []byte, editedAt time.Time) error) *MockMessageStore_UpdateContent_Call {
+	_c.Call.Return(run)
+	return _c
+}
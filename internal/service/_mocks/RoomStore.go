@@ -94,6 +94,130 @@ func (_c *MockRoomStore_AddMember_Call) RunAndReturn(run func(roomID uuid.UUID,
 	return _c
 }
 
+// CreateDM provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) CreateDM(userIDs []uuid.UUID) (*repository.Room, error) {
+	ret := _mock.Called(userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDM")
+	}
+
+	var r0 *repository.Room
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID) (*repository.Room, error)); ok {
+		return returnFunc(userIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func([]uuid.UUID) *repository.Room); ok {
+		r0 = returnFunc(userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Room)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = returnFunc(userIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoomStore_CreateDM_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDM'
+type MockRoomStore_CreateDM_Call struct {
+	*mock.Call
+}
+
+// CreateDM is a helper method to define mock.On call
+//   - userIDs []uuid.UUID
+func (_e *MockRoomStore_Expecter) CreateDM(userIDs interface{}) *MockRoomStore_CreateDM_Call {
+	return &MockRoomStore_CreateDM_Call{Call: _e.mock.On("CreateDM", userIDs)}
+}
+
+func (_c *MockRoomStore_CreateDM_Call) Run(run func(userIDs []uuid.UUID)) *MockRoomStore_CreateDM_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].([]uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_CreateDM_Call) Return(room *repository.Room, err error) *MockRoomStore_CreateDM_Call {
+	_c.Call.Return(room, err)
+	return _c
+}
+
+func (_c *MockRoomStore_CreateDM_Call) RunAndReturn(run func(userIDs []uuid.UUID) (*repository.Room, error)) *MockRoomStore_CreateDM_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByDMKey provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) GetByDMKey(key string) (*repository.Room, error) {
+	ret := _mock.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByDMKey")
+	}
+
+	var r0 *repository.Room
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*repository.Room, error)); ok {
+		return returnFunc(key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) *repository.Room); ok {
+		r0 = returnFunc(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Room)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoomStore_GetByDMKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByDMKey'
+type MockRoomStore_GetByDMKey_Call struct {
+	*mock.Call
+}
+
+// GetByDMKey is a helper method to define mock.On call
+//   - key string
+func (_e *MockRoomStore_Expecter) GetByDMKey(key interface{}) *MockRoomStore_GetByDMKey_Call {
+	return &MockRoomStore_GetByDMKey_Call{Call: _e.mock.On("GetByDMKey", key)}
+}
+
+func (_c *MockRoomStore_GetByDMKey_Call) Run(run func(key string)) *MockRoomStore_GetByDMKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_GetByDMKey_Call) Return(room *repository.Room, err error) *MockRoomStore_GetByDMKey_Call {
+	_c.Call.Return(room, err)
+	return _c
+}
+
+func (_c *MockRoomStore_GetByDMKey_Call) RunAndReturn(run func(key string) (*repository.Room, error)) *MockRoomStore_GetByDMKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByID provides a mock function for the type MockRoomStore
 func (_mock *MockRoomStore) GetByID(id uuid.UUID) (*repository.Room, error) {
 	ret := _mock.Called(id)
@@ -155,3 +279,561 @@ func (_c *MockRoomStore_GetByID_Call) RunAndReturn(run func(id uuid.UUID) (*repo
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetMember provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) GetMember(roomID uuid.UUID, userID uuid.UUID) (*repository.RoomMember, error) {
+	ret := _mock.Called(roomID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMember")
+	}
+
+	var r0 *repository.RoomMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*repository.RoomMember, error)); ok {
+		return returnFunc(roomID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *repository.RoomMember); ok {
+		r0 = returnFunc(roomID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.RoomMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(roomID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoomStore_GetMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMember'
+type MockRoomStore_GetMember_Call struct {
+	*mock.Call
+}
+
+// GetMember is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockRoomStore_Expecter) GetMember(roomID interface{}, userID interface{}) *MockRoomStore_GetMember_Call {
+	return &MockRoomStore_GetMember_Call{Call: _e.mock.On("GetMember", roomID, userID)}
+}
+
+func (_c *MockRoomStore_GetMember_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID)) *MockRoomStore_GetMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_GetMember_Call) Return(roomMember *repository.RoomMember, err error) *MockRoomStore_GetMember_Call {
+	_c.Call.Return(roomMember, err)
+	return _c
+}
+
+func (_c *MockRoomStore_GetMember_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID) (*repository.RoomMember, error)) *MockRoomStore_GetMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Import provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) Import(room *repository.Room, ownerID uuid.UUID, authors []repository.User, messages []repository.Message) error {
+	ret := _mock.Called(room, ownerID, authors, messages)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(*repository.Room, uuid.UUID, []repository.User, []repository.Message) error); ok {
+		r0 = returnFunc(room, ownerID, authors, messages)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_Import_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Import'
+type MockRoomStore_Import_Call struct {
+	*mock.Call
+}
+
+// Import is a helper method to define mock.On call
+//   - room *repository.Room
+//   - ownerID uuid.UUID
+//   - authors []repository.User
+//   - messages []repository.Message
+func (_e *MockRoomStore_Expecter) Import(room interface{}, ownerID interface{}, authors interface{}, messages interface{}) *MockRoomStore_Import_Call {
+	return &MockRoomStore_Import_Call{Call: _e.mock.On("Import", room, ownerID, authors, messages)}
+}
+
+func (_c *MockRoomStore_Import_Call) Run(run func(room *repository.Room, ownerID uuid.UUID, authors []repository.User, messages []repository.Message)) *MockRoomStore_Import_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *repository.Room
+		if args[0] != nil {
+			arg0 = args[0].(*repository.Room)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 []repository.User
+		if args[2] != nil {
+			arg2 = args[2].([]repository.User)
+		}
+		var arg3 []repository.Message
+		if args[3] != nil {
+			arg3 = args[3].([]repository.Message)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_Import_Call) Return(err error) *MockRoomStore_Import_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_Import_Call) RunAndReturn(run func(room *repository.Room, ownerID uuid.UUID, authors []repository.User, messages []repository.Message) error) *MockRoomStore_Import_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) ListMembers(roomID uuid.UUID) ([]repository.RoomMember, error) {
+	ret := _mock.Called(roomID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []repository.RoomMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) ([]repository.RoomMember, error)); ok {
+		return returnFunc(roomID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) []repository.RoomMember); ok {
+		r0 = returnFunc(roomID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.RoomMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = returnFunc(roomID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoomStore_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockRoomStore_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - roomID uuid.UUID
+func (_e *MockRoomStore_Expecter) ListMembers(roomID interface{}) *MockRoomStore_ListMembers_Call {
+	return &MockRoomStore_ListMembers_Call{Call: _e.mock.On("ListMembers", roomID)}
+}
+
+func (_c *MockRoomStore_ListMembers_Call) Run(run func(roomID uuid.UUID)) *MockRoomStore_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_ListMembers_Call) Return(roomMembers []repository.RoomMember, err error) *MockRoomStore_ListMembers_Call {
+	_c.Call.Return(roomMembers, err)
+	return _c
+}
+
+func (_c *MockRoomStore_ListMembers_Call) RunAndReturn(run func(roomID uuid.UUID) ([]repository.RoomMember, error)) *MockRoomStore_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkRead provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) MarkRead(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID) error {
+	ret := _mock.Called(roomID, userID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, userID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_MarkRead_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkRead'
+type MockRoomStore_MarkRead_Call struct {
+	*mock.Call
+}
+
+// MarkRead is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+//   - messageID uuid.UUID
+func (_e *MockRoomStore_Expecter) MarkRead(roomID interface{}, userID interface{}, messageID interface{}) *MockRoomStore_MarkRead_Call {
+	return &MockRoomStore_MarkRead_Call{Call: _e.mock.On("MarkRead", roomID, userID, messageID)}
+}
+
+func (_c *MockRoomStore_MarkRead_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID)) *MockRoomStore_MarkRead_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_MarkRead_Call) Return(err error) *MockRoomStore_MarkRead_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_MarkRead_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID) error) *MockRoomStore_MarkRead_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) RemoveMember(roomID uuid.UUID, userID uuid.UUID) error {
+	ret := _mock.Called(roomID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(roomID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockRoomStore_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockRoomStore_Expecter) RemoveMember(roomID interface{}, userID interface{}) *MockRoomStore_RemoveMember_Call {
+	return &MockRoomStore_RemoveMember_Call{Call: _e.mock.On("RemoveMember", roomID, userID)}
+}
+
+func (_c *MockRoomStore_RemoveMember_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID)) *MockRoomStore_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_RemoveMember_Call) Return(err error) *MockRoomStore_RemoveMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_RemoveMember_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID) error) *MockRoomStore_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberRole provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) SetMemberRole(roomID uuid.UUID, userID uuid.UUID, role string) error {
+	ret := _mock.Called(roomID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = returnFunc(roomID, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockRoomStore_SetMemberRole_Call struct {
+	*mock.Call
+}
+
+// SetMemberRole is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - userID uuid.UUID
+//   - role string
+func (_e *MockRoomStore_Expecter) SetMemberRole(roomID interface{}, userID interface{}, role interface{}) *MockRoomStore_SetMemberRole_Call {
+	return &MockRoomStore_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", roomID, userID, role)}
+}
+
+func (_c *MockRoomStore_SetMemberRole_Call) Run(run func(roomID uuid.UUID, userID uuid.UUID, role string)) *MockRoomStore_SetMemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_SetMemberRole_Call) Return(err error) *MockRoomStore_SetMemberRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_SetMemberRole_Call) RunAndReturn(run func(roomID uuid.UUID, userID uuid.UUID, role string) error) *MockRoomStore_SetMemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRetention provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) SetRetention(roomID uuid.UUID, retention repository.Retention) error {
+	ret := _mock.Called(roomID, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRetention")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, repository.Retention) error); ok {
+		r0 = returnFunc(roomID, retention)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_SetRetention_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRetention'
+type MockRoomStore_SetRetention_Call struct {
+	*mock.Call
+}
+
+// SetRetention is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - retention repository.Retention
+func (_e *MockRoomStore_Expecter) SetRetention(roomID interface{}, retention interface{}) *MockRoomStore_SetRetention_Call {
+	return &MockRoomStore_SetRetention_Call{Call: _e.mock.On("SetRetention", roomID, retention)}
+}
+
+func (_c *MockRoomStore_SetRetention_Call) Run(run func(roomID uuid.UUID, retention repository.Retention)) *MockRoomStore_SetRetention_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 repository.Retention
+		if args[1] != nil {
+			arg1 = args[1].(repository.Retention)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_SetRetention_Call) Return(err error) *MockRoomStore_SetRetention_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_SetRetention_Call) RunAndReturn(run func(roomID uuid.UUID, retention repository.Retention) error) *MockRoomStore_SetRetention_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTopic provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) SetTopic(roomID uuid.UUID, topic string) error {
+	ret := _mock.Called(roomID, topic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTopic")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = returnFunc(roomID, topic)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoomStore_SetTopic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTopic'
+type MockRoomStore_SetTopic_Call struct {
+	*mock.Call
+}
+
+// SetTopic is a helper method to define mock.On call
+//   - roomID uuid.UUID
+//   - topic string
+func (_e *MockRoomStore_Expecter) SetTopic(roomID interface{}, topic interface{}) *MockRoomStore_SetTopic_Call {
+	return &MockRoomStore_SetTopic_Call{Call: _e.mock.On("SetTopic", roomID, topic)}
+}
+
+func (_c *MockRoomStore_SetTopic_Call) Run(run func(roomID uuid.UUID, topic string)) *MockRoomStore_SetTopic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_SetTopic_Call) Return(err error) *MockRoomStore_SetTopic_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoomStore_SetTopic_Call) RunAndReturn(run func(roomID uuid.UUID, topic string) error) *MockRoomStore_SetTopic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnreadCounts provides a mock function for the type MockRoomStore
+func (_mock *MockRoomStore) UnreadCounts(userID uuid.UUID) ([]repository.UnreadCount, error) {
+	ret := _mock.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnreadCounts")
+	}
+
+	var r0 []repository.UnreadCount
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) ([]repository.UnreadCount, error)); ok {
+		return returnFunc(userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uuid.UUID) []repository.UnreadCount); ok {
+		r0 = returnFunc(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.UnreadCount)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = returnFunc(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoomStore_UnreadCounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnreadCounts'
+type MockRoomStore_UnreadCounts_Call struct {
+	*mock.Call
+}
+
+// UnreadCounts is a helper method to define mock.On call
+//   - userID uuid.UUID
+func (_e *MockRoomStore_Expecter) UnreadCounts(userID interface{}) *MockRoomStore_UnreadCounts_Call {
+	return &MockRoomStore_UnreadCounts_Call{Call: _e.mock.On("UnreadCounts", userID)}
+}
+
+func (_c *MockRoomStore_UnreadCounts_Call) Run(run func(userID uuid.UUID)) *MockRoomStore_UnreadCounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 uuid.UUID
+		if args[0] != nil {
+			arg0 = args[0].(uuid.UUID)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoomStore_UnreadCounts_Call) Return(unreadCounts []repository.UnreadCount, err error) *MockRoomStore_UnreadCounts_Call {
+	_c.Call.Return(unreadCounts, err)
+	return _c
+}
+
+func (_c *MockRoomStore_UnreadCounts_Call) RunAndReturn(run func(userID uuid.UUID) ([]repository.UnreadCount, error)) *MockRoomStore_UnreadCounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -1,27 +1,106 @@
 package service
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/limits"
 	"github.com/EwanGreer/chatatui/internal/repository"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrNotMessageAuthor = errors.New("message was sent by another user")
+	ErrNotRoomMember    = errors.New("not a member of this room")
+	ErrForbidden        = errors.New("insufficient room permissions")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrInvalidDM        = errors.New("invalid direct message participants")
+	ErrInvalidParent    = errors.New("cannot reply to a thread reply")
+	ErrInvalidReaction  = errors.New("invalid reaction")
+	ErrNotEncrypted     = errors.New("encrypted rooms only accept end-to-end encrypted messages")
+	ErrNotAttachment    = errors.New("message has no attachment")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrNameTaken        = errors.New("name is already taken")
+	ErrInvalidImport    = errors.New("invalid import")
+	ErrAlreadyImported  = errors.New("messages have already been imported")
+	ErrInvalidRetention = errors.New("invalid retention")
+)
+
+// maxDMParticipants caps group direct messages, including the caller.
+const maxDMParticipants = 8
+
 type ChatService struct {
 	rooms    RoomStore
 	messages MessageStore
+	listener MessageListener
 }
 
 func NewChatService(rooms RoomStore, messages MessageStore) *ChatService {
 	return &ChatService{rooms: rooms, messages: messages}
 }
 
+// SetMessageListener has l told about every message stored from now on.
+func (s *ChatService) SetMessageListener(l MessageListener) {
+	s.listener = l
+}
+
 func (s *ChatService) GetRoom(id uuid.UUID) (*RoomInfo, error) {
 	room, err := s.rooms.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	return &RoomInfo{ID: room.ID, Name: room.Name}, nil
+	return toRoomInfo(room), nil
+}
+
+// OpenDM returns the direct message room between actorID and participantIDs,
+// creating it if this is their first conversation. It reports whether the room
+// was created. Participants who left an existing room are added back.
+func (s *ChatService) OpenDM(actorID uuid.UUID, participantIDs []uuid.UUID) (*RoomInfo, bool, error) {
+	userIDs := []uuid.UUID{actorID}
+	for _, id := range participantIDs {
+		if !slices.Contains(userIDs, id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) < 2 || len(userIDs) > maxDMParticipants {
+		return nil, false, ErrInvalidDM
+	}
+
+	room, err := s.rooms.GetByDMKey(repository.DMKey(userIDs))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		room, err = s.rooms.CreateDM(userIDs)
+		if err == nil {
+			return toRoomInfo(room), true, nil
+		}
+		// Another request may have created the room since the lookup.
+		existing, getErr := s.rooms.GetByDMKey(repository.DMKey(userIDs))
+		if getErr != nil {
+			return nil, false, err
+		}
+		room, err = existing, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, userID := range userIDs {
+		if err := s.rooms.AddMember(room.ID, userID); err != nil {
+			return nil, false, err
+		}
+	}
+	return toRoomInfo(room), false, nil
+}
+
+func toRoomInfo(room *repository.Room) *RoomInfo {
+	return &RoomInfo{ID: room.ID, Name: room.Name, Topic: room.Topic, OwnerID: room.OwnerID, Visibility: room.Visibility, Kind: room.Kind, Encrypted: room.Encrypted, Retention: room.Retention}
 }
 
 func (s *ChatService) AddRoomMember(roomID, userID uuid.UUID) error {
@@ -36,24 +115,797 @@ func (s *ChatService) GetMessageHistory(roomID uuid.UUID, limit, offset int) ([]
 
 	infos := make([]MessageInfo, len(messages))
 	for i, m := range messages {
-		infos[i] = MessageInfo{
-			ID:        m.ID,
-			Author:    m.Sender.Name,
-			Content:   string(m.Content),
-			CreatedAt: m.CreatedAt,
+		infos[i] = toMessageInfo(m)
+	}
+	return infos, nil
+}
+
+// GetMessagesBefore returns the page of messages immediately older than
+// before, or the newest page when before is uuid.Nil. Reactions are marked
+// with whether viewerID made them.
+func (s *ChatService) GetMessagesBefore(roomID, viewerID, before uuid.UUID, limit int) (MessagePage, error) {
+	// Fetch one extra row to learn whether another page exists.
+	messages, err := s.messages.GetByRoomBefore(roomID, before, limit+1)
+	if err != nil {
+		return MessagePage{}, err
+	}
+
+	page := MessagePage{HasMore: len(messages) > limit}
+	if page.HasMore {
+		messages = messages[:limit]
+	}
+
+	page.Messages = make([]MessageInfo, len(messages))
+	for i, m := range messages {
+		page.Messages[len(messages)-1-i] = toMessageInfo(m)
+	}
+	if err := s.summariseThreads(page.Messages); err != nil {
+		return MessagePage{}, err
+	}
+	if err := s.attachReactions(page.Messages, viewerID); err != nil {
+		return MessagePage{}, err
+	}
+	return page, nil
+}
+
+// attachReactions fills in the reactions to each message.
+func (s *ChatService) attachReactions(infos []MessageInfo, viewerID uuid.UUID) error {
+	if len(infos) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(infos))
+	for i, info := range infos {
+		ids[i] = info.ID
+	}
+	counts, err := s.messages.ReactionCounts(ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for _, count := range counts {
+		for i := range infos {
+			if infos[i].ID == count.MessageID {
+				infos[i].Reactions = append(infos[i].Reactions, ReactionInfo{Emoji: count.Emoji, Count: count.Count, Mine: count.Mine})
+			}
+		}
+	}
+	return nil
+}
+
+// summariseThreads fills in the thread summary of each message with replies.
+func (s *ChatService) summariseThreads(infos []MessageInfo) error {
+	if len(infos) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(infos))
+	for i, info := range infos {
+		ids[i] = info.ID
+	}
+	summaries, err := s.messages.ThreadSummaries(ids)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		for i := range infos {
+			if infos[i].ID == summary.ParentID {
+				infos[i].ReplyCount = summary.ReplyCount
+				infos[i].LastReplyAt = &summary.LastReplyAt
+			}
+		}
+	}
+	return nil
+}
+
+// GetThread returns the replies to parentID, oldest first. The parent must be
+// a top-level message in roomID.
+func (s *ChatService) GetThread(roomID, viewerID, parentID uuid.UUID) ([]MessageInfo, error) {
+	if _, err := s.threadParent(roomID, parentID); err != nil {
+		return nil, err
+	}
+
+	replies, err := s.messages.GetReplies(parentID)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]MessageInfo, len(replies))
+	for i, m := range replies {
+		infos[i] = toMessageInfo(m)
+	}
+	if err := s.attachReactions(infos, viewerID); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// threadParent loads the message a thread hangs off. Messages in other rooms
+// are reported as not found, and replies cannot start threads of their own.
+func (s *ChatService) threadParent(roomID, parentID uuid.UUID) (*repository.Message, error) {
+	parent, err := s.messages.GetByID(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.RoomID != roomID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if parent.ParentID != nil {
+		return nil, ErrInvalidParent
+	}
+	return parent, nil
+}
+
+// SearchMessages returns the messages matching query in rooms actorID is a
+// member of, newest first. Searching a single room requires membership of it.
+func (s *ChatService) SearchMessages(actorID uuid.UUID, query SearchQuery) ([]MessageInfo, error) {
+	if query.RoomID != uuid.Nil {
+		if _, err := s.member(query.RoomID, actorID); err != nil {
+			return nil, err
 		}
 	}
+
+	messages, err := s.messages.Search(repository.MessageSearch{
+		Query:  query.Text,
+		UserID: actorID,
+		RoomID: query.RoomID,
+		Author: query.Author,
+		Before: query.Before,
+		After:  query.After,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]MessageInfo, len(messages))
+	for i, m := range messages {
+		infos[i] = toMessageInfo(m)
+	}
 	return infos, nil
 }
 
-func (s *ChatService) PersistMessage(content []byte, senderID, roomID uuid.UUID) (uuid.UUID, time.Time, error) {
+// ExportMessages returns up to limit of the room's messages newer than after,
+// or the oldest when after is uuid.Nil, for viewerID to export. Thread replies
+// are included, and nothing is summarised. Public rooms can be exported by
+// anyone, as their history can be read; others only by members.
+func (s *ChatService) ExportMessages(roomID, viewerID, after uuid.UUID, limit int) ([]MessageInfo, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !isPublic(room) {
+		if _, err := s.member(roomID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := s.messages.GetByRoomAfter(roomID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]MessageInfo, len(messages))
+	for i, m := range messages {
+		infos[i] = toMessageInfo(m)
+	}
+	return infos, nil
+}
+
+// ImportRoom creates a room owned by actorID holding messages exported from
+// another, perhaps on another server, keeping their IDs, timestamps and
+// threads. Messages must be in the order they were sent. Those sent by the
+// actor remain theirs; other authors become placeholder users who cannot sign
+// in, so an import cannot put words in the mouths of real users. Files keep
+// their description but not their contents.
+func (s *ChatService) ImportRoom(actorID uuid.UUID, info RoomInfo, messages []MessageInfo) (*RoomInfo, error) {
+	room := &repository.Room{
+		Name:       info.Name,
+		Topic:      info.Topic,
+		Visibility: info.Visibility,
+		Kind:       repository.RoomKindRoom,
+		Encrypted:  info.Encrypted,
+	}
+	// Direct messages are imported as private rooms, since their
+	// participants may not be here to make up the DM.
+	if info.Kind == repository.RoomKindDM || room.Visibility == "" {
+		room.Visibility = repository.RoomVisibilityPrivate
+	}
+	switch room.Visibility {
+	case repository.RoomVisibilityPublic, repository.RoomVisibilityInviteOnly, repository.RoomVisibilityPrivate:
+	default:
+		return nil, fmt.Errorf("%w: unknown visibility %q", ErrInvalidImport, info.Visibility)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(messages))
+	authors := make(map[uuid.UUID]string)
+	var authorIDs []uuid.UUID
+	stored := make([]repository.Message, len(messages))
+	for i, m := range messages {
+		if err := checkImported(m, seen); err != nil {
+			return nil, err
+		}
+		seen[m.ID] = m.ParentID == nil
+		if m.AuthorID != actorID {
+			if _, ok := authors[m.AuthorID]; !ok {
+				authorIDs = append(authorIDs, m.AuthorID)
+			}
+			authors[m.AuthorID] = m.Author
+		}
+
+		updatedAt := m.CreatedAt
+		if m.EditedAt != nil {
+			updatedAt = *m.EditedAt
+		}
+		stored[i] = repository.Message{
+			BaseModel: repository.BaseModel{ID: m.ID, CreatedAt: m.CreatedAt, UpdatedAt: updatedAt},
+			Content:   []byte(m.Content),
+			Kind:      m.Kind,
+			SenderID:  m.AuthorID,
+			ParentID:  m.ParentID,
+			EditedAt:  m.EditedAt,
+		}
+		if m.Attachment != nil {
+			stored[i].Attachment = repository.Attachment{
+				Name:        m.Attachment.Name,
+				ContentType: m.Attachment.ContentType,
+				Size:        m.Attachment.Size,
+			}
+		}
+	}
+
+	users := make([]repository.User, len(authorIDs))
+	for i, id := range authorIDs {
+		users[i] = repository.User{BaseModel: repository.BaseModel{ID: id}, Name: authors[id]}
+	}
+	if err := s.rooms.Import(room, actorID, users, stored); err != nil {
+		if errors.Is(err, repository.ErrMessagesExist) {
+			return nil, ErrAlreadyImported
+		}
+		return nil, err
+	}
+	return toRoomInfo(room), nil
+}
+
+// checkImported reports ErrInvalidImport unless m can be imported after the
+// messages in seen, which maps each message to whether it can start a thread.
+// Messages are held to the length limits of ones sent live, with end-to-end
+// encrypted envelopes allowed the longer limit.
+func checkImported(m MessageInfo, seen map[uuid.UUID]bool) error {
+	if m.ID == uuid.Nil || m.AuthorID == uuid.Nil || m.Author == "" || m.CreatedAt.IsZero() {
+		return fmt.Errorf("%w: messages need an id, author, author id and time", ErrInvalidImport)
+	}
+	limit := limits.MaxMessageLength
+	if e2e.IsEnvelope([]byte(m.Content)) {
+		limit = limits.MaxEncryptedMessageLength
+	}
+	if len(m.Content) > limit {
+		return fmt.Errorf("%w: message %s is longer than %d characters", ErrInvalidImport, m.ID, limit)
+	}
+	if _, dup := seen[m.ID]; dup {
+		return fmt.Errorf("%w: message %s appears twice", ErrInvalidImport, m.ID)
+	}
+	switch m.Kind {
+	case repository.MessageKindChat, repository.MessageKindAction, repository.MessageKindFile:
+	default:
+		return fmt.Errorf("%w: message %s has unknown kind %q", ErrInvalidImport, m.ID, m.Kind)
+	}
+	if m.ParentID != nil && !seen[*m.ParentID] {
+		return fmt.Errorf("%w: message %s replies to a message that is not an earlier thread parent", ErrInvalidImport, m.ID)
+	}
+	return nil
+}
+
+func toMessageInfo(m repository.Message) MessageInfo {
+	info := MessageInfo{
+		ID:        m.ID,
+		RoomID:    m.RoomID,
+		Author:    m.Sender.Name,
+		Content:   string(m.Content),
+		Kind:      m.Kind,
+		CreatedAt: m.CreatedAt,
+		EditedAt:  m.EditedAt,
+		AuthorID:  m.SenderID,
+		ParentID:  m.ParentID,
+	}
+	// Imported files keep their description but not their contents, so
+	// have no key.
+	if m.Kind == repository.MessageKindFile {
+		info.Attachment = &AttachmentInfo{
+			Key:         m.Attachment.Key,
+			Name:        m.Attachment.Name,
+			ContentType: m.Attachment.ContentType,
+			Size:        m.Attachment.Size,
+		}
+	}
+	return info
+}
+
+// PersistMessage stores a message of the given kind, either
+// repository.MessageKindChat or repository.MessageKindAction, and tells the
+// message listener about it. It returns the members the message mentions, who
+// should be told about it.
+func (s *ChatService) PersistMessage(content []byte, kind string, senderID, roomID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error) {
+	if err := s.checkEncryption(roomID, content); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+
 	msg := &repository.Message{
 		Content:  content,
+		Kind:     kind,
+		SenderID: senderID,
+		RoomID:   roomID,
+	}
+	if err := s.addMentions(msg); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+	if err := s.messages.Create(msg); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+	s.messageCreated(msg)
+	return msg.ID, msg.CreatedAt, mentionedUsers(msg), nil
+}
+
+// PersistReply stores a reply to the thread started by parentID. Like
+// PersistMessage, it returns the members the reply mentions.
+func (s *ChatService) PersistReply(content []byte, senderID, roomID, parentID uuid.UUID) (uuid.UUID, time.Time, []uuid.UUID, error) {
+	if _, err := s.threadParent(roomID, parentID); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+	if err := s.checkEncryption(roomID, content); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+
+	msg := &repository.Message{
+		Content:  content,
+		Kind:     repository.MessageKindChat,
+		SenderID: senderID,
+		RoomID:   roomID,
+		ParentID: &parentID,
+	}
+	if err := s.addMentions(msg); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+	if err := s.messages.Create(msg); err != nil {
+		return uuid.Nil, time.Time{}, nil, err
+	}
+	s.messageCreated(msg)
+	return msg.ID, msg.CreatedAt, mentionedUsers(msg), nil
+}
+
+func (s *ChatService) messageCreated(msg *repository.Message) {
+	if s.listener != nil {
+		s.listener.MessageCreated(msg.RoomID, msg.ID)
+	}
+}
+
+// addMentions attaches a mention of every member of the room that msg names
+// with @name, other than its sender. End-to-end encrypted messages are opaque
+// to the server, so they mention no one.
+func (s *ChatService) addMentions(msg *repository.Message) error {
+	if !bytes.ContainsRune(msg.Content, '@') || e2e.IsEnvelope(msg.Content) {
+		return nil
+	}
+	members, err := s.rooms.ListMembers(msg.RoomID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.UserID != msg.SenderID && mentions(string(msg.Content), m.User.Name) {
+			msg.Mentions = append(msg.Mentions, repository.Mention{UserID: m.UserID})
+		}
+	}
+	return nil
+}
+
+func mentionedUsers(msg *repository.Message) []uuid.UUID {
+	var userIDs []uuid.UUID
+	for _, m := range msg.Mentions {
+		userIDs = append(userIDs, m.UserID)
+	}
+	return userIDs
+}
+
+// mentions reports whether text contains @name, ignoring case, as a word of
+// its own: not part of an email address, and not the start of a longer name.
+func mentions(text, name string) bool {
+	if name == "" {
+		return false
+	}
+	lower, target := strings.ToLower(text), "@"+strings.ToLower(name)
+	for i := 0; ; {
+		j := strings.Index(lower[i:], target)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(target)
+		before, _ := utf8.DecodeLastRuneInString(lower[:start])
+		after, _ := utf8.DecodeRuneInString(lower[end:])
+		if !isNameRune(before) && !isNameRune(after) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Mentions returns up to limit messages mentioning userID that are older than
+// before, newest first, or the newest when before is uuid.Nil. Mentions in
+// rooms the user has left are not included.
+func (s *ChatService) Mentions(userID, before uuid.UUID, limit int) ([]MessageInfo, error) {
+	messages, err := s.messages.MentionsOf(userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]MessageInfo, len(messages))
+	for i, m := range messages {
+		infos[i] = toMessageInfo(m)
+	}
+	return infos, nil
+}
+
+// PersistAttachment records a file, already saved in the blob store, as a
+// message from senderID with an optional caption. Only members may post files,
+// and encrypted rooms take none, since the server would see their contents.
+func (s *ChatService) PersistAttachment(senderID, roomID uuid.UUID, caption []byte, att AttachmentInfo) (*MessageInfo, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.member(roomID, senderID); err != nil {
+		return nil, err
+	}
+	if room.Encrypted {
+		return nil, ErrNotEncrypted
+	}
+
+	msg := &repository.Message{
+		Content:  caption,
+		Kind:     repository.MessageKindFile,
 		SenderID: senderID,
 		RoomID:   roomID,
+		Attachment: repository.Attachment{
+			Key:         att.Key,
+			Name:        att.Name,
+			ContentType: att.ContentType,
+			Size:        att.Size,
+		},
 	}
 	if err := s.messages.Create(msg); err != nil {
-		return uuid.Nil, time.Time{}, err
+		return nil, err
+	}
+	s.messageCreated(msg)
+	info := toMessageInfo(*msg)
+	return &info, nil
+}
+
+// GetAttachment returns the file message id for viewerID to download. Files in
+// public rooms are visible to anyone, as their history is; others only to
+// members.
+func (s *ChatService) GetAttachment(id, viewerID uuid.UUID) (*MessageInfo, error) {
+	msg, err := s.messages.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Attachment.Key == "" {
+		return nil, ErrNotAttachment
+	}
+	if !isPublic(&msg.Room) {
+		if _, err := s.member(msg.RoomID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	info := toMessageInfo(*msg)
+	return &info, nil
+}
+
+func (s *ChatService) EditMessage(id, senderID, roomID uuid.UUID, content []byte) (time.Time, error) {
+	if _, err := s.authoredMessage(id, senderID, roomID); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.checkEncryption(roomID, content); err != nil {
+		return time.Time{}, err
+	}
+
+	editedAt := time.Now()
+	if err := s.messages.UpdateContent(id, content, editedAt); err != nil {
+		return time.Time{}, err
+	}
+	return editedAt, nil
+}
+
+// checkEncryption refuses plaintext in encrypted rooms, so that a client that
+// does not know the room is encrypted cannot leak a message to the server.
+func (s *ChatService) checkEncryption(roomID uuid.UUID, content []byte) error {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return err
+	}
+	if room.Encrypted && !e2e.IsEnvelope(content) {
+		return ErrNotEncrypted
+	}
+	return nil
+}
+
+func (s *ChatService) DeleteMessage(id, senderID, roomID uuid.UUID) error {
+	if _, err := s.authoredMessage(id, senderID, roomID); err != nil {
+		return err
+	}
+	return s.messages.Delete(id)
+}
+
+// React adds userID's emoji reaction to a message in roomID. It reports
+// whether the reaction is new, so that repeats are not announced.
+func (s *ChatService) React(messageID, userID, roomID uuid.UUID, emoji string) (bool, error) {
+	if err := s.reactable(messageID, roomID, emoji); err != nil {
+		return false, err
+	}
+	return s.messages.AddReaction(messageID, userID, emoji)
+}
+
+// Unreact withdraws userID's emoji reaction. It reports whether there was one.
+func (s *ChatService) Unreact(messageID, userID, roomID uuid.UUID, emoji string) (bool, error) {
+	if err := s.reactable(messageID, roomID, emoji); err != nil {
+		return false, err
+	}
+	return s.messages.RemoveReaction(messageID, userID, emoji)
+}
+
+// reactable checks emoji is a plausible reaction and the message is in roomID.
+// Messages in other rooms are reported as not found.
+func (s *ChatService) reactable(messageID, roomID uuid.UUID, emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > limits.MaxReactionLength || strings.ContainsFunc(emoji, unicode.IsSpace) {
+		return ErrInvalidReaction
+	}
+	msg, err := s.messages.GetByID(messageID)
+	if err != nil {
+		return err
+	}
+	if msg.RoomID != roomID {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// authoredMessage loads a message and checks it belongs to roomID and was sent
+// by senderID. Messages in other rooms are reported as not found.
+func (s *ChatService) authoredMessage(id, senderID, roomID uuid.UUID) (*repository.Message, error) {
+	msg, err := s.messages.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.RoomID != roomID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if msg.SenderID != senderID {
+		return nil, ErrNotMessageAuthor
+	}
+	return msg, nil
+}
+
+// MarkRead records that userID has read the room up to and including
+// messageID.
+func (s *ChatService) MarkRead(roomID, userID, messageID uuid.UUID) error {
+	msg, err := s.messages.GetByID(messageID)
+	if err != nil {
+		return err
+	}
+	if msg.RoomID != roomID {
+		return gorm.ErrRecordNotFound
+	}
+	return s.rooms.MarkRead(roomID, userID, messageID)
+}
+
+// UnreadCounts returns the number of unread messages in each of userID's
+// rooms, keyed by room ID.
+func (s *ChatService) UnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	counts, err := s.rooms.UnreadCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make(map[uuid.UUID]int, len(counts))
+	for _, c := range counts {
+		unread[c.RoomID] = c.Count
+	}
+	return unread, nil
+}
+
+// JoinRoom adds userID to a public room. Private and invite-only rooms can only
+// be joined by existing members.
+func (s *ChatService) JoinRoom(roomID, userID uuid.UUID) error {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return err
+	}
+
+	if isPublic(room) {
+		return s.rooms.AddMember(roomID, userID)
+	}
+
+	_, err = s.member(roomID, userID)
+	return err
+}
+
+// InviteMember adds userID to the room on behalf of actorID. Any member may
+// invite to a public room; other rooms require a moderator or the owner.
+func (s *ChatService) InviteMember(roomID, actorID, userID uuid.UUID) error {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return err
+	}
+
+	actor, err := s.member(roomID, actorID)
+	if err != nil {
+		return err
+	}
+
+	if !isPublic(room) && roleRank(actor.Role) < roleRank(repository.RoleModerator) {
+		return ErrForbidden
+	}
+
+	return s.rooms.AddMember(roomID, userID)
+}
+
+// RemoveMember kicks userID from the room, or lets a member leave when actorID
+// and userID are the same. Only members with a higher role than the target can
+// kick, and the owner cannot leave their own room.
+func (s *ChatService) RemoveMember(roomID, actorID, userID uuid.UUID) error {
+	actor, err := s.member(roomID, actorID)
+	if err != nil {
+		return err
+	}
+
+	if actorID == userID {
+		if actor.Role == repository.RoleOwner {
+			return ErrForbidden
+		}
+		return s.rooms.RemoveMember(roomID, userID)
+	}
+
+	target, err := s.rooms.GetMember(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	if roleRank(actor.Role) < roleRank(repository.RoleModerator) || roleRank(actor.Role) <= roleRank(target.Role) {
+		return ErrForbidden
+	}
+
+	return s.rooms.RemoveMember(roomID, userID)
+}
+
+// SetMemberRole lets the owner promote members to moderator or demote them
+// back. Ownership itself cannot be assigned.
+func (s *ChatService) SetMemberRole(roomID, actorID, userID uuid.UUID, role string) error {
+	if role != repository.RoleModerator && role != repository.RoleMember {
+		return ErrInvalidRole
+	}
+
+	actor, err := s.member(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != repository.RoleOwner {
+		return ErrForbidden
+	}
+
+	target, err := s.rooms.GetMember(roomID, userID)
+	if err != nil {
+		return err
+	}
+	if target.Role == repository.RoleOwner {
+		return ErrForbidden
+	}
+
+	return s.rooms.SetMemberRole(roomID, userID, role)
+}
+
+// SetTopic changes a room's topic. Only the owner and moderators may.
+func (s *ChatService) SetTopic(roomID, actorID uuid.UUID, topic string) error {
+	actor, err := s.member(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != repository.RoleOwner && actor.Role != repository.RoleModerator {
+		return ErrForbidden
+	}
+	return s.rooms.SetTopic(roomID, topic)
+}
+
+// SetRetention changes how long a room keeps its messages. Only the owner may,
+// since messages that have outlived the new policy are deleted.
+func (s *ChatService) SetRetention(roomID, actorID uuid.UUID, retention repository.Retention) error {
+	if retention.MaxAgeDays < 0 || retention.MaxMessages < 0 {
+		return ErrInvalidRetention
+	}
+	actor, err := s.member(roomID, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != repository.RoleOwner {
+		return ErrForbidden
+	}
+	return s.rooms.SetRetention(roomID, retention)
+}
+
+// ListMembers returns the members of a room in the order they joined. Anyone
+// may list a public room; other rooms are only visible to their members.
+func (s *ChatService) ListMembers(roomID, actorID uuid.UUID) ([]MemberInfo, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isPublic(room) {
+		if _, err := s.member(roomID, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := s.rooms.ListMembers(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]MemberInfo, len(members))
+	for i, m := range members {
+		infos[i] = MemberInfo{UserID: m.UserID, Name: m.User.Name, Role: m.Role}
+	}
+	return infos, nil
+}
+
+// RoomKeys returns the published public keys of a room's members, for
+// encrypting messages to them. Only members may fetch them, and members who
+// have not published a key are left out.
+func (s *ChatService) RoomKeys(roomID, actorID uuid.UUID) ([]MemberKey, error) {
+	if _, err := s.member(roomID, actorID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.rooms.ListMembers(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []MemberKey{}
+	for _, m := range members {
+		if m.User.PublicKey != "" {
+			keys = append(keys, MemberKey{UserID: m.UserID, Name: m.User.Name, PublicKey: m.User.PublicKey})
+		}
+	}
+	return keys, nil
+}
+
+// member looks up userID's membership, reporting ErrNotRoomMember if they have
+// none.
+func (s *ChatService) member(roomID, userID uuid.UUID) (*repository.RoomMember, error) {
+	return roomMember(s.rooms, roomID, userID)
+}
+
+func roomMember(rooms RoomStore, roomID, userID uuid.UUID) (*repository.RoomMember, error) {
+	member, err := rooms.GetMember(roomID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotRoomMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// isPublic treats rooms created before visibility existed as public.
+func isPublic(room *repository.Room) bool {
+	return room.Visibility == "" || room.Visibility == repository.RoomVisibilityPublic
+}
+
+func roleRank(role string) int {
+	switch role {
+	case repository.RoleOwner:
+		return 3
+	case repository.RoleModerator:
+		return 2
+	case repository.RoleMember:
+		return 1
+	default:
+		return 0
 	}
-	return msg.ID, msg.CreatedAt, nil
 }
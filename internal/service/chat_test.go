@@ -1,10 +1,14 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/EwanGreer/chatatui/internal/e2e"
+	"github.com/EwanGreer/chatatui/internal/limits"
 	"github.com/EwanGreer/chatatui/internal/repository"
 	mocks "github.com/EwanGreer/chatatui/internal/service/_mocks"
 	"github.com/google/uuid"
@@ -175,6 +179,228 @@ func TestChatService_GetMessageHistory(t *testing.T) {
 	}
 }
 
+func TestChatService_GetMessagesBefore(t *testing.T) {
+	roomID := uuid.New()
+	viewerID := uuid.New()
+	before := uuid.New()
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// Newest first, as returned by the store.
+	stored := []repository.Message{
+		{BaseModel: repository.BaseModel{ID: ids[2]}, Content: []byte("third")},
+		{BaseModel: repository.BaseModel{ID: ids[1]}, Content: []byte("second")},
+		{BaseModel: repository.BaseModel{ID: ids[0]}, Content: []byte("first")},
+	}
+
+	tests := []struct {
+		name        string
+		stored      []repository.Message
+		wantIDs     []uuid.UUID
+		wantHasMore bool
+	}{
+		{
+			name:        "reports more when store returns an extra row",
+			stored:      stored,
+			wantIDs:     []uuid.UUID{ids[1], ids[2]},
+			wantHasMore: true,
+		},
+		{
+			name:    "last page has no more",
+			stored:  stored[:2],
+			wantIDs: []uuid.UUID{ids[1], ids[2]},
+		},
+		{
+			name:    "empty page",
+			stored:  []repository.Message{},
+			wantIDs: []uuid.UUID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			messages.EXPECT().GetByRoomBefore(roomID, before, 3).Return(tt.stored, nil)
+			messages.EXPECT().ThreadSummaries(mock.Anything).
+				Return([]repository.ThreadSummary{{ParentID: ids[2], ReplyCount: 3, LastReplyAt: time.Now()}}, nil).
+				Maybe()
+			messages.EXPECT().ReactionCounts(mock.Anything, viewerID).
+				Return([]repository.ReactionCount{{MessageID: ids[1], Emoji: "👍", Count: 2, Mine: true}}, nil).
+				Maybe()
+
+			svc := NewChatService(rooms, messages)
+			page, err := svc.GetMessagesBefore(roomID, viewerID, before, 2)
+			require.NoError(t, err)
+
+			gotIDs := make([]uuid.UUID, len(page.Messages))
+			for i, m := range page.Messages {
+				gotIDs[i] = m.ID
+				if m.ID == ids[2] {
+					assert.Equal(t, 3, m.ReplyCount)
+					assert.NotNil(t, m.LastReplyAt)
+				} else {
+					assert.Zero(t, m.ReplyCount)
+				}
+				if m.ID == ids[1] {
+					assert.Equal(t, []ReactionInfo{{Emoji: "👍", Count: 2, Mine: true}}, m.Reactions)
+				}
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+			assert.Equal(t, tt.wantHasMore, page.HasMore)
+		})
+	}
+}
+
+func TestChatService_PersistReply(t *testing.T) {
+	senderID := uuid.New()
+	roomID := uuid.New()
+	parentID := uuid.New()
+	replyID := uuid.New()
+
+	tests := []struct {
+		name    string
+		setup   func(*mocks.MockMessageStore)
+		wantErr error
+	}{
+		{
+			name: "stores reply under parent",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(parentID).Return(&repository.Message{RoomID: roomID}, nil)
+				m.EXPECT().Create(mockAny).RunAndReturn(func(msg *repository.Message) error {
+					require.NotNil(t, msg.ParentID)
+					assert.Equal(t, parentID, *msg.ParentID)
+					msg.ID = replyID
+					return nil
+				})
+			},
+		},
+		{
+			name: "parent in another room is not found",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(parentID).Return(&repository.Message{RoomID: uuid.New()}, nil)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "cannot reply to a reply",
+			setup: func(m *mocks.MockMessageStore) {
+				grandparent := uuid.New()
+				m.EXPECT().GetByID(parentID).Return(&repository.Message{RoomID: roomID, ParentID: &grandparent}, nil)
+			},
+			wantErr: ErrInvalidParent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(messages)
+
+			rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil).Maybe()
+
+			svc := NewChatService(rooms, messages)
+			id, _, _, err := svc.PersistReply([]byte("reply"), senderID, roomID, parentID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, replyID, id)
+		})
+	}
+}
+
+func TestChatService_GetThread(t *testing.T) {
+	roomID := uuid.New()
+	parentID := uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	messages.EXPECT().GetByID(parentID).Return(&repository.Message{RoomID: roomID}, nil)
+	messages.EXPECT().GetReplies(parentID).Return([]repository.Message{
+		{BaseModel: repository.BaseModel{ID: uuid.New()}, RoomID: roomID, ParentID: &parentID, Content: []byte("first")},
+		{BaseModel: repository.BaseModel{ID: uuid.New()}, RoomID: roomID, ParentID: &parentID, Content: []byte("second")},
+	}, nil)
+	messages.EXPECT().ReactionCounts(mock.Anything, uuid.Nil).Return(nil, nil)
+
+	svc := NewChatService(rooms, messages)
+	replies, err := svc.GetThread(roomID, uuid.Nil, parentID)
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "first", replies[0].Content)
+	assert.Equal(t, parentID, *replies[1].ParentID)
+}
+
+func TestChatService_React(t *testing.T) {
+	userID := uuid.New()
+	roomID := uuid.New()
+	msgID := uuid.New()
+
+	tests := []struct {
+		name        string
+		emoji       string
+		setup       func(*mocks.MockMessageStore)
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name:  "adds reaction",
+			emoji: "👍",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: roomID}, nil)
+				m.EXPECT().AddReaction(msgID, userID, "👍").Return(true, nil)
+			},
+			wantChanged: true,
+		},
+		{
+			name:  "repeat reaction is unchanged",
+			emoji: "👍",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: roomID}, nil)
+				m.EXPECT().AddReaction(msgID, userID, "👍").Return(false, nil)
+			},
+		},
+		{
+			name:    "empty emoji rejected",
+			emoji:   "",
+			setup:   func(*mocks.MockMessageStore) {},
+			wantErr: ErrInvalidReaction,
+		},
+		{
+			name:    "whitespace rejected",
+			emoji:   "thumbs up",
+			setup:   func(*mocks.MockMessageStore) {},
+			wantErr: ErrInvalidReaction,
+		},
+		{
+			name:  "message in another room is not found",
+			emoji: "👍",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: uuid.New()}, nil)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(messages)
+
+			svc := NewChatService(rooms, messages)
+			changed, err := svc.React(msgID, userID, roomID, tt.emoji)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
 func TestChatService_PersistMessage(t *testing.T) {
 	senderID := uuid.New()
 	roomID := uuid.New()
@@ -189,6 +415,7 @@ func TestChatService_PersistMessage(t *testing.T) {
 			name: "persists message and returns ID and timestamp",
 			setup: func(m *mocks.MockMessageStore) {
 				m.EXPECT().Create(mockAny).RunAndReturn(func(msg *repository.Message) error {
+					assert.Equal(t, repository.MessageKindAction, msg.Kind)
 					msg.ID = uuid.New()
 					msg.CreatedAt = time.Now()
 					return nil
@@ -210,8 +437,10 @@ func TestChatService_PersistMessage(t *testing.T) {
 			messages := mocks.NewMockMessageStore(t)
 			tt.setup(messages)
 
+			rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+
 			svc := NewChatService(rooms, messages)
-			id, createdAt, err := svc.PersistMessage(content, senderID, roomID)
+			id, createdAt, _, err := svc.PersistMessage(content, repository.MessageKindAction, senderID, roomID)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -227,5 +456,998 @@ func TestChatService_PersistMessage(t *testing.T) {
 	}
 }
 
-// mockAny matches any argument — used where the exact value is set inside the function.
-var mockAny = mock.MatchedBy(func(_ *repository.Message) bool { return true })
+func TestChatService_EncryptedRoomsRefusePlaintext(t *testing.T) {
+	senderID := uuid.New()
+	roomID := uuid.New()
+	key, err := e2e.GenerateKey()
+	require.NoError(t, err)
+	sealed, err := e2e.Seal([]byte("secret"), key, nil)
+	require.NoError(t, err)
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	rooms.EXPECT().GetByID(roomID).Return(&repository.Room{Encrypted: true}, nil)
+	messages.EXPECT().Create(mockAny).RunAndReturn(func(msg *repository.Message) error {
+		assert.Equal(t, sealed, msg.Content)
+		msg.ID = uuid.New()
+		return nil
+	}).Once()
+	svc := NewChatService(rooms, messages)
+
+	_, _, _, err = svc.PersistMessage([]byte("secret"), repository.MessageKindChat, senderID, roomID)
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+
+	_, _, _, err = svc.PersistMessage(sealed, repository.MessageKindChat, senderID, roomID)
+	assert.NoError(t, err)
+
+	msgID := uuid.New()
+	messages.EXPECT().GetByID(msgID).Return(&repository.Message{SenderID: senderID, RoomID: roomID}, nil)
+	_, err = svc.EditMessage(msgID, senderID, roomID, []byte("not so secret"))
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+}
+
+func TestChatService_PersistMessage_RecordsMentions(t *testing.T) {
+	senderID := uuid.New()
+	roomID := uuid.New()
+	bob, bobby, carol := uuid.New(), uuid.New(), uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+	rooms.EXPECT().ListMembers(roomID).Return([]repository.RoomMember{
+		{UserID: senderID, User: repository.User{Name: "alice"}},
+		{UserID: bob, User: repository.User{Name: "Bob"}},
+		{UserID: bobby, User: repository.User{Name: "bobby"}},
+		{UserID: carol, User: repository.User{Name: "carol"}},
+	}, nil)
+	messages.EXPECT().Create(mockAny).RunAndReturn(func(msg *repository.Message) error {
+		assert.Equal(t, []repository.Mention{{UserID: bob}}, msg.Mentions)
+		msg.ID = uuid.New()
+		return nil
+	})
+
+	svc := NewChatService(rooms, messages)
+	_, _, mentioned, err := svc.PersistMessage([]byte("@bob, @alice: mail carol@example.com"), repository.MessageKindChat, senderID, roomID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{bob}, mentioned)
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text, name string
+		want       bool
+	}{
+		{"hey @bob", "bob", true},
+		{"@BOB: look", "bob", true},
+		{"thanks @bob's review", "bob", true},
+		{"ping @bobby", "bob", false},
+		{"mail bob@bob.com", "bob", false},
+		{"bob, no at sign", "bob", false},
+		{"@bobby then @bob", "bob", true},
+		{"@ann lee is here", "ann lee", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, mentions(tt.text, tt.name), "mentions(%q, %q)", tt.text, tt.name)
+	}
+}
+
+func TestChatService_Mentions(t *testing.T) {
+	userID := uuid.New()
+	before := uuid.New()
+	msgID := uuid.New()
+
+	messages := mocks.NewMockMessageStore(t)
+	messages.EXPECT().MentionsOf(userID, before, 20).Return([]repository.Message{
+		{BaseModel: repository.BaseModel{ID: msgID}, Content: []byte("@alice hi"), Kind: repository.MessageKindChat, Sender: repository.User{Name: "bob"}},
+	}, nil)
+
+	infos, err := NewChatService(mocks.NewMockRoomStore(t), messages).Mentions(userID, before, 20)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, msgID, infos[0].ID)
+	assert.Equal(t, "bob", infos[0].Author)
+	assert.Equal(t, "@alice hi", infos[0].Content)
+}
+
+func TestChatService_RoomKeys(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	keyless := uuid.New()
+
+	t.Run("lists members with published keys", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{}, nil)
+		rooms.EXPECT().ListMembers(roomID).Return([]repository.RoomMember{
+			{UserID: actorID, User: repository.User{Name: "alice", PublicKey: "YWxpY2U="}},
+			{UserID: keyless, User: repository.User{Name: "bob"}},
+		}, nil)
+
+		keys, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).RoomKeys(roomID, actorID)
+		require.NoError(t, err)
+		assert.Equal(t, []MemberKey{{UserID: actorID, Name: "alice", PublicKey: "YWxpY2U="}}, keys)
+	})
+
+	t.Run("refuses non-members", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().GetMember(roomID, actorID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).RoomKeys(roomID, actorID)
+		assert.ErrorIs(t, err, ErrNotRoomMember)
+	})
+}
+
+func TestChatService_PersistAttachment(t *testing.T) {
+	roomID := uuid.New()
+	senderID := uuid.New()
+	att := AttachmentInfo{Key: "k", Name: "shot.png", ContentType: "image/png", Size: 42}
+
+	t.Run("stores a file message", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		messages := mocks.NewMockMessageStore(t)
+		rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+		rooms.EXPECT().GetMember(roomID, senderID).Return(&repository.RoomMember{}, nil)
+		messages.EXPECT().Create(mockAny).RunAndReturn(func(msg *repository.Message) error {
+			assert.Equal(t, repository.MessageKindFile, msg.Kind)
+			assert.Equal(t, "shot.png", msg.Attachment.Name)
+			msg.ID = uuid.New()
+			return nil
+		})
+
+		info, err := NewChatService(rooms, messages).PersistAttachment(senderID, roomID, []byte("look"), att)
+		require.NoError(t, err)
+		assert.Equal(t, "look", info.Content)
+		assert.Equal(t, &att, info.Attachment)
+	})
+
+	t.Run("refuses non-members", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+		rooms.EXPECT().GetMember(roomID, senderID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).PersistAttachment(senderID, roomID, nil, att)
+		assert.ErrorIs(t, err, ErrNotRoomMember)
+	})
+
+	t.Run("refuses encrypted rooms", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().GetByID(roomID).Return(&repository.Room{Encrypted: true}, nil)
+		rooms.EXPECT().GetMember(roomID, senderID).Return(&repository.RoomMember{}, nil)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).PersistAttachment(senderID, roomID, nil, att)
+		assert.ErrorIs(t, err, ErrNotEncrypted)
+	})
+}
+
+func TestChatService_GetAttachment(t *testing.T) {
+	msgID := uuid.New()
+	roomID := uuid.New()
+	viewerID := uuid.New()
+	file := func(visibility string) *repository.Message {
+		return &repository.Message{
+			BaseModel:  repository.BaseModel{ID: msgID},
+			Kind:       repository.MessageKindFile,
+			RoomID:     roomID,
+			Room:       repository.Room{Visibility: visibility},
+			Attachment: repository.Attachment{Key: "k", Name: "a.txt"},
+		}
+	}
+
+	t.Run("public rooms are open to anyone", func(t *testing.T) {
+		messages := mocks.NewMockMessageStore(t)
+		messages.EXPECT().GetByID(msgID).Return(file(repository.RoomVisibilityPublic), nil)
+
+		info, err := NewChatService(mocks.NewMockRoomStore(t), messages).GetAttachment(msgID, viewerID)
+		require.NoError(t, err)
+		assert.Equal(t, "k", info.Attachment.Key)
+	})
+
+	t.Run("private rooms need membership", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		messages := mocks.NewMockMessageStore(t)
+		messages.EXPECT().GetByID(msgID).Return(file(repository.RoomVisibilityPrivate), nil)
+		rooms.EXPECT().GetMember(roomID, viewerID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewChatService(rooms, messages).GetAttachment(msgID, viewerID)
+		assert.ErrorIs(t, err, ErrNotRoomMember)
+	})
+
+	t.Run("messages without a file", func(t *testing.T) {
+		messages := mocks.NewMockMessageStore(t)
+		messages.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: roomID}, nil)
+
+		_, err := NewChatService(mocks.NewMockRoomStore(t), messages).GetAttachment(msgID, viewerID)
+		assert.ErrorIs(t, err, ErrNotAttachment)
+	})
+}
+
+func TestChatService_ExportMessages(t *testing.T) {
+	roomID := uuid.New()
+	viewerID := uuid.New()
+	after := uuid.New()
+
+	t.Run("public rooms are open to anyone", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		messages := mocks.NewMockMessageStore(t)
+		rooms.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPublic}, nil)
+		messages.EXPECT().GetByRoomAfter(roomID, after, 100).Return([]repository.Message{
+			{Content: []byte("hi"), Kind: repository.MessageKindChat, SenderID: viewerID, Sender: repository.User{Name: "alice"}},
+		}, nil)
+
+		infos, err := NewChatService(rooms, messages).ExportMessages(roomID, viewerID, after, 100)
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		assert.Equal(t, viewerID, infos[0].AuthorID)
+		assert.Equal(t, "alice", infos[0].Author)
+	})
+
+	t.Run("private rooms need membership", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPrivate}, nil)
+		rooms.EXPECT().GetMember(roomID, viewerID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).ExportMessages(roomID, viewerID, after, 100)
+		assert.ErrorIs(t, err, ErrNotRoomMember)
+	})
+}
+
+func TestChatService_ImportRoom(t *testing.T) {
+	actorID := uuid.New()
+	bobID := uuid.New()
+	parentID := uuid.New()
+	sent := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	parent := MessageInfo{ID: parentID, Author: "alice", AuthorID: actorID, Content: "hello", Kind: repository.MessageKindChat, CreatedAt: sent}
+	reply := MessageInfo{ID: uuid.New(), Author: "bob", AuthorID: bobID, Content: "hi", Kind: repository.MessageKindChat, CreatedAt: sent, ParentID: &parentID}
+	file := MessageInfo{
+		ID: uuid.New(), Author: "bob", AuthorID: bobID, Kind: repository.MessageKindFile, CreatedAt: sent,
+		Attachment: &AttachmentInfo{Key: "secret", Name: "a.txt", ContentType: "text/plain", Size: 3},
+	}
+
+	t.Run("keeps messages and makes placeholders of other authors", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().Import(mock.Anything, actorID, mock.Anything, mock.Anything).
+			Run(func(room *repository.Room, _ uuid.UUID, authors []repository.User, messages []repository.Message) {
+				assert.Equal(t, repository.RoomVisibilityPrivate, room.Visibility)
+				assert.Equal(t, repository.RoomKindRoom, room.Kind)
+				require.Len(t, authors, 1)
+				assert.Equal(t, bobID, authors[0].ID)
+				assert.Equal(t, "bob", authors[0].Name)
+				require.Len(t, messages, 3)
+				assert.Equal(t, parentID, messages[0].ID)
+				assert.Equal(t, sent, messages[0].CreatedAt)
+				assert.Equal(t, actorID, messages[0].SenderID)
+				assert.Equal(t, &parentID, messages[1].ParentID)
+				assert.Equal(t, "a.txt", messages[2].Attachment.Name)
+				assert.Empty(t, messages[2].Attachment.Key)
+			}).Return(nil)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).ImportRoom(actorID,
+			RoomInfo{Name: "archive", Kind: repository.RoomKindDM}, []MessageInfo{parent, reply, file})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects invalid messages", func(t *testing.T) {
+		nested := reply
+		nested.ID = uuid.New()
+		nested.ParentID = &reply.ID
+		unknown := parent
+		unknown.Kind = "poll"
+		long := parent
+		long.Content = strings.Repeat("a", limits.MaxMessageLength+1)
+		oversized := parent
+		oversized.Content = envelopeLongerThan(t, limits.MaxEncryptedMessageLength)
+
+		for name, messages := range map[string][]MessageInfo{
+			"reply before its parent": {reply, parent},
+			"reply to a reply":        {parent, reply, nested},
+			"duplicate":               {parent, parent},
+			"unknown kind":            {unknown},
+			"too long":                {long},
+			"oversized envelope":      {oversized},
+			"missing author":          {{ID: uuid.New(), Kind: repository.MessageKindChat, CreatedAt: sent}},
+		} {
+			_, err := NewChatService(mocks.NewMockRoomStore(t), mocks.NewMockMessageStore(t)).ImportRoom(actorID, RoomInfo{Name: "archive"}, messages)
+			assert.ErrorIs(t, err, ErrInvalidImport, name)
+		}
+	})
+
+	t.Run("allows envelopes past the plaintext limit", func(t *testing.T) {
+		sealed := parent
+		sealed.Content = envelopeLongerThan(t, limits.MaxMessageLength)
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().Import(mock.Anything, actorID, mock.Anything, mock.Anything).Return(nil)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).ImportRoom(actorID,
+			RoomInfo{Name: "archive", Encrypted: true}, []MessageInfo{sealed})
+		require.NoError(t, err)
+	})
+
+	t.Run("refuses messages already imported", func(t *testing.T) {
+		rooms := mocks.NewMockRoomStore(t)
+		rooms.EXPECT().Import(mock.Anything, actorID, mock.Anything, mock.Anything).Return(repository.ErrMessagesExist)
+
+		_, err := NewChatService(rooms, mocks.NewMockMessageStore(t)).ImportRoom(actorID, RoomInfo{Name: "archive"}, []MessageInfo{parent})
+		assert.ErrorIs(t, err, ErrAlreadyImported)
+	})
+}
+
+// envelopeLongerThan returns a well-formed end-to-end envelope one byte longer
+// than n.
+func envelopeLongerThan(t *testing.T, n int) string {
+	t.Helper()
+	env := e2e.Envelope{Version: e2e.Version, SenderKey: "s", Nonce: "n", Keys: map[string]string{"r": "k"}}
+	empty, err := json.Marshal(env)
+	require.NoError(t, err)
+	env.Ciphertext = strings.Repeat("a", n+1-len(empty))
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	require.True(t, e2e.IsEnvelope(data))
+	return string(data)
+}
+
+func TestChatService_EditMessage(t *testing.T) {
+	msgID := uuid.New()
+	senderID := uuid.New()
+	roomID := uuid.New()
+	content := []byte("edited")
+
+	stored := func(sender, room uuid.UUID) *repository.Message {
+		return &repository.Message{
+			BaseModel: repository.BaseModel{ID: msgID},
+			SenderID:  sender,
+			RoomID:    room,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockMessageStore)
+		wantErrIs error
+	}{
+		{
+			name: "updates content of own message",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(stored(senderID, roomID), nil)
+				m.EXPECT().UpdateContent(msgID, content, mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "rejects message from another author",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(stored(uuid.New(), roomID), nil)
+			},
+			wantErrIs: ErrNotMessageAuthor,
+		},
+		{
+			name: "reports message from another room as not found",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(stored(senderID, uuid.New()), nil)
+			},
+			wantErrIs: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "propagates lookup error",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(messages)
+
+			rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil).Maybe()
+
+			svc := NewChatService(rooms, messages)
+			editedAt, err := svc.EditMessage(msgID, senderID, roomID, content)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				assert.Zero(t, editedAt)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotZero(t, editedAt)
+		})
+	}
+}
+
+func TestChatService_DeleteMessage(t *testing.T) {
+	msgID := uuid.New()
+	senderID := uuid.New()
+	roomID := uuid.New()
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockMessageStore)
+		wantErrIs error
+	}{
+		{
+			name: "deletes own message",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{SenderID: senderID, RoomID: roomID}, nil)
+				m.EXPECT().Delete(msgID).Return(nil)
+			},
+		},
+		{
+			name: "rejects message from another author",
+			setup: func(m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{SenderID: uuid.New(), RoomID: roomID}, nil)
+			},
+			wantErrIs: ErrNotMessageAuthor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(messages)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.DeleteMessage(msgID, senderID, roomID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+// mockAny matches any argument — used where the exact value is set inside the function.
+var mockAny = mock.MatchedBy(func(_ *repository.Message) bool { return true })
+
+func TestChatService_JoinRoom(t *testing.T) {
+	roomID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockRoomStore)
+		wantErrIs error
+	}{
+		{
+			name: "adds user to public room",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPublic}, nil)
+				m.EXPECT().AddMember(roomID, userID).Return(nil)
+			},
+		},
+		{
+			name: "allows existing member of private room",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPrivate}, nil)
+				m.EXPECT().GetMember(roomID, userID).Return(&repository.RoomMember{Role: repository.RoleMember}, nil)
+			},
+		},
+		{
+			name: "rejects non-member of invite-only room",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityInviteOnly}, nil)
+				m.EXPECT().GetMember(roomID, userID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: ErrNotRoomMember,
+		},
+		{
+			name: "propagates room lookup error",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.JoinRoom(roomID, userID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_InviteMember(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	inviteeID := uuid.New()
+
+	tests := []struct {
+		name       string
+		visibility string
+		actorRole  string
+		wantErrIs  error
+	}{
+		{name: "member invites to public room", visibility: repository.RoomVisibilityPublic, actorRole: repository.RoleMember},
+		{name: "moderator invites to private room", visibility: repository.RoomVisibilityPrivate, actorRole: repository.RoleModerator},
+		{name: "member cannot invite to invite-only room", visibility: repository.RoomVisibilityInviteOnly, actorRole: repository.RoleMember, wantErrIs: ErrForbidden},
+		{name: "non-member cannot invite", visibility: repository.RoomVisibilityPublic, wantErrIs: ErrNotRoomMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+
+			rooms.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: tt.visibility}, nil)
+			if tt.actorRole == "" {
+				rooms.EXPECT().GetMember(roomID, actorID).Return(nil, gorm.ErrRecordNotFound)
+			} else {
+				rooms.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: tt.actorRole}, nil)
+			}
+			if tt.wantErrIs == nil {
+				rooms.EXPECT().AddMember(roomID, inviteeID).Return(nil)
+			}
+
+			svc := NewChatService(rooms, messages)
+			err := svc.InviteMember(roomID, actorID, inviteeID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_RemoveMember(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name       string
+		targetID   uuid.UUID
+		actorRole  string
+		targetRole string
+		wantErrIs  error
+	}{
+		{name: "member leaves room", targetID: actorID, actorRole: repository.RoleMember},
+		{name: "owner cannot leave own room", targetID: actorID, actorRole: repository.RoleOwner, wantErrIs: ErrForbidden},
+		{name: "moderator kicks member", targetID: targetID, actorRole: repository.RoleModerator, targetRole: repository.RoleMember},
+		{name: "owner kicks moderator", targetID: targetID, actorRole: repository.RoleOwner, targetRole: repository.RoleModerator},
+		{name: "moderator cannot kick moderator", targetID: targetID, actorRole: repository.RoleModerator, targetRole: repository.RoleModerator, wantErrIs: ErrForbidden},
+		{name: "member cannot kick member", targetID: targetID, actorRole: repository.RoleMember, targetRole: repository.RoleMember, wantErrIs: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+
+			rooms.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: tt.actorRole}, nil)
+			if tt.targetRole != "" {
+				rooms.EXPECT().GetMember(roomID, tt.targetID).Return(&repository.RoomMember{Role: tt.targetRole}, nil)
+			}
+			if tt.wantErrIs == nil {
+				rooms.EXPECT().RemoveMember(roomID, tt.targetID).Return(nil)
+			}
+
+			svc := NewChatService(rooms, messages)
+			err := svc.RemoveMember(roomID, actorID, tt.targetID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_SetMemberRole(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name      string
+		role      string
+		setup     func(*mocks.MockRoomStore)
+		wantErrIs error
+	}{
+		{
+			name: "owner promotes member",
+			role: repository.RoleModerator,
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleOwner}, nil)
+				m.EXPECT().GetMember(roomID, targetID).Return(&repository.RoomMember{Role: repository.RoleMember}, nil)
+				m.EXPECT().SetMemberRole(roomID, targetID, repository.RoleModerator).Return(nil)
+			},
+		},
+		{
+			name:      "rejects assigning owner",
+			role:      repository.RoleOwner,
+			setup:     func(*mocks.MockRoomStore) {},
+			wantErrIs: ErrInvalidRole,
+		},
+		{
+			name: "moderator cannot change roles",
+			role: repository.RoleModerator,
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleModerator}, nil)
+			},
+			wantErrIs: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.SetMemberRole(roomID, actorID, targetID, tt.role)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_ListMembers(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	memberID := uuid.New()
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockRoomStore)
+		want      []MemberInfo
+		wantErrIs error
+	}{
+		{
+			name: "lists members of public room",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPublic}, nil)
+				m.EXPECT().ListMembers(roomID).Return([]repository.RoomMember{
+					{UserID: memberID, Role: repository.RoleOwner, User: repository.User{Name: "alice"}},
+				}, nil)
+			},
+			want: []MemberInfo{{UserID: memberID, Name: "alice", Role: repository.RoleOwner}},
+		},
+		{
+			name: "hides private room from non-members",
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByID(roomID).Return(&repository.Room{Visibility: repository.RoomVisibilityPrivate}, nil)
+				m.EXPECT().GetMember(roomID, actorID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: ErrNotRoomMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			got, err := svc.ListMembers(roomID, actorID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestChatService_OpenDM(t *testing.T) {
+	actorID := uuid.New()
+	otherID := uuid.New()
+	roomID := uuid.New()
+	key := repository.DMKey([]uuid.UUID{actorID, otherID})
+	dm := &repository.Room{BaseModel: repository.BaseModel{ID: roomID}, Kind: repository.RoomKindDM}
+
+	tests := []struct {
+		name         string
+		participants []uuid.UUID
+		setup        func(*mocks.MockRoomStore)
+		wantCreated  bool
+		wantErrIs    error
+	}{
+		{
+			name:         "creates room for first conversation",
+			participants: []uuid.UUID{otherID},
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByDMKey(key).Return(nil, gorm.ErrRecordNotFound)
+				m.EXPECT().CreateDM([]uuid.UUID{actorID, otherID}).Return(dm, nil)
+			},
+			wantCreated: true,
+		},
+		{
+			name:         "reuses existing room and restores members",
+			participants: []uuid.UUID{otherID, otherID},
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByDMKey(key).Return(dm, nil)
+				m.EXPECT().AddMember(roomID, actorID).Return(nil)
+				m.EXPECT().AddMember(roomID, otherID).Return(nil)
+			},
+		},
+		{
+			name:         "falls back to room created concurrently",
+			participants: []uuid.UUID{otherID},
+			setup: func(m *mocks.MockRoomStore) {
+				m.EXPECT().GetByDMKey(key).Return(nil, gorm.ErrRecordNotFound).Once()
+				m.EXPECT().CreateDM([]uuid.UUID{actorID, otherID}).Return(nil, errors.New("duplicate key"))
+				m.EXPECT().GetByDMKey(key).Return(dm, nil).Once()
+				m.EXPECT().AddMember(roomID, actorID).Return(nil)
+				m.EXPECT().AddMember(roomID, otherID).Return(nil)
+			},
+		},
+		{
+			name:         "rejects conversation with only yourself",
+			participants: []uuid.UUID{actorID},
+			setup:        func(*mocks.MockRoomStore) {},
+			wantErrIs:    ErrInvalidDM,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			info, created, err := svc.OpenDM(actorID, tt.participants)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, roomID, info.ID)
+			assert.Equal(t, repository.RoomKindDM, info.Kind)
+			assert.Equal(t, tt.wantCreated, created)
+		})
+	}
+}
+
+func TestChatService_MarkRead(t *testing.T) {
+	roomID := uuid.New()
+	userID := uuid.New()
+	msgID := uuid.New()
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockRoomStore, *mocks.MockMessageStore)
+		wantErrIs error
+	}{
+		{
+			name: "moves read marker",
+			setup: func(r *mocks.MockRoomStore, m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: roomID}, nil)
+				r.EXPECT().MarkRead(roomID, userID, msgID).Return(nil)
+			},
+		},
+		{
+			name: "reports message from another room as not found",
+			setup: func(_ *mocks.MockRoomStore, m *mocks.MockMessageStore) {
+				m.EXPECT().GetByID(msgID).Return(&repository.Message{RoomID: uuid.New()}, nil)
+			},
+			wantErrIs: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms, messages)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.MarkRead(roomID, userID, msgID)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_UnreadCounts(t *testing.T) {
+	userID := uuid.New()
+	roomA := uuid.New()
+	roomB := uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	rooms.EXPECT().UnreadCounts(userID).Return([]repository.UnreadCount{
+		{RoomID: roomA, Count: 3},
+		{RoomID: roomB, Count: 0},
+	}, nil)
+
+	svc := NewChatService(rooms, messages)
+	got, err := svc.UnreadCounts(userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{roomA: 3, roomB: 0}, got)
+}
+
+func TestChatService_SearchMessages(t *testing.T) {
+	actorID := uuid.New()
+	roomID := uuid.New()
+	hit := repository.Message{BaseModel: repository.BaseModel{ID: uuid.New()}, RoomID: roomID, Content: []byte("release"), Sender: repository.User{Name: "bob"}}
+
+	tests := []struct {
+		name      string
+		query     SearchQuery
+		setup     func(*mocks.MockRoomStore, *mocks.MockMessageStore)
+		wantErrIs error
+	}{
+		{
+			name:  "searches all member rooms",
+			query: SearchQuery{Text: "release", Limit: 20},
+			setup: func(_ *mocks.MockRoomStore, m *mocks.MockMessageStore) {
+				m.EXPECT().Search(repository.MessageSearch{Query: "release", UserID: actorID, Limit: 20}).
+					Return([]repository.Message{hit}, nil)
+			},
+		},
+		{
+			name:  "searches one room the actor is in",
+			query: SearchQuery{Text: "release", RoomID: roomID, Limit: 20},
+			setup: func(r *mocks.MockRoomStore, m *mocks.MockMessageStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{}, nil)
+				m.EXPECT().Search(repository.MessageSearch{Query: "release", UserID: actorID, RoomID: roomID, Limit: 20}).
+					Return([]repository.Message{hit}, nil)
+			},
+		},
+		{
+			name:  "rejects a room the actor is not in",
+			query: SearchQuery{Text: "release", RoomID: roomID, Limit: 20},
+			setup: func(r *mocks.MockRoomStore, _ *mocks.MockMessageStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: ErrNotRoomMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms, messages)
+
+			svc := NewChatService(rooms, messages)
+			got, err := svc.SearchMessages(actorID, tt.query)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, hit.ID, got[0].ID)
+			assert.Equal(t, roomID, got[0].RoomID)
+			assert.Equal(t, "bob", got[0].Author)
+		})
+	}
+}
+
+func TestChatService_SetTopic(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+
+	tests := []struct {
+		name      string
+		setup     func(*mocks.MockRoomStore)
+		wantErrIs error
+	}{
+		{
+			name: "moderator sets topic",
+			setup: func(r *mocks.MockRoomStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleModerator}, nil)
+				r.EXPECT().SetTopic(roomID, "release day").Return(nil)
+			},
+		},
+		{
+			name: "member is forbidden",
+			setup: func(r *mocks.MockRoomStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleMember}, nil)
+			},
+			wantErrIs: ErrForbidden,
+		},
+		{
+			name: "non-member is rejected",
+			setup: func(r *mocks.MockRoomStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrIs: ErrNotRoomMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.SetTopic(roomID, actorID, "release day")
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChatService_SetRetention(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+
+	tests := []struct {
+		name      string
+		retention repository.Retention
+		setup     func(*mocks.MockRoomStore)
+		wantErrIs error
+	}{
+		{
+			name:      "owner sets retention",
+			retention: repository.Retention{MaxAgeDays: 30},
+			setup: func(r *mocks.MockRoomStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleOwner}, nil)
+				r.EXPECT().SetRetention(roomID, repository.Retention{MaxAgeDays: 30}).Return(nil)
+			},
+		},
+		{
+			name:      "moderator is forbidden",
+			retention: repository.Retention{MaxMessages: 100},
+			setup: func(r *mocks.MockRoomStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleModerator}, nil)
+			},
+			wantErrIs: ErrForbidden,
+		},
+		{
+			name:      "negative values are rejected",
+			retention: repository.Retention{MaxMessages: -1},
+			setup:     func(*mocks.MockRoomStore) {},
+			wantErrIs: ErrInvalidRetention,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			messages := mocks.NewMockMessageStore(t)
+			tt.setup(rooms)
+
+			svc := NewChatService(rooms, messages)
+			err := svc.SetRetention(roomID, actorID, tt.retention)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
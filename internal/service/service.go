@@ -10,21 +10,151 @@ import (
 type RoomStore interface {
 	GetByID(id uuid.UUID) (*repository.Room, error)
 	AddMember(roomID, userID uuid.UUID) error
+	RemoveMember(roomID, userID uuid.UUID) error
+	GetMember(roomID, userID uuid.UUID) (*repository.RoomMember, error)
+	SetMemberRole(roomID, userID uuid.UUID, role string) error
+	SetTopic(roomID uuid.UUID, topic string) error
+	SetRetention(roomID uuid.UUID, retention repository.Retention) error
+	ListMembers(roomID uuid.UUID) ([]repository.RoomMember, error)
+	CreateDM(userIDs []uuid.UUID) (*repository.Room, error)
+	GetByDMKey(key string) (*repository.Room, error)
+	MarkRead(roomID, userID, messageID uuid.UUID) error
+	UnreadCounts(userID uuid.UUID) ([]repository.UnreadCount, error)
+	Import(room *repository.Room, ownerID uuid.UUID, authors []repository.User, messages []repository.Message) error
 }
 
 type MessageStore interface {
 	Create(msg *repository.Message) error
+	GetByID(id uuid.UUID) (*repository.Message, error)
 	GetByRoom(roomID uuid.UUID, limit, offset int) ([]repository.Message, error)
+	GetByRoomBefore(roomID, before uuid.UUID, limit int) ([]repository.Message, error)
+	GetByRoomAfter(roomID, after uuid.UUID, limit int) ([]repository.Message, error)
+	Search(params repository.MessageSearch) ([]repository.Message, error)
+	MentionsOf(userID, before uuid.UUID, limit int) ([]repository.Message, error)
+	GetReplies(parentID uuid.UUID) ([]repository.Message, error)
+	AddReaction(messageID, userID uuid.UUID, emoji string) (bool, error)
+	RemoveReaction(messageID, userID uuid.UUID, emoji string) (bool, error)
+	ReactionCounts(messageIDs []uuid.UUID, viewerID uuid.UUID) ([]repository.ReactionCount, error)
+	ThreadSummaries(parentIDs []uuid.UUID) ([]repository.ThreadSummary, error)
+	UpdateContent(id uuid.UUID, content []byte, editedAt time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type WebhookStore interface {
+	Create(hook *repository.Webhook) error
+	GetByID(id uuid.UUID) (*repository.Webhook, error)
+	GetByToken(token string) (*repository.Webhook, error)
+	ListByRoom(roomID uuid.UUID) ([]repository.Webhook, error)
+	Delete(id uuid.UUID) error
+	Deliveries(webhookID uuid.UUID, limit int) ([]repository.WebhookDelivery, error)
+}
+
+// MessageListener is told about each message PersistMessage or PersistReply
+// stores. It is called on the sender's goroutine, so must not block.
+type MessageListener interface {
+	MessageCreated(roomID, messageID uuid.UUID)
 }
 
 type RoomInfo struct {
-	ID   uuid.UUID
-	Name string
+	ID         uuid.UUID
+	Name       string
+	Topic      string
+	OwnerID    *uuid.UUID
+	Visibility string
+	Kind       string
+	Encrypted  bool
+	// Retention is the room's own retention policy, not including the
+	// server's.
+	Retention repository.Retention
+}
+
+type MemberInfo struct {
+	UserID uuid.UUID
+	Name   string
+	Role   string
+}
+
+// MemberKey is a room member's public key for end-to-end encryption.
+type MemberKey struct {
+	UserID    uuid.UUID
+	Name      string
+	PublicKey string
 }
 
 type MessageInfo struct {
 	ID        uuid.UUID
+	RoomID    uuid.UUID
 	Author    string
+	AuthorID  uuid.UUID
 	Content   string
+	Kind      string
 	CreatedAt time.Time
+	EditedAt  *time.Time
+	// ParentID is set on thread replies.
+	ParentID *uuid.UUID
+	// ReplyCount and LastReplyAt summarise the thread started by a message.
+	ReplyCount  int
+	LastReplyAt *time.Time
+	Reactions   []ReactionInfo
+	// Attachment is set on messages of kind repository.MessageKindFile.
+	Attachment *AttachmentInfo
+}
+
+// AttachmentInfo describes a file sent as a message. Key names its contents
+// in the blob store and is never shown to clients.
+type AttachmentInfo struct {
+	Key         string
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// ReactionInfo is how many users reacted to a message with an emoji, and
+// whether the viewer did.
+type ReactionInfo struct {
+	Emoji string
+	Count int
+	Mine  bool
+}
+
+// SearchQuery filters a message search. Zero-valued filters are ignored.
+type SearchQuery struct {
+	Text   string
+	RoomID uuid.UUID
+	Author string
+	Before time.Time
+	After  time.Time
+	Limit  int
+}
+
+// MessagePage is one page of a room's history in chronological order.
+type MessagePage struct {
+	Messages []MessageInfo
+	HasMore  bool
+}
+
+// WebhookInfo describes a room's webhook. Its token or secret is only
+// returned when it is created.
+type WebhookInfo struct {
+	ID     uuid.UUID
+	RoomID uuid.UUID
+	Kind   string
+	Name   string
+	// URL is set on outgoing webhooks, and UserID on incoming ones.
+	URL       string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+// DeliveryInfo is one attempt to send a message to an outgoing webhook.
+// StatusCode is zero when the receiver could not be reached.
+type DeliveryInfo struct {
+	ID         uuid.UUID
+	MessageID  uuid.UUID
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	Succeeded  bool
+	CreatedAt  time.Time
 }
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookService manages rooms' webhooks. Owners and moderators set them up;
+// messages then arrive through incoming webhooks without a member signing in.
+type WebhookService struct {
+	rooms RoomStore
+	hooks WebhookStore
+}
+
+func NewWebhookService(rooms RoomStore, hooks WebhookStore) *WebhookService {
+	return &WebhookService{rooms: rooms, hooks: hooks}
+}
+
+// CreateWebhook adds a webhook to a room and returns it with its secret, which
+// cannot be retrieved again: the token for an incoming webhook's URL, or the
+// key an outgoing webhook's deliveries are signed with. Outgoing webhooks need
+// an http or https URL. Encrypted rooms only take messages sealed by members,
+// so they cannot have incoming webhooks, and an incoming webhook cannot take a
+// name that a user already has.
+func (s *WebhookService) CreateWebhook(roomID, actorID uuid.UUID, kind, name, target string) (*WebhookInfo, string, error) {
+	if err := s.requireModerator(roomID, actorID); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	hook := &repository.Webhook{RoomID: roomID, Kind: kind, Name: name, CreatedByID: actorID}
+	switch kind {
+	case repository.WebhookKindIncoming:
+		room, err := s.rooms.GetByID(roomID)
+		if err != nil {
+			return nil, "", err
+		}
+		if room.Encrypted {
+			return nil, "", ErrInvalidWebhook
+		}
+		tokenHash := repository.HashAPIKey(secret)
+		hook.TokenHash = &tokenHash
+	case repository.WebhookKindOutgoing:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, "", ErrInvalidWebhook
+		}
+		hook.URL, hook.Secret = target, secret
+	default:
+		return nil, "", ErrInvalidWebhook
+	}
+
+	if err := s.hooks.Create(hook); err != nil {
+		if errors.Is(err, repository.ErrNameTaken) {
+			return nil, "", ErrNameTaken
+		}
+		return nil, "", err
+	}
+	return toWebhookInfo(hook), secret, nil
+}
+
+// ListWebhooks returns a room's webhooks, without their secrets.
+func (s *WebhookService) ListWebhooks(roomID, actorID uuid.UUID) ([]WebhookInfo, error) {
+	if err := s.requireModerator(roomID, actorID); err != nil {
+		return nil, err
+	}
+	hooks, err := s.hooks.ListByRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]WebhookInfo, len(hooks))
+	for i := range hooks {
+		infos[i] = *toWebhookInfo(&hooks[i])
+	}
+	return infos, nil
+}
+
+// DeleteWebhook removes a room's webhook. Its URL stops working at once, and
+// it is sent no more messages.
+func (s *WebhookService) DeleteWebhook(roomID, actorID, webhookID uuid.UUID) error {
+	if _, err := s.roomWebhook(roomID, actorID, webhookID); err != nil {
+		return err
+	}
+	return s.hooks.Delete(webhookID)
+}
+
+// Deliveries returns the newest attempts to send messages to an outgoing
+// webhook, newest first.
+func (s *WebhookService) Deliveries(roomID, actorID, webhookID uuid.UUID, limit int) ([]DeliveryInfo, error) {
+	if _, err := s.roomWebhook(roomID, actorID, webhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.hooks.Deliveries(webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]DeliveryInfo, len(deliveries))
+	for i, d := range deliveries {
+		infos[i] = DeliveryInfo{
+			ID:         d.ID,
+			MessageID:  d.MessageID,
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			Duration:   time.Duration(d.DurationMS) * time.Millisecond,
+			Succeeded:  d.Succeeded,
+			CreatedAt:  d.CreatedAt,
+		}
+	}
+	return infos, nil
+}
+
+// IncomingWebhook returns the incoming webhook whose URL carries token.
+func (s *WebhookService) IncomingWebhook(token string) (*WebhookInfo, error) {
+	hook, err := s.hooks.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookInfo(hook), nil
+}
+
+// roomWebhook returns a webhook of roomID for one of its owners or
+// moderators. Webhooks of other rooms are reported as not found.
+func (s *WebhookService) roomWebhook(roomID, actorID, webhookID uuid.UUID) (*repository.Webhook, error) {
+	if err := s.requireModerator(roomID, actorID); err != nil {
+		return nil, err
+	}
+	hook, err := s.hooks.GetByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if hook.RoomID != roomID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return hook, nil
+}
+
+func (s *WebhookService) requireModerator(roomID, actorID uuid.UUID) error {
+	actor, err := roomMember(s.rooms, roomID, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != repository.RoleOwner && actor.Role != repository.RoleModerator {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toWebhookInfo(hook *repository.Webhook) *WebhookInfo {
+	info := &WebhookInfo{
+		ID:        hook.ID,
+		RoomID:    hook.RoomID,
+		Kind:      hook.Kind,
+		Name:      hook.Name,
+		URL:       hook.URL,
+		CreatedAt: hook.CreatedAt,
+	}
+	if hook.UserID != nil {
+		info.UserID = *hook.UserID
+	}
+	return info
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	mocks "github.com/EwanGreer/chatatui/internal/service/_mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	moderator := &repository.RoomMember{Role: repository.RoleModerator}
+
+	tests := []struct {
+		name      string
+		kind      string
+		url       string
+		setup     func(*mocks.MockRoomStore, *mocks.MockWebhookStore)
+		check     func(t *testing.T, hook *repository.Webhook, secret string)
+		wantErrIs error
+	}{
+		{
+			name: "incoming webhook stores the token's hash",
+			kind: repository.WebhookKindIncoming,
+			setup: func(r *mocks.MockRoomStore, h *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+				r.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+				h.EXPECT().Create(mock.Anything).Return(nil)
+			},
+			check: func(t *testing.T, hook *repository.Webhook, secret string) {
+				require.NotNil(t, hook.TokenHash)
+				assert.Equal(t, repository.HashAPIKey(secret), *hook.TokenHash)
+				assert.Empty(t, hook.Secret)
+			},
+		},
+		{
+			name: "outgoing webhook keeps its signing secret",
+			kind: repository.WebhookKindOutgoing,
+			url:  "https://ci.example.com/chat",
+			setup: func(r *mocks.MockRoomStore, h *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+				h.EXPECT().Create(mock.Anything).Return(nil)
+			},
+			check: func(t *testing.T, hook *repository.Webhook, secret string) {
+				assert.Equal(t, secret, hook.Secret)
+				assert.Equal(t, "https://ci.example.com/chat", hook.URL)
+				assert.Nil(t, hook.TokenHash)
+			},
+		},
+		{
+			name: "outgoing webhook needs an http url",
+			kind: repository.WebhookKindOutgoing,
+			url:  "file:///etc/passwd",
+			setup: func(r *mocks.MockRoomStore, _ *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+			},
+			wantErrIs: ErrInvalidWebhook,
+		},
+		{
+			name: "encrypted rooms have no incoming webhooks",
+			kind: repository.WebhookKindIncoming,
+			setup: func(r *mocks.MockRoomStore, _ *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+				r.EXPECT().GetByID(roomID).Return(&repository.Room{Encrypted: true}, nil)
+			},
+			wantErrIs: ErrInvalidWebhook,
+		},
+		{
+			name: "incoming webhook cannot take a user's name",
+			kind: repository.WebhookKindIncoming,
+			setup: func(r *mocks.MockRoomStore, h *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+				r.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+				h.EXPECT().Create(mock.Anything).Return(repository.ErrNameTaken)
+			},
+			wantErrIs: ErrNameTaken,
+		},
+		{
+			name: "unknown kind",
+			kind: "sideways",
+			setup: func(r *mocks.MockRoomStore, _ *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(moderator, nil)
+			},
+			wantErrIs: ErrInvalidWebhook,
+		},
+		{
+			name: "member is forbidden",
+			kind: repository.WebhookKindIncoming,
+			setup: func(r *mocks.MockRoomStore, _ *mocks.MockWebhookStore) {
+				r.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleMember}, nil)
+			},
+			wantErrIs: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := mocks.NewMockRoomStore(t)
+			hooks := mocks.NewMockWebhookStore(t)
+			tt.setup(rooms, hooks)
+
+			info, secret, err := NewWebhookService(rooms, hooks).CreateWebhook(roomID, actorID, tt.kind, "ci", tt.url)
+
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, secret, 64)
+			assert.Equal(t, tt.kind, info.Kind)
+			created := hooks.Calls[0].Arguments.Get(0).(*repository.Webhook)
+			assert.Equal(t, actorID, created.CreatedByID)
+			tt.check(t, created, secret)
+		})
+	}
+}
+
+func TestWebhookService_DeleteWebhook_OtherRoom(t *testing.T) {
+	roomID := uuid.New()
+	actorID := uuid.New()
+	webhookID := uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	hooks := mocks.NewMockWebhookStore(t)
+	rooms.EXPECT().GetMember(roomID, actorID).Return(&repository.RoomMember{Role: repository.RoleOwner}, nil)
+	hooks.EXPECT().GetByID(webhookID).Return(&repository.Webhook{RoomID: uuid.New()}, nil)
+
+	err := NewWebhookService(rooms, hooks).DeleteWebhook(roomID, actorID, webhookID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+type recordingListener struct {
+	created []uuid.UUID
+}
+
+func (l *recordingListener) MessageCreated(_, messageID uuid.UUID) {
+	l.created = append(l.created, messageID)
+}
+
+func TestChatService_PersistMessage_TellsListener(t *testing.T) {
+	roomID := uuid.New()
+	senderID := uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+	messages.EXPECT().Create(mock.Anything).RunAndReturn(func(msg *repository.Message) error {
+		msg.ID = uuid.New()
+		return nil
+	})
+
+	listener := &recordingListener{}
+	svc := NewChatService(rooms, messages)
+	svc.SetMessageListener(listener)
+
+	id, _, _, err := svc.PersistMessage([]byte("deployed"), repository.MessageKindChat, senderID, roomID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{id}, listener.created)
+}
+
+func TestChatService_PersistAttachment_TellsListener(t *testing.T) {
+	roomID := uuid.New()
+	senderID := uuid.New()
+
+	rooms := mocks.NewMockRoomStore(t)
+	messages := mocks.NewMockMessageStore(t)
+	rooms.EXPECT().GetByID(roomID).Return(&repository.Room{}, nil)
+	rooms.EXPECT().GetMember(roomID, senderID).Return(&repository.RoomMember{}, nil)
+	messages.EXPECT().Create(mock.Anything).RunAndReturn(func(msg *repository.Message) error {
+		msg.ID = uuid.New()
+		return nil
+	})
+
+	listener := &recordingListener{}
+	svc := NewChatService(rooms, messages)
+	svc.SetMessageListener(listener)
+
+	info, err := svc.PersistAttachment(senderID, roomID, []byte("build log"), AttachmentInfo{Key: "k", Name: "build.log", Size: 7})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{info.ID}, listener.created)
+}
@@ -0,0 +1,333 @@
+// Package webhook delivers a room's new messages to its outgoing webhooks.
+// Each delivery is a JSON POST signed with the webhook's secret, retried with
+// backoff while the receiver fails, and every attempt is logged.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+)
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the request
+// body, keyed with the webhook's secret. EventHeader names the event.
+const (
+	SignatureHeader = "X-Chatatui-Signature"
+	EventHeader     = "X-Chatatui-Event"
+)
+
+// EventMessageCreated is sent for each message persisted in a room.
+const EventMessageCreated = "message.created"
+
+// defaultQueueSize is used when Config.QueueSize is not set.
+const defaultQueueSize = 1024
+
+// ErrBlockedAddress is the error for deliveries to an address that webhooks
+// may not reach, such as loopback or a private network.
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+// blockedPrefixes are ranges that reach the server's own networks but that
+// netip.Addr has no predicate for.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+type HookStore interface {
+	ListOutgoing(roomID uuid.UUID) ([]repository.Webhook, error)
+	RecordDelivery(delivery *repository.WebhookDelivery) error
+}
+
+type MessageLoader interface {
+	GetByID(id uuid.UUID) (*repository.Message, error)
+}
+
+type Config struct {
+	// MaxAttempts is how many times a message is sent to a webhook before
+	// it is given up on.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry, doubling for each
+	// retry after it.
+	RetryDelay time.Duration
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+	// Workers is how many messages are delivered at once, and QueueSize how
+	// many can wait for a worker before new ones are dropped. QueueSize
+	// defaults to 1024.
+	Workers   int
+	QueueSize int
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses. It is off by default, since anyone who can add a
+	// webhook could otherwise probe the server's own network.
+	AllowPrivateNetworks bool
+}
+
+// Payload is the body POSTed to outgoing webhooks.
+type Payload struct {
+	Event   string  `json:"event"`
+	RoomID  string  `json:"room_id"`
+	Room    string  `json:"room"`
+	Message Message `json:"message"`
+}
+
+// Message is a message as sent to outgoing webhooks. The content of messages
+// in encrypted rooms is the envelope the server stored.
+type Message struct {
+	ID        string    `json:"id"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Kind      string    `json:"kind"`
+	AuthorID  string    `json:"author_id"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type job struct {
+	roomID    uuid.UUID
+	messageID uuid.UUID
+}
+
+// Dispatcher sends messages to outgoing webhooks in the background.
+type Dispatcher struct {
+	hooks    HookStore
+	messages MessageLoader
+	client   *http.Client
+	cfg      Config
+	queue    chan job
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewDispatcher(hooks HookStore, messages MessageLoader, cfg Config) *Dispatcher {
+	cfg.MaxAttempts = max(cfg.MaxAttempts, 1)
+	cfg.Workers = max(cfg.Workers, 1)
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	d := &Dispatcher{
+		hooks:    hooks,
+		messages: messages,
+		client:   newClient(cfg),
+		cfg:      cfg,
+		queue:    make(chan job, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	for range cfg.Workers {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// newClient returns the client deliveries are made with. Addresses are checked
+// as they are dialled, after DNS resolution, so that a name cannot resolve to
+// a public address when the webhook is created and a private one when it is
+// used. Redirects are not followed, since they could lead anywhere, and proxies
+// are not used, since the check would then apply to the proxy.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = refuseBlocked
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refuseBlocked is a net.Dialer Control function that refuses connections to
+// loopback, private, link-local, multicast and unspecified addresses.
+func refuseBlocked(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+		}
+	}
+	return nil
+}
+
+// MessageCreated queues a persisted message for the room's outgoing webhooks.
+// It never blocks the sender: if the queue is full the message is dropped.
+func (d *Dispatcher) MessageCreated(roomID, messageID uuid.UUID) {
+	select {
+	case d.queue <- job{roomID: roomID, messageID: messageID}:
+	default:
+		slog.Warn("webhook queue full, dropping message", "room_id", roomID, "message_id", messageID)
+	}
+}
+
+// Close stops the workers once their current attempts finish. Queued
+// messages, and retries not yet due, are abandoned.
+func (d *Dispatcher) Close() {
+	close(d.done)
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.done:
+			return
+		case j := <-d.queue:
+			d.dispatch(j)
+		}
+	}
+}
+
+// dispatch sends a message to every outgoing webhook of its room at once, so
+// that one failing receiver does not hold up the others.
+func (d *Dispatcher) dispatch(j job) {
+	hooks, err := d.hooks.ListOutgoing(j.roomID)
+	if err != nil {
+		slog.Error("failed to list webhooks", "error", err, "room_id", j.roomID)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	msg, err := d.messages.GetByID(j.messageID)
+	if err != nil {
+		slog.Error("failed to load message for webhooks", "error", err, "message_id", j.messageID)
+		return
+	}
+	body, err := json.Marshal(payloadFor(msg))
+	if err != nil {
+		slog.Error("failed to marshal webhook payload", "error", err, "message_id", j.messageID)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		wg.Go(func() { d.deliver(hook, msg.ID, body) })
+	}
+	wg.Wait()
+}
+
+// deliver POSTs body to hook until it is accepted, it is refused outright, or
+// the attempts run out, recording each attempt.
+func (d *Dispatcher) deliver(hook repository.Webhook, messageID uuid.UUID, body []byte) {
+	delay := d.cfg.RetryDelay
+	for attempt := 1; ; attempt++ {
+		delivery := d.attempt(hook, body)
+		delivery.MessageID = messageID
+		delivery.Attempt = attempt
+		if err := d.hooks.RecordDelivery(delivery); err != nil {
+			slog.Error("failed to record webhook delivery", "error", err, "webhook_id", hook.ID)
+		}
+
+		if delivery.Succeeded || !retryable(delivery) || attempt >= d.cfg.MaxAttempts {
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-d.done:
+			return
+		}
+		delay *= 2
+	}
+}
+
+func (d *Dispatcher) attempt(hook repository.Webhook, body []byte) *repository.WebhookDelivery {
+	delivery := &repository.WebhookDelivery{WebhookID: hook.ID}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chatatui-webhook")
+	req.Header.Set(EventHeader, EventMessageCreated)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	return delivery
+}
+
+// retryable reports whether a failed attempt may succeed if tried again: the
+// receiver could not be reached, was overloaded or failed itself. Other
+// refusals, such as a 404 or 401, will not change.
+func retryable(delivery *repository.WebhookDelivery) bool {
+	return delivery.StatusCode == 0 ||
+		delivery.StatusCode == http.StatusTooManyRequests ||
+		delivery.StatusCode >= 500
+}
+
+func payloadFor(msg *repository.Message) Payload {
+	payload := Payload{
+		Event:  EventMessageCreated,
+		RoomID: msg.RoomID.String(),
+		Room:   msg.Room.Name,
+		Message: Message{
+			ID:        msg.ID.String(),
+			Kind:      msg.Kind,
+			AuthorID:  msg.SenderID.String(),
+			Author:    msg.Sender.Name,
+			Content:   string(msg.Content),
+			Timestamp: msg.CreatedAt,
+		},
+	}
+	if msg.ParentID != nil {
+		payload.Message.ParentID = msg.ParentID.String()
+	}
+	return payload
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the SignatureHeader value for body, for
+// receivers checking that a delivery came from this server.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
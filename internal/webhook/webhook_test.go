@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	hooks      []repository.Webhook
+	message    *repository.Message
+	deliveries []repository.WebhookDelivery
+	recorded   chan struct{}
+}
+
+func (s *fakeStore) ListOutgoing(uuid.UUID) ([]repository.Webhook, error) {
+	return s.hooks, nil
+}
+
+func (s *fakeStore) GetByID(uuid.UUID) (*repository.Message, error) {
+	return s.message, nil
+}
+
+func (s *fakeStore) RecordDelivery(d *repository.WebhookDelivery) error {
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, *d)
+	s.mu.Unlock()
+	s.recorded <- struct{}{}
+	return nil
+}
+
+func newFakeStore(url string) *fakeStore {
+	roomID := uuid.New()
+	return &fakeStore{
+		hooks: []repository.Webhook{{BaseModel: repository.BaseModel{ID: uuid.New()}, RoomID: roomID, Kind: repository.WebhookKindOutgoing, URL: url, Secret: "shh"}},
+		message: &repository.Message{
+			BaseModel: repository.BaseModel{ID: uuid.New(), CreatedAt: time.Now()},
+			Content:   []byte("build failed"),
+			Kind:      repository.MessageKindChat,
+			RoomID:    roomID,
+			Room:      repository.Room{Name: "ci"},
+			Sender:    repository.User{Name: "alice"},
+		},
+		recorded: make(chan struct{}, 10),
+	}
+}
+
+// waitForDeliveries waits until n attempts have been recorded.
+func waitForDeliveries(t *testing.T, store *fakeStore, n int) []repository.WebhookDelivery {
+	t.Helper()
+	for range n {
+		select {
+		case <-store.recorded:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %d deliveries", n)
+		}
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.deliveries
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	var got Payload
+	var signed bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signed = Verify("shh", body, r.Header.Get(SignatureHeader))
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := newFakeStore(srv.URL)
+	d := NewDispatcher(store, store, Config{MaxAttempts: 3, RetryDelay: time.Millisecond, Workers: 1, QueueSize: 1, AllowPrivateNetworks: true})
+	defer d.Close()
+
+	d.MessageCreated(store.message.RoomID, store.message.ID)
+	deliveries := waitForDeliveries(t, store, 1)
+
+	assert.True(t, signed, "expected a valid signature")
+	assert.Equal(t, EventMessageCreated, got.Event)
+	assert.Equal(t, "ci", got.Room)
+	assert.Equal(t, "alice", got.Message.Author)
+	assert.Equal(t, "build failed", got.Message.Content)
+	require.Len(t, deliveries, 1)
+	assert.True(t, deliveries[0].Succeeded)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].StatusCode)
+	assert.Equal(t, store.message.ID, deliveries[0].MessageID)
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCodes []int
+	}{
+		{name: "retries server errors until accepted", statuses: []int{500, 503, 200}, wantCodes: []int{500, 503, 200}},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500, 500}, wantCodes: []int{500, 500, 500}},
+		{name: "does not retry a refusal", statuses: []int{404, 200}, wantCodes: []int{404}},
+		{name: "retries when rate limited", statuses: []int{429, 200}, wantCodes: []int{429, 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[calls.Add(1)-1])
+			}))
+			defer srv.Close()
+
+			store := newFakeStore(srv.URL)
+			d := NewDispatcher(store, store, Config{MaxAttempts: 3, RetryDelay: time.Millisecond, Workers: 1, QueueSize: 1, AllowPrivateNetworks: true})
+			defer d.Close()
+
+			d.MessageCreated(store.message.RoomID, store.message.ID)
+			deliveries := waitForDeliveries(t, store, len(tt.wantCodes))
+			time.Sleep(20 * time.Millisecond) // let any unwanted retry happen
+
+			var codes []int
+			for i, delivery := range deliveries {
+				assert.Equal(t, i+1, delivery.Attempt)
+				codes = append(codes, delivery.StatusCode)
+			}
+			assert.Equal(t, tt.wantCodes, codes)
+			assert.Equal(t, len(tt.wantCodes), int(calls.Load()))
+		})
+	}
+}
+
+func TestDispatcher_RefusesPrivateAddresses(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	store := newFakeStore(srv.URL)
+	d := NewDispatcher(store, store, Config{MaxAttempts: 1, Workers: 1, QueueSize: 1})
+	defer d.Close()
+
+	d.MessageCreated(store.message.RoomID, store.message.ID)
+	deliveries := waitForDeliveries(t, store, 1)
+
+	assert.Zero(t, hits.Load(), "the loopback receiver should never be reached")
+	require.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Succeeded)
+	assert.Contains(t, deliveries[0].Error, ErrBlockedAddress.Error())
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer srv.Close()
+
+	store := newFakeStore(srv.URL)
+	d := NewDispatcher(store, store, Config{MaxAttempts: 3, RetryDelay: time.Millisecond, Workers: 1, QueueSize: 1, AllowPrivateNetworks: true})
+	defer d.Close()
+
+	d.MessageCreated(store.message.RoomID, store.message.ID)
+	deliveries := waitForDeliveries(t, store, 1)
+
+	assert.Zero(t, redirected.Load(), "the redirect should not be followed")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, http.StatusFound, deliveries[0].StatusCode)
+	assert.False(t, deliveries[0].Succeeded)
+}
+
+func TestRefuseBlocked(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "10.1.2.3:80", "192.168.0.1:80", "172.16.0.1:80",
+		"169.254.169.254:80", "0.0.0.0:80", "[::]:80", "[fd00::1]:80", "[fe80::1]:80", "[::ffff:127.0.0.1]:80", "100.64.0.1:80"} {
+		assert.ErrorIs(t, refuseBlocked("tcp", address, nil), ErrBlockedAddress, address)
+	}
+	for _, address := range []string{"93.184.216.34:443", "[2606:4700::1111]:443"} {
+		assert.NoError(t, refuseBlocked("tcp", address, nil), address)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"message.created"}`)
+	sig := Sign("secret", body)
+
+	assert.True(t, Verify("secret", body, sig))
+	assert.False(t, Verify("other", body, sig))
+	assert.False(t, Verify("secret", []byte(`{}`), sig))
+	assert.False(t, Verify("secret", body, ""))
+}
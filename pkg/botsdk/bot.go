@@ -0,0 +1,393 @@
+// Package botsdk is a client for writing chatatui bots. A Bot authenticates
+// with a bot API key, joins its rooms over one WebSocket, reconnects when the
+// connection drops, and hands each message, or each command such as
+// "!remind 10m stretch", to the functions registered for it.
+//
+//	bot := botsdk.New(botsdk.Config{Host: "localhost:8080", APIKey: key})
+//	bot.Command("echo", func(ctx context.Context, b *botsdk.Bot, cmd botsdk.Command) {
+//		_ = b.Send(ctx, cmd.RoomID, cmd.Text)
+//	})
+//	err := bot.Run(ctx)
+package botsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/coder/websocket"
+)
+
+var (
+	// ErrNotConnected is returned when sending while the bot has no
+	// connection, such as while it is reconnecting.
+	ErrNotConnected = errors.New("not connected")
+	// ErrUnauthorized is returned when the server refuses the bot's API key,
+	// or the key lacks a scope the bot needs. Run gives up on it rather than
+	// reconnecting.
+	ErrUnauthorized = errors.New("api key refused")
+)
+
+// maxReadBytes bounds each frame read from the server, which may carry a
+// page of history.
+const maxReadBytes = 1 << 20
+
+type Config struct {
+	// Host is the server's address, such as "localhost:8080" or
+	// "https://chat.example.com".
+	Host string
+	// APIKey is the bot's key, returned when the bot was created.
+	APIKey string
+	// Rooms are the names or IDs of the rooms to join. If empty the bot
+	// joins every room it can see, including every public room.
+	Rooms []string
+	// Prefix starts a command; it defaults to "!".
+	Prefix string
+	// ReconnectDelay is the wait before reconnecting after the connection
+	// drops, doubling for each failed attempt up to MaxReconnectDelay. They
+	// default to 1 and 30 seconds.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// HTTPClient makes API requests; it defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// User is who an API key belongs to.
+type User struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Kind   string   `json:"kind"`
+	Scopes []string `json:"scopes"`
+}
+
+type Room struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Topic      string `json:"topic"`
+	Visibility string `json:"visibility"`
+	Kind       string `json:"kind"`
+	Encrypted  bool   `json:"encrypted"`
+}
+
+// Message is a message sent to one of the bot's rooms by someone else.
+type Message struct {
+	ID     string
+	RoomID string
+	Author string
+	// Content is the text of the message. The bot cannot read messages in
+	// encrypted rooms, whose content is an encrypted envelope.
+	Content string
+	// ParentID is set on replies in a thread.
+	ParentID string
+	// Action is set on messages sent with /me.
+	Action    bool
+	Timestamp time.Time
+}
+
+// MessageHandler is called with every message. CommandHandler is called with
+// each command of the name it was registered for.
+type (
+	MessageHandler func(ctx context.Context, b *Bot, msg Message)
+	CommandHandler func(ctx context.Context, b *Bot, cmd Command)
+)
+
+type Bot struct {
+	cfg      Config
+	onMsg    []MessageHandler
+	commands map[string]CommandHandler
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+	me   User
+}
+
+func New(cfg Config) *Bot {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "!"
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = time.Second
+	}
+	if cfg.MaxReconnectDelay <= 0 {
+		cfg.MaxReconnectDelay = 30 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &Bot{cfg: cfg, commands: make(map[string]CommandHandler)}
+}
+
+// OnMessage registers fn to be called with every message, commands included.
+func (b *Bot) OnMessage(fn MessageHandler) {
+	b.onMsg = append(b.onMsg, fn)
+}
+
+// Command registers fn to be called with each command called name, which is
+// matched without regard to case.
+func (b *Bot) Command(name string, fn CommandHandler) {
+	b.commands[strings.ToLower(name)] = fn
+}
+
+// Me returns who the bot is. It is only known once Run has started.
+func (b *Bot) Me() User {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.me
+}
+
+// Run connects the bot and serves its handlers until ctx is cancelled or the
+// server refuses its API key. A dropped connection is reconnected, and the
+// bot's rooms joined again. Each message is handled on its own goroutine.
+func (b *Bot) Run(ctx context.Context) error {
+	me, err := b.User(ctx)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.me = *me
+	b.mu.Unlock()
+
+	var handlers sync.WaitGroup
+	defer handlers.Wait()
+
+	delay := b.cfg.ReconnectDelay
+	for {
+		conn, err := b.connect(ctx)
+		if errors.Is(err, ErrUnauthorized) {
+			return err
+		}
+		if err == nil {
+			delay = b.cfg.ReconnectDelay
+			err = b.serve(ctx, conn, &handlers)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Warn("bot disconnected, reconnecting", "error", err, "delay", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, b.cfg.MaxReconnectDelay)
+	}
+}
+
+// connect dials the multiplexed WebSocket and subscribes to the bot's rooms.
+func (b *Bot) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := websocket.Dial(ctx, b.wsURL("/ws"), &websocket.DialOptions{
+		HTTPClient: b.cfg.HTTPClient,
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer " + b.cfg.APIKey}},
+	})
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, fmt.Errorf("%w: server returned %d", ErrUnauthorized, resp.StatusCode)
+		}
+		return nil, err
+	}
+	conn.SetReadLimit(maxReadBytes)
+
+	rooms, err := b.joinable(ctx)
+	if err != nil {
+		_ = conn.CloseNow()
+		return nil, err
+	}
+	for _, room := range rooms {
+		if err := write(ctx, conn, &hub.WireMessage{Type: hub.MessageTypeSubscribe, RoomID: room.ID}); err != nil {
+			_ = conn.CloseNow()
+			return nil, err
+		}
+	}
+
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+	return conn, nil
+}
+
+// joinable returns the rooms named in the config, or every room if none are.
+func (b *Bot) joinable(ctx context.Context) ([]Room, error) {
+	rooms, err := b.Rooms(ctx)
+	if err != nil || len(b.cfg.Rooms) == 0 {
+		return rooms, err
+	}
+
+	var joinable []Room
+	for _, want := range b.cfg.Rooms {
+		i := slices.IndexFunc(rooms, func(r Room) bool { return r.ID == want || r.Name == want })
+		if i < 0 {
+			slog.Warn("bot cannot see room", "room", want)
+			continue
+		}
+		joinable = append(joinable, rooms[i])
+	}
+	return joinable, nil
+}
+
+// serve reads from conn until it fails, handing messages to the handlers.
+func (b *Bot) serve(ctx context.Context, conn *websocket.Conn, handlers *sync.WaitGroup) error {
+	defer func() {
+		b.mu.Lock()
+		b.conn = nil
+		b.mu.Unlock()
+		_ = conn.CloseNow()
+	}()
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		var wire hub.WireMessage
+		if err := json.Unmarshal(data, &wire); err != nil {
+			continue
+		}
+
+		switch wire.Type {
+		case hub.MessageTypeChat, hub.MessageTypeAction, hub.MessageTypeThreadReply:
+		case hub.MessageTypeError:
+			slog.Warn("server refused bot message", "room_id", wire.RoomID, "error", wire.Content)
+			continue
+		default:
+			continue
+		}
+		if wire.Author == b.Me().Name {
+			continue
+		}
+
+		msg := Message{
+			ID:        wire.ID,
+			RoomID:    wire.RoomID,
+			Author:    wire.Author,
+			Content:   wire.Content,
+			ParentID:  wire.ParentID,
+			Action:    wire.Type == hub.MessageTypeAction,
+			Timestamp: wire.Timestamp,
+		}
+		handlers.Go(func() { b.handle(ctx, msg) })
+	}
+}
+
+func (b *Bot) handle(ctx context.Context, msg Message) {
+	for _, fn := range b.onMsg {
+		fn(ctx, b, msg)
+	}
+	if msg.Action {
+		return
+	}
+	cmd, ok := ParseCommand(b.cfg.Prefix, msg.Content)
+	if !ok {
+		return
+	}
+	if fn, ok := b.commands[strings.ToLower(cmd.Name)]; ok {
+		cmd.Message = msg
+		fn(ctx, b, cmd)
+	}
+}
+
+// Send posts text to a room the bot has joined.
+func (b *Bot) Send(ctx context.Context, roomID, text string) error {
+	return b.send(ctx, &hub.WireMessage{Type: hub.MessageTypeChat, RoomID: roomID, Content: text})
+}
+
+// Reply posts text in msg's thread, starting one if msg is not a reply.
+func (b *Bot) Reply(ctx context.Context, msg Message, text string) error {
+	parentID := msg.ParentID
+	if parentID == "" {
+		parentID = msg.ID
+	}
+	return b.send(ctx, &hub.WireMessage{Type: hub.MessageTypeThreadReply, RoomID: msg.RoomID, ParentID: parentID, Content: text})
+}
+
+func (b *Bot) send(ctx context.Context, msg *hub.WireMessage) error {
+	b.mu.Lock()
+	conn := b.conn
+	b.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	return write(ctx, conn, msg)
+}
+
+func write(ctx context.Context, conn *websocket.Conn, msg *hub.WireMessage) error {
+	data, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// User returns who the bot's API key belongs to.
+func (b *Bot) User(ctx context.Context) (*User, error) {
+	var user User
+	if err := b.get(ctx, "/users/me", &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Rooms returns the rooms the bot can see. It needs the rooms:read scope.
+func (b *Bot) Rooms(ctx context.Context) ([]Room, error) {
+	var rooms []Room
+	if err := b.get(ctx, "/rooms", &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (b *Bot) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.httpURL(path), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.cfg.APIKey)
+
+	resp, err := b.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		err := fmt.Errorf("GET %s: server returned %d: %s", path, resp.StatusCode, body.Error)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			err = fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		}
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (b *Bot) httpURL(path string) string {
+	base := b.cfg.Host
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	return base + path
+}
+
+func (b *Bot) wsURL(path string) string {
+	base := b.cfg.Host
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	default:
+		base = "ws://" + base
+	}
+	return base + path
+}
@@ -0,0 +1,118 @@
+package botsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/chatatui/internal/server/hub"
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer serves just enough of the API for a bot. Its first WebSocket
+// connection is dropped once the bot subscribes; on the second it sends
+// each of toBot and passes on what the bot sends back.
+func fakeServer(t *testing.T, toBot []hub.WireMessage, fromBot chan<- hub.WireMessage) *httptest.Server {
+	var connections atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/me", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(User{ID: "b1", Name: "echo", Kind: "bot"})
+	})
+	mux.HandleFunc("GET /rooms", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Room{{ID: "r1", Name: "general"}, {ID: "r2", Name: "random"}})
+	})
+	mux.HandleFunc("GET /ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.CloseNow() }()
+		ctx := r.Context()
+
+		var sub hub.WireMessage
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &sub))
+		fromBot <- sub
+		if connections.Add(1) == 1 {
+			return
+		}
+
+		for _, msg := range toBot {
+			data, _ := msg.Marshal()
+			require.NoError(t, conn.Write(ctx, websocket.MessageText, data))
+		}
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var msg hub.WireMessage
+			require.NoError(t, json.Unmarshal(data, &msg))
+			fromBot <- msg
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBot_ReconnectsAndAnswersCommands(t *testing.T) {
+	fromBot := make(chan hub.WireMessage, 10)
+	srv := fakeServer(t, []hub.WireMessage{
+		{Type: hub.MessageTypeChat, RoomID: "r1", ID: "m1", Author: "echo", Content: "!echo talking to myself"},
+		{Type: hub.MessageTypeChat, RoomID: "r1", ID: "m2", Author: "alice", Content: "!ECHO hello  there"},
+		{Type: hub.MessageTypeChat, RoomID: "r1", ID: "m3", Author: "alice", Content: "!ping"},
+	}, fromBot)
+
+	bot := New(Config{Host: srv.URL, APIKey: "key", Rooms: []string{"general"}, ReconnectDelay: time.Millisecond})
+	bot.Command("echo", func(ctx context.Context, b *Bot, cmd Command) {
+		assert.Equal(t, "alice", cmd.Author)
+		assert.NoError(t, b.Send(ctx, cmd.RoomID, cmd.Text))
+	})
+	bot.Command("ping", func(ctx context.Context, b *Bot, cmd Command) {
+		assert.NoError(t, b.Reply(ctx, cmd.Message, "pong"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bot.Run(ctx) }()
+
+	var got []hub.WireMessage
+	for range 4 {
+		select {
+		case msg := <-fromBot:
+			got = append(got, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out after %+v", got)
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// The bot only joins the room it was given, again after reconnecting.
+	for _, sub := range got[:2] {
+		assert.Equal(t, hub.MessageTypeSubscribe, sub.Type)
+		assert.Equal(t, "r1", sub.RoomID)
+	}
+	assert.ElementsMatch(t, []hub.WireMessage{
+		{Type: hub.MessageTypeChat, RoomID: "r1", Content: "hello  there"},
+		{Type: hub.MessageTypeThreadReply, RoomID: "r1", ParentID: "m3", Content: "pong"},
+	}, got[2:])
+}
+
+func TestBot_RunStopsWhenKeyIsRefused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid api key","code":"INVALID_API_KEY"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	err := New(Config{Host: srv.URL, APIKey: "wrong"}).Run(context.Background())
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,67 @@
+package botsdk
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Command is a message that starts with the bot's prefix, such as
+// "!remind 10m stretch your legs".
+type Command struct {
+	Message
+	// Name is the word after the prefix: "remind".
+	Name string
+	// Args are the words after the name, with double-quoted phrases kept
+	// whole: ["10m", "stretch", "your", "legs"].
+	Args []string
+	// Text is everything after the name, as it was typed: "10m stretch your
+	// legs".
+	Text string
+}
+
+// ParseCommand parses content as a command if it starts with prefix and a
+// name. The returned Command's Message is not set.
+func ParseCommand(prefix, content string) (Command, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(content), prefix)
+	if !ok || rest == "" || unicode.IsSpace(rune(rest[0])) {
+		return Command{}, false
+	}
+
+	end := strings.IndexFunc(rest, unicode.IsSpace)
+	if end < 0 {
+		end = len(rest)
+	}
+	text := strings.TrimSpace(rest[end:])
+	return Command{Name: rest[:end], Args: splitArgs(text), Text: text}, true
+}
+
+// splitArgs splits s on whitespace, except inside double quotes, which are
+// dropped. An unclosed quote runs to the end of s.
+func splitArgs(s string) []string {
+	var (
+		args    []string
+		arg     strings.Builder
+		quoted  bool
+		started bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				args = append(args, arg.String())
+				arg.Reset()
+				started = false
+			}
+		default:
+			arg.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, arg.String())
+	}
+	return args
+}
@@ -0,0 +1,61 @@
+package botsdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantOK  bool
+		want    Command
+	}{
+		{
+			name:    "name and args",
+			content: "!remind 10m stretch  your legs",
+			wantOK:  true,
+			want:    Command{Name: "remind", Args: []string{"10m", "stretch", "your", "legs"}, Text: "10m stretch  your legs"},
+		},
+		{
+			name:    "quoted args",
+			content: `!remind 1h "stand up" now`,
+			wantOK:  true,
+			want:    Command{Name: "remind", Args: []string{"1h", "stand up", "now"}, Text: `1h "stand up" now`},
+		},
+		{
+			name:    "no args",
+			content: " !help ",
+			wantOK:  true,
+			want:    Command{Name: "help", Text: ""},
+		},
+		{
+			name:    "args on the next line",
+			content: "!echo\nhello",
+			wantOK:  true,
+			want:    Command{Name: "echo", Args: []string{"hello"}, Text: "hello"},
+		},
+		{
+			name:    "no prefix",
+			content: "echo hello",
+		},
+		{
+			name:    "prefix alone",
+			content: "!",
+		},
+		{
+			name:    "space after the prefix",
+			content: "! echo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseCommand("!", tt.content)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}